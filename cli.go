@@ -0,0 +1,124 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"kasir-api/database"
+	"kasir-api/dummy"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const usage = `Usage:
+  kasir-api                       jalanin HTTP server
+  kasir-api migrate up            apply semua migrasi yang belum jalan
+  kasir-api migrate down [N]      rollback N migrasi terakhir (default 1). Berhenti di versi 1:
+                                  migrasi dasar (tabel products & categories) ga pernah di-rollback
+  kasir-api migrate status        daftar migrasi & kapan di-apply
+  kasir-api seed [--reset --confirm-wipe] [--reset-stock] [--scale N]
+                                  upsert katalog dummy (kategori & produk), --reset-stock samain
+                                  stok produk yang udah ada ke data seed (default ga disentuh),
+                                  --scale N bikin katalog N kali lipat.
+                                  --reset HAPUS PERMANEN katalog plus semua transaksi, refund, PO,
+                                  penerimaan barang, opname, pending QRIS, promo & customer
+                                  sebelum seed; wajib ditemenin --confirm-wipe`
+
+// runCommand - subcommand CLI, dipanggil kalau binary dijalanin pake argumen
+func runCommand(args []string, db *sql.DB, logger *slog.Logger) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(args[1:], db, logger)
+	case "seed":
+		return runSeed(args[1:], db, logger)
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+		return nil
+	}
+	return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+}
+
+func runMigrate(args []string, db *sql.DB, logger *slog.Logger) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(db, logger)
+		if err != nil {
+			return err
+		}
+		logger.Info("Migrations applied", "count", applied)
+		return nil
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return errors.New("jumlah step migrate down harus angka lebih dari 0")
+			}
+			steps = n
+		}
+		reverted, err := database.MigrateDown(db, steps, logger)
+		if err != nil {
+			return err
+		}
+		logger.Info("Migrations reverted", "count", reverted)
+		return nil
+	case "status":
+		statuses, err := database.MigrationStatuses(db)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+	}
+	return fmt.Errorf("unknown migrate command %q\n%s", args[0], usage)
+}
+
+// runSeed - isi katalog dari package dummy, aman dijalanin berkali-kali
+func runSeed(args []string, db *sql.DB, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	reset := flags.Bool("reset", false, "kosongin katalog & data penjualan sebelum seed (butuh --confirm-wipe)")
+	confirmWipe := flags.Bool("confirm-wipe", false, "konfirmasi --reset boleh hapus semua transaksi, refund, PO & customer")
+	resetStock := flags.Bool("reset-stock", false, "koreksi stok produk yang udah ada ke angka data seed")
+	scale := flags.Int("scale", 1, "jumlah salinan katalog dummy (50 produk per salinan)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *scale < 1 {
+		return errors.New("--scale harus lebih dari 0")
+	}
+	// --reset ikut ngehapus data penjualan asli (FK ke produk), jadi ga boleh jalan karena salah ketik doang
+	if *reset && !*confirmWipe {
+		return errors.New("--reset bakal hapus semua transaksi, refund, PO, opname, pending QRIS, promo & customer; tambahin --confirm-wipe kalau memang mau")
+	}
+
+	repo := repositories.NewSeedRepository(db, logger)
+	opts := models.SeedOptions{Reset: *reset, ResetStock: *resetStock}
+	result, err := repo.Seed(dummy.Categories(), dummy.Products(*scale), opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("categories: %d created, %d updated\nproducts: %d created, %d updated\n",
+		result.CategoriesCreated, result.CategoriesUpdated, result.ProductsCreated, result.ProductsUpdated)
+	fmt.Printf("stock adjusted: %d products\n", len(result.StockChanges))
+	for _, c := range result.StockChanges {
+		fmt.Printf("  #%d %s: %d -> %d\n", c.ProductID, c.Name, c.From, c.To)
+	}
+	return nil
+}
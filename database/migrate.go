@@ -0,0 +1,270 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File migrasi: migrations/NNNN_nama.up.sql + NNNN_nama.down.sql, urut berdasarkan NNNN
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey - key pg_advisory_lock biar dua replica ga migrasi barengan
+const migrationLockKey int64 = 7301202601
+
+// baseMigrationVersion - migrasi dasar yang ngadopsi tabel katalog lama (CREATE TABLE IF NOT EXISTS).
+// Ga pernah di-rollback, karena tabelnya belum tentu dibikin sama migrasi ini
+const baseMigrationVersion = 1
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus - satu baris output `migrate status`
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Migrations - semua migrasi yang di-embed, urut dari versi terkecil.
+// Error kalau ada versi dobel atau pasangan up/down yang ga lengkap
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		file := entry.Name()
+		base, direction, ok := cutDirection(file)
+		if !ok {
+			return nil, fmt.Errorf("migration %s: nama file harus NNNN_nama.up.sql atau .down.sql", file)
+		}
+		prefix, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: versi harus angka di depan nama file", file)
+		}
+
+		content, err := migrationFiles.ReadFile("migrations/" + file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migration %d: versi dipake dua nama (%s, %s)", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s: file up dan down wajib ada dua-duanya", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func cutDirection(file string) (string, string, bool) {
+	if base, ok := strings.CutSuffix(file, ".up.sql"); ok {
+		return base, "up", true
+	}
+	if base, ok := strings.CutSuffix(file, ".down.sql"); ok {
+		return base, "down", true
+	}
+	return "", "", false
+}
+
+// MigrateUp - jalanin semua migrasi yang belum ke-apply, return jumlah yang dijalanin
+func MigrateUp(db *sql.DB, logger *slog.Logger) (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	err = withMigrationLock(db, logger, func(conn *sql.Conn) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if _, ok := done[m.Version]; ok {
+				continue
+			}
+			logger.Info("Applying migration", "version", m.Version, "name", m.Name)
+			if err := runMigration(conn, m.Up, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name); err != nil {
+				return fmt.Errorf("migration %04d_%s up: %w", m.Version, m.Name, err)
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown - rollback steps migrasi terakhir yang udah ke-apply, return jumlah yang di-rollback.
+// Berhenti di baseMigrationVersion: paling bawah database balik ke versi 1, bukan 0
+func MigrateDown(db *sql.DB, steps int, logger *slog.Logger) (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+
+	reverted := 0
+	err = withMigrationLock(db, logger, func(conn *sql.Conn) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(migrations) - 1; i >= 0 && reverted < steps; i-- {
+			m := migrations[i]
+			if _, ok := done[m.Version]; !ok {
+				continue
+			}
+			if m.Version <= baseMigrationVersion {
+				logger.Warn("Refusing to revert base migration, catalog tables are kept", "version", m.Version, "name", m.Name)
+				break
+			}
+			logger.Info("Reverting migration", "version", m.Version, "name", m.Name)
+			if err := runMigration(conn, m.Down, "DELETE FROM schema_migrations WHERE version = $1", m.Version); err != nil {
+				return fmt.Errorf("migration %04d_%s down: %w", m.Version, m.Name, err)
+			}
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// MigrationStatuses - semua migrasi yang di-embed + kapan di-apply (nil = belum)
+func MigrationStatuses(db *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureMigrationTable(conn); err != nil {
+		return nil, err
+	}
+	done, err := appliedVersions(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if appliedAt, ok := done[m.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// withMigrationLock - advisory lock itu per session, jadi lock, migrasi dan unlock
+// harus lewat satu koneksi yang sama, bukan connection pool
+func withMigrationLock(db *sql.DB, logger *slog.Logger, fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	logger.Info("Waiting for migration lock")
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+			logger.Error("Failed to release migration lock", "error", err)
+		}
+	}()
+
+	if err := ensureMigrationTable(conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func ensureMigrationTable(conn *sql.Conn) error {
+	_, err := conn.ExecContext(context.Background(), `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INT PRIMARY KEY,
+			name       VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`)
+	return err
+}
+
+func appliedVersions(conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(context.Background(), "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		done[version] = appliedAt
+	}
+	return done, rows.Err()
+}
+
+// runMigration - isi file migrasi dan pencatatan di schema_migrations satu SQL transaction,
+// jadi migrasi yang gagal di tengah ga ninggalin schema setengah jadi
+func runMigration(conn *sql.Conn, script, record string, args ...any) error {
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var migrationName = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// explicitTx - runMigration udah bungkus tiap file dalam transaction, BEGIN/COMMIT di file bakal bentrok
+var explicitTx = regexp.MustCompile(`(?im)^\s*(BEGIN|COMMIT|ROLLBACK)\s*;`)
+
+func TestMigrationsEmbedded(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	if migrations[0].Version != baseMigrationVersion {
+		t.Fatalf("first migration = %d, want base version %d", migrations[0].Version, baseMigrationVersion)
+	}
+
+	for i, m := range migrations {
+		// versi harus urut tanpa lompat & tanpa dobel
+		if want := baseMigrationVersion + i; m.Version != want {
+			t.Fatalf("migration #%d has version %d, want %d", i, m.Version, want)
+		}
+		if !migrationName.MatchString(m.Name) {
+			t.Errorf("migration %d: name %q should be lower_snake_case", m.Version, m.Name)
+		}
+		for direction, script := range map[string]string{"up": m.Up, "down": m.Down} {
+			// prefix 4 digit biar urutan nama file sama dengan urutan versi
+			file := fmt.Sprintf("migrations/%04d_%s.%s.sql", m.Version, m.Name, direction)
+			if _, err := fs.Stat(migrationFiles, file); err != nil {
+				t.Errorf("migration %d: %v", m.Version, err)
+			}
+			if strings.TrimSpace(script) == "" {
+				t.Errorf("%s is empty", file)
+			}
+			if explicitTx.MatchString(script) {
+				t.Errorf("%s should not manage its own transaction", file)
+			}
+			if err := checkSQL(script); err != nil {
+				t.Errorf("%s: %v", file, err)
+			}
+		}
+	}
+
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2*len(migrations) {
+		t.Fatalf("%d embedded files for %d migrations", len(entries), len(migrations))
+	}
+}
+
+// checkSQL - cek sintaks kasar tanpa database: kutip & kurung seimbang (di luar komentar/string)
+// dan statement terakhir ditutup titik koma
+func checkSQL(script string) error {
+	depth, inString, last := 0, false, rune(0)
+	lines := strings.Split(script, "\n")
+	for n, line := range lines {
+		for i, r := range line {
+			if !inString && strings.HasPrefix(line[i:], "--") {
+				break
+			}
+			switch {
+			case r == '\'':
+				inString = !inString
+			case inString:
+			case r == '(':
+				depth++
+			case r == ')':
+				depth--
+				if depth < 0 {
+					return fmt.Errorf("line %d: unbalanced )", n+1)
+				}
+			}
+			if r != ' ' && r != '\t' && r != '\r' {
+				last = r
+			}
+		}
+	}
+	if inString {
+		return fmt.Errorf("unterminated string literal")
+	}
+	if depth != 0 {
+		return fmt.Errorf("%d unclosed (", depth)
+	}
+	if last != ';' {
+		return fmt.Errorf("last statement should end with ;")
+	}
+	return nil
+}
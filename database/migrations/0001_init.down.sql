@@ -0,0 +1,4 @@
+-- Sengaja kosong: 0001 ngadopsi tabel products/categories yang mungkin udah ada sebelum
+-- ada migrasi, jadi rollback ga boleh nge-drop data katalog. MigrateDown juga ga pernah
+-- nurunin versi di bawah baseMigrationVersion
+SELECT 1;
@@ -0,0 +1,17 @@
+-- Tabel dasar produk & kategori. Sebelumnya dibikin manual, IF NOT EXISTS
+-- biar database lama yang udah punya tabel ini tetap bisa dimigrasi
+CREATE TABLE IF NOT EXISTS categories (
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS products (
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    price       INT NOT NULL DEFAULT 0,
+    stock       INT NOT NULL DEFAULT 0,
+    category_id INT REFERENCES categories(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products(category_id);
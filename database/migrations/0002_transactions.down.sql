@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS transaction_items;
+DROP TABLE IF EXISTS transactions;
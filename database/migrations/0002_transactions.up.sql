@@ -0,0 +1,23 @@
+-- Tabel untuk checkout / penjualan
+CREATE TABLE IF NOT EXISTS transactions (
+    id             SERIAL PRIMARY KEY,
+    cashier        VARCHAR(100) NOT NULL DEFAULT '',
+    payment_method VARCHAR(30) NOT NULL DEFAULT 'cash',
+    total_amount   INT NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_created_at ON transactions(created_at);
+
+CREATE TABLE IF NOT EXISTS transaction_items (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    product_id     INT NOT NULL REFERENCES products(id),
+    product_name   VARCHAR(255) NOT NULL,
+    price          INT NOT NULL,
+    quantity       INT NOT NULL CHECK (quantity > 0),
+    subtotal       INT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_items_transaction_id ON transaction_items(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_items_product_id ON transaction_items(product_id);
@@ -0,0 +1,23 @@
+-- Refund / retur, selalu nge-link ke transaksi asal
+CREATE TABLE IF NOT EXISTS refunds (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id),
+    reason         TEXT NOT NULL DEFAULT '',
+    total_amount   INT NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS refund_items (
+    id                  SERIAL PRIMARY KEY,
+    refund_id           INT NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+    transaction_item_id INT NOT NULL REFERENCES transaction_items(id),
+    product_id          INT NOT NULL REFERENCES products(id),
+    product_name        VARCHAR(255) NOT NULL,
+    price               INT NOT NULL,
+    quantity            INT NOT NULL CHECK (quantity > 0),
+    subtotal            INT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_transaction_id ON refunds(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_created_at ON refunds(created_at);
+CREATE INDEX IF NOT EXISTS idx_refund_items_transaction_item_id ON refund_items(transaction_item_id);
@@ -0,0 +1,22 @@
+-- Ledger stok, append-only. products.stock itu cache dari SUM(quantity)
+-- dan selalu di-update di SQL transaction yang sama dengan insert ledger
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id             SERIAL PRIMARY KEY,
+    product_id     INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    reason         VARCHAR(30) NOT NULL,
+    quantity       INT NOT NULL,
+    stock_after    INT NOT NULL,
+    reference_type VARCHAR(30) NOT NULL DEFAULT '',
+    reference_id   INT,
+    note           TEXT NOT NULL DEFAULT '',
+    created_by     VARCHAR(100) NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_id ON stock_movements(product_id, created_at DESC);
+
+-- Saldo awal buat produk yang udah ada sebelum ledger dipake
+INSERT INTO stock_movements (product_id, reason, quantity, stock_after, note)
+SELECT p.id, 'initial', p.stock, p.stock, 'saldo awal ledger'
+FROM products p
+WHERE NOT EXISTS (SELECT 1 FROM stock_movements sm WHERE sm.product_id = p.id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS stock_opname_counts;
+DROP TABLE IF EXISTS stock_opnames;
@@ -0,0 +1,26 @@
+-- Stock opname (hitung fisik)
+CREATE TABLE IF NOT EXISTS stock_opnames (
+    id          SERIAL PRIMARY KEY,
+    status      VARCHAR(20) NOT NULL DEFAULT 'open',
+    category_id INT REFERENCES categories(id),
+    lock_sales  BOOLEAN NOT NULL DEFAULT FALSE,
+    note        TEXT NOT NULL DEFAULT '',
+    created_by  VARCHAR(100) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    posted_by   VARCHAR(100) NOT NULL DEFAULT '',
+    posted_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_opnames_open_lock ON stock_opnames(category_id) WHERE status = 'open' AND lock_sales;
+
+-- satu baris per produk per sesi, submit ulang = hitung ulang (overwrite)
+CREATE TABLE IF NOT EXISTS stock_opname_counts (
+    id           SERIAL PRIMARY KEY,
+    opname_id    INT NOT NULL REFERENCES stock_opnames(id) ON DELETE CASCADE,
+    product_id   INT NOT NULL REFERENCES products(id),
+    system_stock INT NOT NULL,
+    counted_qty  INT NOT NULL CHECK (counted_qty >= 0),
+    counted_by   VARCHAR(100) NOT NULL DEFAULT '',
+    counted_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (opname_id, product_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE products DROP COLUMN IF EXISTS reorder_qty;
+ALTER TABLE products DROP COLUMN IF EXISTS min_stock;
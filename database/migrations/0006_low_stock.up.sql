@@ -0,0 +1,3 @@
+-- Threshold stok minimum & jumlah reorder per produk
+ALTER TABLE products ADD COLUMN IF NOT EXISTS min_stock INT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_qty INT NOT NULL DEFAULT 0;
@@ -0,0 +1,47 @@
+-- Supplier & purchase order
+CREATE TABLE IF NOT EXISTS suppliers (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    phone      VARCHAR(50) NOT NULL DEFAULT '',
+    address    TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id          SERIAL PRIMARY KEY,
+    supplier_id INT NOT NULL REFERENCES suppliers(id),
+    status      VARCHAR(20) NOT NULL DEFAULT 'draft',
+    note        TEXT NOT NULL DEFAULT '',
+    created_by  VARCHAR(100) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at     TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS purchase_order_items (
+    id                SERIAL PRIMARY KEY,
+    purchase_order_id INT NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    product_id        INT NOT NULL REFERENCES products(id),
+    quantity_ordered  INT NOT NULL CHECK (quantity_ordered > 0),
+    quantity_received INT NOT NULL DEFAULT 0 CHECK (quantity_received >= 0),
+    unit_cost         INT NOT NULL DEFAULT 0,
+    UNIQUE (purchase_order_id, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS goods_receipts (
+    id                SERIAL PRIMARY KEY,
+    purchase_order_id INT NOT NULL REFERENCES purchase_orders(id),
+    note              TEXT NOT NULL DEFAULT '',
+    received_by       VARCHAR(100) NOT NULL DEFAULT '',
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS goods_receipt_items (
+    id                     SERIAL PRIMARY KEY,
+    goods_receipt_id       INT NOT NULL REFERENCES goods_receipts(id) ON DELETE CASCADE,
+    purchase_order_item_id INT NOT NULL REFERENCES purchase_order_items(id),
+    product_id             INT NOT NULL REFERENCES products(id),
+    quantity               INT NOT NULL CHECK (quantity > 0),
+    unit_cost              INT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_goods_receipt_items_product_id ON goods_receipt_items(product_id);
@@ -0,0 +1,2 @@
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS cost_price;
+ALTER TABLE products DROP COLUMN IF EXISTS cost_price;
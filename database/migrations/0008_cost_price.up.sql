@@ -0,0 +1,4 @@
+-- Harga pokok (moving average, di-update tiap penerimaan barang)
+-- dan snapshot HPP per baris penjualan buat hitung margin / COGS
+ALTER TABLE products ADD COLUMN IF NOT EXISTS cost_price INT NOT NULL DEFAULT 0;
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS cost_price INT NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- Role staff buat RBAC. User yang udah ada sebelum kolom ini jadi cashier,
+-- kecuali user pertama (bootstrap admin) yang dijadiin owner biar ga ada yang kekunci
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'cashier'
+    CHECK (role IN ('cashier', 'supervisor', 'owner'));
+UPDATE users SET role = 'owner'
+WHERE id = (SELECT MIN(id) FROM users)
+  AND NOT EXISTS (SELECT 1 FROM users WHERE role = 'owner');
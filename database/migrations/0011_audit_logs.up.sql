@@ -0,0 +1,18 @@
+-- Audit log: satu baris per create/update/delete, ditulis di DB transaction
+-- yang sama dengan perubahannya
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id          SERIAL PRIMARY KEY,
+    actor_id    INT REFERENCES users(id) ON DELETE SET NULL,
+    actor       VARCHAR(100) NOT NULL DEFAULT '',
+    action      VARCHAR(20) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id   INT NOT NULL,
+    before_data JSONB,
+    after_data  JSONB,
+    request_id  VARCHAR(64) NOT NULL DEFAULT '',
+    ip          VARCHAR(64) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity_type, entity_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs (actor, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
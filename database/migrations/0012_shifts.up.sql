@@ -0,0 +1,32 @@
+-- Shift kasir & rekonsiliasi laci kas. Satu user cuma boleh punya satu shift open
+CREATE TABLE IF NOT EXISTS shifts (
+    id            SERIAL PRIMARY KEY,
+    user_id       INT NOT NULL REFERENCES users(id),
+    cashier       VARCHAR(100) NOT NULL DEFAULT '',
+    status        VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    opening_float INT NOT NULL DEFAULT 0 CHECK (opening_float >= 0),
+    expected_cash INT,
+    counted_cash  INT,
+    difference    INT,
+    note          TEXT NOT NULL DEFAULT '',
+    closing_note  TEXT NOT NULL DEFAULT '',
+    opened_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_at     TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shifts_one_open_per_user ON shifts(user_id) WHERE status = 'open';
+
+CREATE TABLE IF NOT EXISTS cash_movements (
+    id         SERIAL PRIMARY KEY,
+    shift_id   INT NOT NULL REFERENCES shifts(id),
+    type       VARCHAR(10) NOT NULL CHECK (type IN ('in', 'out')),
+    amount     INT NOT NULL CHECK (amount > 0),
+    reason     TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_cash_movements_shift_id ON cash_movements(shift_id);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS shift_id INT REFERENCES shifts(id);
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS shift_id INT REFERENCES shifts(id);
+CREATE INDEX IF NOT EXISTS idx_transactions_shift_id ON transactions(shift_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_shift_id ON refunds(shift_id);
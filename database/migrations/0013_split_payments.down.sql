@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS change_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS paid_amount;
+DROP TABLE IF EXISTS transaction_payments;
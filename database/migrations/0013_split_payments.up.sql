@@ -0,0 +1,24 @@
+-- Pembayaran per transaksi (split tender). amount = yang masuk ke penjualan,
+-- tendered = yang diserahin customer; selisihnya kembalian (cuma cash)
+CREATE TABLE IF NOT EXISTS transaction_payments (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    method         VARCHAR(30) NOT NULL
+        CHECK (method IN ('cash', 'debit', 'credit', 'qris', 'ewallet', 'store_credit')),
+    amount         INT NOT NULL CHECK (amount >= 0),
+    tendered       INT NOT NULL CHECK (tendered >= amount),
+    reference      VARCHAR(100) NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_transaction_payments_transaction_id ON transaction_payments(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_payments_method ON transaction_payments(method);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS paid_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS change_amount INT NOT NULL DEFAULT 0;
+
+-- transaksi lama dianggap dibayar pas pake payment_method di header
+INSERT INTO transaction_payments (transaction_id, method, amount, tendered)
+SELECT t.id, t.payment_method, t.total_amount, t.total_amount
+FROM transactions t
+WHERE t.payment_method IN ('cash', 'debit', 'credit', 'qris', 'ewallet', 'store_credit')
+  AND NOT EXISTS (SELECT 1 FROM transaction_payments tp WHERE tp.transaction_id = t.id);
+UPDATE transactions SET paid_amount = total_amount WHERE paid_amount = 0;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pending_sale_items;
+DROP TABLE IF EXISTS pending_sales;
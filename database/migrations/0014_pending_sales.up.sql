@@ -0,0 +1,32 @@
+-- Sale QRIS dinamis yang nunggu pembayaran. Status cuma bisa pending -> paid/expired/failed.
+-- Stok ga disentuh sampai paid, kecuali stock_reserved (QRIS_STOCK_POLICY=reserve)
+CREATE TABLE IF NOT EXISTS pending_sales (
+    id             SERIAL PRIMARY KEY,
+    user_id        INT NOT NULL REFERENCES users(id),
+    cashier        VARCHAR(100) NOT NULL DEFAULT '',
+    shift_id       INT REFERENCES shifts(id),
+    status         VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'paid', 'expired', 'failed')),
+    total_amount   INT NOT NULL CHECK (total_amount >= 0),
+    stock_reserved BOOLEAN NOT NULL DEFAULT FALSE,
+    gateway        VARCHAR(30) NOT NULL DEFAULT '',
+    charge_id      VARCHAR(100) UNIQUE,
+    qr_string      TEXT NOT NULL DEFAULT '',
+    expires_at     TIMESTAMPTZ NOT NULL,
+    transaction_id INT REFERENCES transactions(id),
+    note           TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    settled_at     TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_pending_sales_pending ON pending_sales(expires_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS pending_sale_items (
+    id              SERIAL PRIMARY KEY,
+    pending_sale_id INT NOT NULL REFERENCES pending_sales(id) ON DELETE CASCADE,
+    product_id      INT NOT NULL REFERENCES products(id),
+    product_name    VARCHAR(255) NOT NULL,
+    price           INT NOT NULL,
+    quantity        INT NOT NULL CHECK (quantity > 0),
+    subtotal        INT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_pending_sale_items_pending_sale_id ON pending_sale_items(pending_sale_id);
@@ -0,0 +1,24 @@
+-- Promo. Target & hari disimpan sebagai array; kosong = semua produk / setiap hari.
+-- Jendela waktu [starts_at, ends_at), NULL = tanpa batas
+CREATE TABLE IF NOT EXISTS promotions (
+    id           SERIAL PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL,
+    type         VARCHAR(30) NOT NULL CHECK (type IN ('buy_x_get_y', 'percent', 'cart_amount', 'bundle')),
+    priority     INT NOT NULL DEFAULT 0,
+    stackable    BOOLEAN NOT NULL DEFAULT FALSE,
+    active       BOOLEAN NOT NULL DEFAULT TRUE,
+    starts_at    TIMESTAMPTZ,
+    ends_at      TIMESTAMPTZ,
+    days         INT[] NOT NULL DEFAULT '{}',
+    product_ids  INT[] NOT NULL DEFAULT '{}',
+    category_ids INT[] NOT NULL DEFAULT '{}',
+    buy_qty      INT NOT NULL DEFAULT 0,
+    free_qty     INT NOT NULL DEFAULT 0,
+    percent      INT NOT NULL DEFAULT 0 CHECK (percent BETWEEN 0 AND 100),
+    amount       INT NOT NULL DEFAULT 0,
+    min_subtotal INT NOT NULL DEFAULT 0,
+    bundle_qty   INT NOT NULL DEFAULT 0,
+    bundle_price INT NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_promotions_active ON promotions(active, ends_at);
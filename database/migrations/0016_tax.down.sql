@@ -0,0 +1,11 @@
+ALTER TABLE refund_items DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE refund_items DROP COLUMN IF EXISTS net_amount;
+ALTER TABLE refunds DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS net_amount;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS tax_exempt;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_mode;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_rate_bp;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE categories DROP COLUMN IF EXISTS tax_exempt;
+ALTER TABLE products DROP COLUMN IF EXISTS tax_exempt;
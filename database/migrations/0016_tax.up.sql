@@ -0,0 +1,19 @@
+-- PPN. Produk bebas PPN kalau flag produk atau kategorinya nyala.
+-- Tarif & mode di-snapshot per transaksi (tax_rate_bp dalam basis point, 1100 = 11%),
+-- tiap baris nyimpen DPP (net_amount) & PPN-nya sendiri
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_exempt BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS tax_exempt BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_rate_bp INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_mode VARCHAR(20) NOT NULL DEFAULT 'inclusive';
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS tax_exempt BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS net_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE refund_items ADD COLUMN IF NOT EXISTS net_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE refund_items ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0;
+
+-- transaksi & refund sebelum ada PPN dianggap tanpa pajak, DPP = subtotal
+UPDATE transaction_items SET net_amount = subtotal WHERE net_amount = 0 AND tax_amount = 0;
+UPDATE refund_items SET net_amount = subtotal WHERE net_amount = 0 AND tax_amount = 0;
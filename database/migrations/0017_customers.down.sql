@@ -0,0 +1,10 @@
+-- gagal kalau masih ada pembayaran pakai poin, sengaja ga dihapus otomatis
+ALTER TABLE transaction_payments DROP CONSTRAINT IF EXISTS transaction_payments_method_check;
+ALTER TABLE transaction_payments ADD CONSTRAINT transaction_payments_method_check
+    CHECK (method IN ('cash', 'debit', 'credit', 'qris', 'ewallet', 'store_credit'));
+
+DROP TABLE IF EXISTS customer_points;
+ALTER TABLE transactions DROP COLUMN IF EXISTS points_used;
+ALTER TABLE transactions DROP COLUMN IF EXISTS points_earned;
+ALTER TABLE transactions DROP COLUMN IF EXISTS customer_id;
+DROP TABLE IF EXISTS customers;
@@ -0,0 +1,31 @@
+-- Member & poin. Nomor HP jadi kunci (format 08xxx), saldo di customers.points,
+-- semua perubahan saldo dicatat di ledger customer_points
+CREATE TABLE IF NOT EXISTS customers (
+    id         SERIAL PRIMARY KEY,
+    phone      VARCHAR(20) NOT NULL UNIQUE,
+    name       VARCHAR(255) NOT NULL DEFAULT '',
+    points     INT NOT NULL DEFAULT 0 CHECK (points >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS customer_id INT REFERENCES customers(id);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS points_earned INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS points_used INT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_transactions_customer_id ON transactions(customer_id, created_at);
+
+CREATE TABLE IF NOT EXISTS customer_points (
+    id             SERIAL PRIMARY KEY,
+    customer_id    INT NOT NULL REFERENCES customers(id),
+    reason         VARCHAR(20) NOT NULL CHECK (reason IN ('earn', 'redeem', 'refund')),
+    points         INT NOT NULL,
+    balance        INT NOT NULL CHECK (balance >= 0),
+    transaction_id INT REFERENCES transactions(id),
+    refund_id      INT REFERENCES refunds(id),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_customer_points_customer_id ON customer_points(customer_id, id);
+
+-- metode bayar baru: points
+ALTER TABLE transaction_payments DROP CONSTRAINT IF EXISTS transaction_payments_method_check;
+ALTER TABLE transaction_payments ADD CONSTRAINT transaction_payments_method_check
+    CHECK (method IN ('cash', 'debit', 'credit', 'qris', 'ewallet', 'store_credit', 'points'));
@@ -0,0 +1,20 @@
+-- Uang refund per metode bayar, dibagi proporsional sama pembayaran transaksi asal.
+-- Laci shift cuma kepotong baris method = 'cash', bukan total refund
+CREATE TABLE IF NOT EXISTS refund_payments (
+    id        SERIAL PRIMARY KEY,
+    refund_id INT NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+    method    VARCHAR(30) NOT NULL
+        CHECK (method IN ('cash', 'debit', 'credit', 'qris', 'ewallet', 'store_credit', 'points')),
+    amount    INT NOT NULL CHECK (amount >= 0)
+);
+CREATE INDEX IF NOT EXISTS idx_refund_payments_refund_id ON refund_payments(refund_id);
+
+-- refund lama dibagi pake rasio pembayaran transaksinya (dibulatkan ke bawah per metode)
+INSERT INTO refund_payments (refund_id, method, amount)
+SELECT rf.id, tp.method, SUM(tp.amount)::BIGINT * rf.total_amount / t.total_amount
+FROM refunds rf
+JOIN transactions t ON t.id = rf.transaction_id
+JOIN transaction_payments tp ON tp.transaction_id = t.id
+WHERE t.total_amount > 0
+  AND NOT EXISTS (SELECT 1 FROM refund_payments rp WHERE rp.refund_id = rf.id)
+GROUP BY rf.id, tp.method, rf.total_amount, t.total_amount;
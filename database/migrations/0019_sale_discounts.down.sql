@@ -0,0 +1,4 @@
+ALTER TABLE pending_sale_items DROP COLUMN IF EXISTS cart_discount;
+ALTER TABLE pending_sale_items DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS cart_discount;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS discount_amount;
@@ -0,0 +1,8 @@
+-- Diskon promo yang kena pas checkout, disimpan per baris:
+-- discount_amount = potongan promo baris itu, cart_discount = bagian diskon keranjang yang dialokasiin ke baris itu.
+-- net_amount + tax_amount udah dihitung dari subtotal - discount_amount - cart_discount.
+-- Sale QRIS nyimpen diskon yang sama biar nominal QR = total transaksi pas lunas
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS discount_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS cart_discount INT NOT NULL DEFAULT 0;
+ALTER TABLE pending_sale_items ADD COLUMN IF NOT EXISTS discount_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE pending_sale_items ADD COLUMN IF NOT EXISTS cart_discount INT NOT NULL DEFAULT 0;
@@ -0,0 +1,48 @@
+package dummy
+
+import (
+	"fmt"
+	"kasir-api/models"
+)
+
+// Categories - kategori dummy dalam bentuk model, siap di-seed
+func Categories() []models.Category {
+	result := make([]models.Category, 0, len(categories))
+	for _, c := range categories {
+		result = append(result, models.Category{Name: c.Name, Description: c.Description})
+	}
+	return result
+}
+
+// Products - katalog dummy dikali scale. Salinan ke-2 dst namanya dikasih suffix "#N"
+// dan harganya digeser dikit biar ga identik, tapi tetap deterministik
+// jadi seed ulang dengan scale yang sama ga nambah produk baru.
+// Kategori diisi lewat CategoryName karena ID di database belum tentu sama
+func Products(scale int) []models.Product {
+	if scale < 1 {
+		scale = 1
+	}
+	names := make(map[int]string, len(categories))
+	for _, c := range categories {
+		names[c.ID] = c.Name
+	}
+
+	result := make([]models.Product, 0, len(produk)*scale)
+	for n := 1; n <= scale; n++ {
+		for _, p := range produk {
+			product := models.Product{
+				Name:         p.Nama,
+				Price:        p.Harga,
+				Stock:        p.Stok,
+				CategoryName: names[p.KategoriID],
+			}
+			if n > 1 {
+				product.Name = fmt.Sprintf("%s #%d", p.Nama, n)
+				// naik 0-20% dari harga asli, dibulatin ke ratusan
+				product.Price = (p.Harga*(100+(n-1)%5*5)/100 + 50) / 100 * 100
+			}
+			result = append(result, product)
+		}
+	}
+	return result
+}
@@ -1,6 +1,6 @@
 // buat misahin data aja biar rapi n bisa generate banyak sama gpt isinya
 
-package main
+package dummy
 
 type Category struct {
 	ID int `json:"id"`
@@ -20,6 +20,3 @@ var categories = []Category{
     {ID: 9, Name: "Produk Kesehatan", Description: "Kategori untuk vitamin dan obat-obatan"},
     {ID: 10, Name: "Elektronik", Description: "Kategori untuk barang elektronik"},
 }
-
-// jangan lupa pake package main biar bisa ke detect satu namespace dan di link pas compiling dengan main.go sebagai entry point 
-//jangan lupa compile semuanya pake go run . (all) aja dlu 
\ No newline at end of file
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/internal/auth"
+	"kasir-api/internal/requestid"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AuditHandler struct {
+	service *services.AuditService
+	logger  *slog.Logger
+}
+
+func NewAuditHandler(service *services.AuditService, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{service: service, logger: logger}
+}
+
+// / HandleAuditLogs - GET /api/audit?entity_type=&entity_id=&actor=&from=&to=&page=&limit=
+// from/to boleh YYYY-MM-DD (to inklusif sampai akhir hari) atau RFC3339
+func (h *AuditHandler) HandleAuditLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	h.logger.Info("Handler: GET audit logs request")
+	q := r.URL.Query()
+
+	filter := models.AuditFilter{
+		EntityType: q.Get("entity_type"),
+		Actor:      q.Get("actor"),
+	}
+	var err error
+	if v := q.Get("entity_id"); v != "" {
+		if filter.EntityID, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid entity_id")
+			return
+		}
+	}
+	if v := q.Get("from"); v != "" {
+		if filter.From, err = parseTimeParam(v, false); err != nil {
+			badRequest(w, r, "Invalid from, use YYYY-MM-DD or RFC3339")
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if filter.To, err = parseTimeParam(v, true); err != nil {
+			badRequest(w, r, "Invalid to, use YYYY-MM-DD or RFC3339")
+			return
+		}
+	}
+	if v := q.Get("page"); v != "" {
+		if filter.Page, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid page")
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid limit")
+			return
+		}
+	}
+
+	list, err := h.service.GetAll(filter)
+	if err != nil {
+		h.logger.Error("Handler: Failed to get audit logs", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+	h.logger.Info("Handler: Successfully returned audit logs", "count", len(list.Data))
+}
+
+// parseTimeParam - endOfDay true: tanggal tanpa jam dianggap sampai akhir hari itu
+func parseTimeParam(v string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return t, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// auditActor - actor buat audit log: user dari token, request ID dan IP client
+func auditActor(r *http.Request) models.AuditActor {
+	actor := models.AuditActor{
+		RequestID: requestid.FromContext(r.Context()),
+		IP:        clientIP(r),
+	}
+	if claims, ok := auth.ClaimsFromContext(r.Context()); ok {
+		actor.UserID = claims.UserID
+		actor.Username = claims.Username
+	}
+	return actor
+}
+
+// clientIP - di belakang proxy (Railway) IP asli ada di X-Forwarded-For.
+// Yang diambil entry paling kanan, yang ditambahin proxy kita sendiri,
+// karena entry di kirinya bisa dipalsuin client
+func clientIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		parts := strings.Split(xff, ",")
+		if ip := strings.TrimSpace(parts[len(parts)-1]); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/internal/apperror"
+	"kasir-api/internal/auth"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+)
+
+type AuthHandler struct {
+	service     *services.AuthService
+	userService *services.UserService
+	logger      *slog.Logger
+}
+
+func NewAuthHandler(service *services.AuthService, userService *services.UserService, logger *slog.Logger) *AuthHandler {
+	return &AuthHandler{service: service, userService: userService, logger: logger}
+}
+
+// / Login - POST /api/auth/login
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	h.logger.Info("Handler: POST login request")
+	var req models.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	tokens, err := h.service.Login(&req)
+	if err != nil {
+		h.logger.Error("Handler: Failed to login", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokens)
+	h.logger.Info("Handler: Login successful", "user_id", tokens.User.ID)
+}
+
+// / Refresh - POST /api/auth/refresh
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	h.logger.Info("Handler: POST refresh token request")
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	tokens, err := h.service.Refresh(req.RefreshToken)
+	if err != nil {
+		h.logger.Error("Handler: Failed to refresh token", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// / Logout - POST /api/auth/logout
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	if err := h.service.Logout(req.RefreshToken); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// / Me - GET /api/auth/me
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		apperror.Write(w, r, http.StatusUnauthorized, apperror.Body{Code: "unauthorized", Message: "Unauthorized"})
+		return
+	}
+
+	user, err := h.userService.GetByID(claims.UserID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// currentStaff - user yang lagi login, kosong kalau request-nya ga bawa token
+func currentStaff(r *http.Request) models.Staff {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return models.Staff{}
+	}
+	return models.Staff{UserID: claims.UserID, Username: claims.Username, Role: claims.Role}
+}
+
+// currentUsername - username dari token kalau ada, kalau ga ada pakai nilai dari body
+func currentUsername(r *http.Request, fallback string) string {
+	if claims, ok := auth.ClaimsFromContext(r.Context()); ok {
+		return claims.Username
+	}
+	return fallback
+}
@@ -29,25 +29,39 @@ func (h *CategoryHandler) HandleCategories(w http.ResponseWriter, r *http.Reques
 	case http.MethodPost:
 		h.Create(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r)
 	}
 }
 
 //h itu handler w writer r request
 
 // masuk requestnya disini nih yang parameternya write sama read
+// GetAll - GET /categories?tax_exempt=&sort=name&limit=&cursor=&include_total=
 func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info("Handler: GET all categories request")
-	categories, err := h.service.GetAll()
+	q := r.URL.Query()
+	params, err := parseListParams(q)
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+	taxExempt, err := parseOptionalBool(q, "tax_exempt")
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	list, err := h.service.GetAll(models.CategoryFilter{ListParams: params, TaxExempt: taxExempt})
 	if err != nil {
 		h.logger.Error("Handler: Failed to get all categories", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondError(w, r, err)
 		return
 	}
 
+	setNextLink(w, r, list.NextCursor)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(categories)
-	h.logger.Info("Handler: Successfully returned all categories", "count", len(categories))
+	json.NewEncoder(w).Encode(list)
+	h.logger.Info("Handler: Successfully returned all categories", "count", len(list.Data))
 }
 
 func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -57,14 +71,14 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&category)
 	if err != nil {
 		h.logger.Error("Handler: Invalid request payload", "error", err)
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		badRequest(w, r, "Invalid request payload")
 		return
 	}
 
-	err = h.service.Create(&category)
+	err = h.service.Create(&category, auditActor(r))
 	if err != nil {
 		h.logger.Error("Handler: Failed to create category", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondError(w, r, err)
 		return
 	}
 
@@ -84,7 +98,7 @@ func (h *CategoryHandler) HandleCategoryByID(w http.ResponseWriter, r *http.Requ
 	case http.MethodDelete:
 		h.Delete(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r)
 	}
 }
 
@@ -95,7 +109,7 @@ func (h *CategoryHandler) getByID(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		h.logger.Error("Handler: Invalid category ID", "error", err, "id_str", idStr)
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		badRequest(w, r, "Invalid product ID")
 		return
 	}
 
@@ -103,7 +117,7 @@ func (h *CategoryHandler) getByID(w http.ResponseWriter, r *http.Request) {
 	category, err := h.service.GetByID(id)
 	if err != nil {
 		h.logger.Error("Handler: Category not found", "error", err, "id", id)
-		http.Error(w, err.Error(), http.StatusNotFound)
+		respondError(w, r, err)
 		return
 	}
 
@@ -118,17 +132,17 @@ func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		h.logger.Error("Handler: Invalid category ID", "error", err, "id_str", idStr)
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		badRequest(w, r, "Invalid product ID")
 		return
 	}
 
 	h.logger.Info("Handler: DELETE category request", "id", id)
 	//execute bussiness logic inside service module
-	err = h.service.Delete(id)
+	err = h.service.Delete(id, auditActor(r))
 
 	if err != nil {
 		h.logger.Error("Handler: Failed to delete category (may have products)", "error", err, "id", id)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondError(w, r, err)
 		return
 	}
 
@@ -144,7 +158,7 @@ func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		h.logger.Error("Handler: Invalid category ID", "error", err, "id_str", idStr)
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		badRequest(w, r, "Invalid product ID")
 		return
 	}
 
@@ -153,15 +167,15 @@ func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	err = json.NewDecoder(r.Body).Decode(&category)
 	if err != nil {
 		h.logger.Error("Handler: Invalid request body", "error", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		badRequest(w, r, "Invalid request body")
 		return
 	}
 
 	category.ID = id
-	err = h.service.Update(&category)
+	err = h.service.Update(&category, auditActor(r))
 	if err != nil {
 		h.logger.Error("Handler: Failed to update category", "error", err, "id", id)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		respondError(w, r, err)
 		return
 	}
 
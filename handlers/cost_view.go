@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"kasir-api/internal/auth"
+	"kasir-api/models"
+	"net/http"
+)
+
+// Harga pokok & margin cuma boleh dilihat owner, sama kayak /api/reports/margin.
+// Buat role di bawahnya response dibungkus view di bawah: field pointer nil di view
+// nutupin field dengan nama JSON yang sama di struct yang di-embed, jadi ga ikut ke-encode
+
+// canSeeCost - true kalau yang request owner
+func canSeeCost(r *http.Request) bool {
+	return auth.HasRole(currentStaff(r).Role, models.RoleOwner)
+}
+
+type productView struct {
+	models.Product
+	CostPrice     *int     `json:"cost_price,omitempty"`
+	Margin        *int     `json:"margin,omitempty"`
+	MarginPercent *float64 `json:"margin_percent,omitempty"`
+}
+
+type productListView struct {
+	*models.ProductList
+	Data []productView `json:"data"`
+}
+
+type lowStockGroupView struct {
+	models.LowStockGroup
+	Products []productView `json:"products"`
+}
+
+type transactionItemView struct {
+	models.TransactionItem
+	CostPrice *int `json:"cost_price,omitempty"`
+}
+
+type transactionView struct {
+	*models.Transaction
+	Items []transactionItemView `json:"items,omitempty"`
+}
+
+type pendingSaleView struct {
+	*models.PendingSale
+	Transaction any `json:"transaction,omitempty"`
+}
+
+func productViews(products []models.Product) []productView {
+	views := make([]productView, len(products))
+	for i, p := range products {
+		views[i] = productView{Product: p}
+	}
+	return views
+}
+
+// productResponse / productListResponse / lowStockResponse / transactionResponse / pendingSaleResponse -
+// balikin data apa adanya buat owner, versi tanpa harga pokok & margin buat role lain
+func productResponse(r *http.Request, p *models.Product) any {
+	if canSeeCost(r) {
+		return p
+	}
+	return productView{Product: *p}
+}
+
+func productListResponse(r *http.Request, list *models.ProductList) any {
+	if canSeeCost(r) {
+		return list
+	}
+	return productListView{ProductList: list, Data: productViews(list.Data)}
+}
+
+func lowStockResponse(r *http.Request, groups []models.LowStockGroup) any {
+	if canSeeCost(r) {
+		return groups
+	}
+	views := make([]lowStockGroupView, len(groups))
+	for i, g := range groups {
+		views[i] = lowStockGroupView{LowStockGroup: g, Products: productViews(g.Products)}
+	}
+	return views
+}
+
+func transactionResponse(r *http.Request, t *models.Transaction) any {
+	if t == nil || canSeeCost(r) {
+		return t
+	}
+	view := transactionView{Transaction: t}
+	for _, item := range t.Items {
+		view.Items = append(view.Items, transactionItemView{TransactionItem: item})
+	}
+	return view
+}
+
+func pendingSaleResponse(r *http.Request, sale *models.PendingSale) any {
+	if sale.Transaction == nil || canSeeCost(r) {
+		return sale
+	}
+	return pendingSaleView{PendingSale: sale, Transaction: transactionResponse(r, sale.Transaction)}
+}
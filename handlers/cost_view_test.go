@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/internal/auth"
+	"kasir-api/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func requestAs(role string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	return r.WithContext(auth.WithClaims(r.Context(), &auth.Claims{UserID: 1, Username: role, Role: role}))
+}
+
+func encode(t *testing.T, v any) string {
+	t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestCostHiddenBelowOwner(t *testing.T) {
+	product := models.Product{ID: 1, Name: "Teh Botol", Price: 5000, CostPrice: 3500, Stock: 10}
+	product.CalculateMargin()
+	list := &models.ProductList{Data: []models.Product{product}, Limit: 50}
+	groups := []models.LowStockGroup{{CategoryID: 1, CategoryName: "Minuman", Products: []models.Product{product}}}
+	transaction := &models.Transaction{ID: 9, TotalAmount: 5000, Items: []models.TransactionItem{
+		{ProductID: 1, ProductName: "Teh Botol", Price: 5000, CostPrice: 3500, Quantity: 1, Subtotal: 5000},
+	}}
+	sale := &models.PendingSale{ID: 3, Transaction: transaction}
+
+	responses := map[string]func(r *http.Request) any{
+		"product":      func(r *http.Request) any { return productResponse(r, &product) },
+		"product list": func(r *http.Request) any { return productListResponse(r, list) },
+		"low stock":    func(r *http.Request) any { return lowStockResponse(r, groups) },
+		"transaction":  func(r *http.Request) any { return transactionResponse(r, transaction) },
+		"pending sale": func(r *http.Request) any { return pendingSaleResponse(r, sale) },
+	}
+	for name, response := range responses {
+		for _, role := range []string{models.RoleCashier, models.RoleSupervisor} {
+			body := encode(t, response(requestAs(role)))
+			if strings.Contains(body, "cost_price") || strings.Contains(body, "margin") {
+				t.Fatalf("%s as %s leaks cost: %s", name, role, body)
+			}
+			if !strings.Contains(body, `"Teh Botol"`) {
+				t.Fatalf("%s as %s lost the product: %s", name, role, body)
+			}
+		}
+		body := encode(t, response(requestAs(models.RoleOwner)))
+		if !strings.Contains(body, `"cost_price":3500`) {
+			t.Fatalf("%s as owner missing cost_price: %s", name, body)
+		}
+	}
+
+	// request tanpa token (misal webhook QRIS) juga ga boleh lihat harga pokok
+	body := encode(t, pendingSaleResponse(httptest.NewRequest(http.MethodPost, "/", nil), sale))
+	if strings.Contains(body, "cost_price") {
+		t.Fatalf("anonymous pending sale leaks cost: %s", body)
+	}
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type CustomerHandler struct {
+	service *services.CustomerService
+	logger  *slog.Logger
+}
+
+func NewCustomerHandler(service *services.CustomerService, logger *slog.Logger) *CustomerHandler {
+	return &CustomerHandler{service: service, logger: logger}
+}
+
+// / HandleCustomers - GET /api/customers?q=0812, POST /api/customers
+func (h *CustomerHandler) HandleCustomers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (h *CustomerHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: GET all customers request")
+	customers, err := h.service.GetAll(r.URL.Query().Get("q"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customers)
+	h.logger.Info("Handler: Successfully returned customers", "count", len(customers))
+}
+
+// Create - body: {"phone": "081234567890", "name": "Budi"}
+func (h *CustomerHandler) Create(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: POST create customer request")
+	var customer models.Customer
+	if err := json.NewDecoder(r.Body).Decode(&customer); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	if err := h.service.Create(&customer, auditActor(r)); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(customer)
+	h.logger.Info("Handler: Customer created successfully", "id", customer.ID)
+}
+
+// / HandleCustomerByID - GET/PUT /api/customers/{id}
+func (h *CustomerHandler) HandleCustomerByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r, id)
+	case http.MethodPut:
+		h.Update(w, r, id)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (h *CustomerHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	h.logger.Info("Handler: GET customer by ID request", "id", id)
+	customer, err := h.service.GetByID(id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+}
+
+func (h *CustomerHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	h.logger.Info("Handler: PUT update customer request", "id", id)
+	var customer models.Customer
+	if err := json.NewDecoder(r.Body).Decode(&customer); err != nil {
+		h.logger.Error("Handler: Invalid request body", "error", err)
+		badRequest(w, r, "Invalid request body")
+		return
+	}
+
+	customer.ID = id
+	if err := h.service.Update(&customer, auditActor(r)); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+	h.logger.Info("Handler: Customer updated successfully", "id", id)
+}
+
+// / HandlePoints - GET /api/customers/{id}/points?limit=50
+// saldo poin, nilai rupiahnya, dan mutasi poin terbaru
+func (h *CustomerHandler) HandlePoints(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	h.logger.Info("Handler: GET customer points request", "id", id)
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid limit")
+			return
+		}
+	}
+
+	points, err := h.service.Points(id, limit)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(points)
+}
+
+// / HandleTransactions - GET /api/customers/{id}/transactions?page=&limit=
+// riwayat belanja member, terbaru duluan
+func (h *CustomerHandler) HandleTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	h.logger.Info("Handler: GET customer transactions request", "id", id)
+	q := r.URL.Query()
+	var filter models.TransactionFilter
+	var err error
+	if v := q.Get("page"); v != "" {
+		if filter.Page, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid page")
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid limit")
+			return
+		}
+	}
+
+	list, err := h.service.Transactions(id, filter)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+	h.logger.Info("Handler: Successfully returned customer transactions", "id", id, "count", len(list.Data))
+}
+
+func (h *CustomerHandler) pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Handler: Invalid customer ID", "error", err, "id_str", idStr)
+		badRequest(w, r, "Invalid customer ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *CustomerHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	h.logger.Error("Handler: Customer request failed", "error", err)
+	respondError(w, r, err)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"kasir-api/internal/apperror"
+	"net/http"
+)
+
+// errorStatuses - jenis error domain -> HTTP status + code di envelope.
+// Dicek urut, jadi error yang kebetulan nge-wrap dua jenis ikut yang paling atas
+var errorStatuses = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{apperror.ErrValidation, http.StatusUnprocessableEntity, "validation_error"},
+	{apperror.ErrNotFound, http.StatusNotFound, "not_found"},
+	{apperror.ErrForeignKey, http.StatusConflict, "foreign_key_violation"},
+	{apperror.ErrConflict, http.StatusConflict, "conflict"},
+	{apperror.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{apperror.ErrForbidden, http.StatusForbidden, "forbidden"},
+}
+
+// respondError - satu-satunya mapper error service/repo ke response HTTP.
+// Error yang ga punya jenis dianggap bug/infra: 500 dan pesan aslinya ga dibocorin ke client
+func respondError(w http.ResponseWriter, r *http.Request, err error) {
+	for _, s := range errorStatuses {
+		if errors.Is(err, s.kind) {
+			body := apperror.Body{Code: s.code, Message: err.Error()}
+			var appErr *apperror.Error
+			if errors.As(err, &appErr) && len(appErr.Details) > 0 {
+				body.Details = appErr.Details
+			}
+			apperror.Write(w, r, s.status, body)
+			return
+		}
+	}
+	apperror.Write(w, r, http.StatusInternalServerError, apperror.Body{Code: "internal_error", Message: "terjadi kesalahan di server"})
+}
+
+// badRequest - request-nya sendiri yang rusak (body bukan JSON, ID di path bukan angka, query salah format)
+func badRequest(w http.ResponseWriter, r *http.Request, message string) {
+	apperror.Write(w, r, http.StatusBadRequest, apperror.Body{Code: "bad_request", Message: message})
+}
+
+// invalid - validasi input di handler, statusnya sama kayak ErrValidation dari service
+func invalid(w http.ResponseWriter, r *http.Request, message string) {
+	apperror.Write(w, r, http.StatusUnprocessableEntity, apperror.Body{Code: "validation_error", Message: message})
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	apperror.Write(w, r, http.StatusMethodNotAllowed, apperror.Body{Code: "method_not_allowed", Message: "Method not allowed"})
+}
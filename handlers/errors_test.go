@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kasir-api/internal/apperror"
+	"kasir-api/internal/requestid"
+	"kasir-api/repositories"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRespondError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{"not found", repositories.ErrProductNotFound, http.StatusNotFound, "not_found", repositories.ErrProductNotFound.Error()},
+		{"wrapped conflict", fmt.Errorf("%w: id 3", repositories.ErrInsufficientStock), http.StatusConflict, "conflict", ""},
+		{"foreign key", repositories.ErrCategoryInUse, http.StatusConflict, "foreign_key_violation", repositories.ErrCategoryInUse.Error()},
+		{"validation", apperror.New(apperror.ErrValidation, "nama wajib diisi"), http.StatusUnprocessableEntity, "validation_error", "nama wajib diisi"},
+		{"unknown", errors.New("pq: connection refused"), http.StatusInternalServerError, "internal_error", "terjadi kesalahan di server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/produk/3", nil)
+			r.Header.Set(requestid.Header, "req-123")
+			requestid.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				respondError(w, r, tt.err)
+			})).ServeHTTP(rec, r)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			var body apperror.Body
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.code || body.RequestID != "req-123" {
+				t.Fatalf("body = %+v, want code %q and request_id req-123", body, tt.code)
+			}
+			if tt.message != "" && body.Message != tt.message {
+				t.Fatalf("message = %q, want %q", body.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestRespondErrorDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := &apperror.Error{Kind: apperror.ErrValidation, Message: "category_id 9 tidak ditemukan", Details: map[string]any{"field": "category_id"}}
+	respondError(rec, httptest.NewRequest(http.MethodPost, "/api/produk", nil), err)
+
+	var body apperror.Body
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Details["field"] != "category_id" {
+		t.Fatalf("details = %+v, want field category_id", body.Details)
+	}
+}
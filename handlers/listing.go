@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"kasir-api/models"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// parseListParams - ?sort=price,-name&limit=&cursor=&include_total=true.
+// Sort & cursor cuma dicek formatnya di repo, di sini diterusin mentah
+func parseListParams(q url.Values) (models.ListParams, error) {
+	params := models.ListParams{Sort: q.Get("sort"), Cursor: q.Get("cursor")}
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if params.Limit, err = strconv.Atoi(v); err != nil {
+			return params, errors.New("Invalid limit")
+		}
+	}
+	if v := q.Get("include_total"); v != "" {
+		if params.IncludeTotal, err = strconv.ParseBool(v); err != nil {
+			return params, errors.New("Invalid include_total")
+		}
+	}
+	return params, nil
+}
+
+// parseOptionalInt / parseOptionalBool - nil kalau query param-nya ga diisi
+func parseOptionalInt(q url.Values, key string) (*int, error) {
+	v := q.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, errors.New("Invalid " + key)
+	}
+	return &n, nil
+}
+
+func parseOptionalBool(q url.Values, key string) (*bool, error) {
+	v := q.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, errors.New("Invalid " + key)
+	}
+	return &b, nil
+}
+
+// setNextLink - header Link rel="next" (RFC 8288): URL request yang sama, cursor-nya diganti.
+// Relatif ke request, jadi aman di belakang reverse proxy
+func setNextLink(w http.ResponseWriter, r *http.Request, cursor string) {
+	if cursor == "" {
+		return
+	}
+	q := r.URL.Query()
+	q.Set("cursor", cursor)
+	next := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+	w.Header().Set("Link", "<"+next.String()+`>; rel="next"`)
+}
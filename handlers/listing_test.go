@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestParseProductFilter(t *testing.T) {
+	q, _ := url.ParseQuery("category_id=2&min_price=1000&in_stock=true&sort=price,-name&limit=20&include_total=1")
+	filter, err := parseProductFilter(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.CategoryID != 2 || *filter.MinPrice != 1000 || filter.MaxPrice != nil || !*filter.InStock ||
+		filter.Sort != "price,-name" || filter.Limit != 20 || !filter.IncludeTotal {
+		t.Fatalf("filter = %+v", filter)
+	}
+
+	for _, raw := range []string{"limit=abc", "min_price=murah", "in_stock=ada", "include_total=ya"} {
+		q, _ := url.ParseQuery(raw)
+		if _, err := parseProductFilter(q); err == nil {
+			t.Fatalf("parseProductFilter(%q) want error", raw)
+		}
+	}
+}
+
+func TestSetNextLink(t *testing.T) {
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/produk?sort=-price&limit=2&cursor=lama", nil)
+	setNextLink(rec, r, "baru")
+	want := `</api/produk?cursor=baru&limit=2&sort=-price>; rel="next"`
+	if got := rec.Header().Get("Link"); got != want {
+		t.Fatalf("Link = %q, want %q", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	setNextLink(rec, r, "")
+	if got := rec.Header().Get("Link"); got != "" {
+		t.Fatalf("Link on last page = %q, want empty", got)
+	}
+}
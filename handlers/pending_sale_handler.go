@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"kasir-api/internal/payment"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type PendingSaleHandler struct {
+	service *services.PendingSaleService
+	// fake - cuma diisi kalau PAYMENT_GATEWAY=fake (wajib APP_ENV=dev), buat endpoint simulasi bayar
+	fake   *payment.FakeGateway
+	logger *slog.Logger
+}
+
+func NewPendingSaleHandler(service *services.PendingSaleService, fake *payment.FakeGateway, logger *slog.Logger) *PendingSaleHandler {
+	return &PendingSaleHandler{service: service, fake: fake, logger: logger}
+}
+
+// / HandleSales - POST /api/qris/sales
+// body: {"items": [{"product_id": 1, "quantity": 2}]}, response berisi qr_string buat di-render jadi QR
+func (h *PendingSaleHandler) HandleSales(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	h.logger.Info("Handler: POST create QRIS sale request")
+	var req models.PendingSaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	staff := currentStaff(r)
+	req.UserID = staff.UserID
+	req.Cashier = staff.Username
+	sale, err := h.service.Create(r.Context(), &req, auditActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(pendingSaleResponse(r, sale))
+	h.logger.Info("Handler: QRIS sale created", "id", sale.ID, "charge_id", sale.ChargeID)
+}
+
+// / HandleSaleByID - GET /api/qris/sales/{id}, dipolling client sampai status bukan pending
+func (h *PendingSaleHandler) HandleSaleByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	h.logger.Info("Handler: GET QRIS sale request", "id", id)
+	sale, err := h.service.GetByID(r.Context(), id, currentStaff(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pendingSaleResponse(r, sale))
+}
+
+// / HandleCancel - POST /api/qris/sales/{id}/cancel
+func (h *PendingSaleHandler) HandleCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	h.logger.Info("Handler: POST cancel QRIS sale request", "id", id)
+	sale, err := h.service.Cancel(id, currentStaff(r), auditActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pendingSaleResponse(r, sale))
+	h.logger.Info("Handler: QRIS sale cancelled", "id", id)
+}
+
+// / HandleWebhook - POST /api/payments/qris/webhook, dipanggil gateway (tanpa JWT, pake signature)
+func (h *PendingSaleHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		h.logger.Error("Handler: Failed to read webhook body", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+	h.handleWebhook(w, r, r.Header, body)
+}
+
+// / HandleSimulate - POST /api/dev/qris/{charge_id}/simulate?status=paid|failed|expired
+// cuma ada kalau pake fake gateway: ubah status charge lalu kirim webhook-nya ke service
+func (h *PendingSaleHandler) HandleSimulate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+	if h.fake == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = payment.StatusPaid
+	}
+	header, body, err := h.fake.Simulate(r.PathValue("charge_id"), status)
+	if err != nil {
+		h.logger.Error("Handler: Failed to simulate QRIS payment", "error", err)
+		respondError(w, r, err)
+		return
+	}
+	h.handleWebhook(w, r, header, body)
+}
+
+func (h *PendingSaleHandler) handleWebhook(w http.ResponseWriter, r *http.Request, header http.Header, body []byte) {
+	sale, err := h.service.HandleWebhook(header, body)
+	if errors.Is(err, repositories.ErrSaleNotPending) {
+		// sale udah final; tetap 200 biar gateway berhenti retry, penanganannya manual dari log
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pendingSaleResponse(r, sale))
+	h.logger.Info("Handler: QRIS webhook processed", "id", sale.ID, "status", sale.Status)
+}
+
+func (h *PendingSaleHandler) pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Handler: Invalid QRIS sale ID", "error", err, "id_str", idStr)
+		badRequest(w, r, "Invalid QRIS sale ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *PendingSaleHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	h.logger.Error("Handler: QRIS request failed", "error", err)
+	respondError(w, r, err)
+}
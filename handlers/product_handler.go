@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"kasir-api/models"
 	"kasir-api/services"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 )
@@ -27,23 +29,54 @@ func (h *ProductHandler) HandleProducts(w http.ResponseWriter, r *http.Request)
 	case http.MethodPost:
 		h.Create(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r)
 	}
 }
 
 // buat single responsibility di offload ke method lain
+// GetAll - GET /api/produk?category_id=&min_price=&max_price=&in_stock=&sort=price,-name&limit=&cursor=&include_total=
 func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info("Handler: GET all products request")
-	products, err := h.service.GetAll()
+	filter, err := parseProductFilter(r.URL.Query())
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	list, err := h.service.GetAll(filter)
 	if err != nil {
 		h.logger.Error("Handler: Failed to get all products", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondError(w, r, err)
 		return
 	}
 
+	setNextLink(w, r, list.NextCursor)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(products)
-	h.logger.Info("Handler: Successfully returned all products", "count", len(products))
+	json.NewEncoder(w).Encode(productListResponse(r, list))
+	h.logger.Info("Handler: Successfully returned all products", "count", len(list.Data))
+}
+
+func parseProductFilter(q url.Values) (models.ProductFilter, error) {
+	var filter models.ProductFilter
+	var err error
+	if filter.ListParams, err = parseListParams(q); err != nil {
+		return filter, err
+	}
+	if v := q.Get("category_id"); v != "" {
+		if filter.CategoryID, err = strconv.Atoi(v); err != nil {
+			return filter, errors.New("Invalid category_id")
+		}
+	}
+	if filter.MinPrice, err = parseOptionalInt(q, "min_price"); err != nil {
+		return filter, err
+	}
+	if filter.MaxPrice, err = parseOptionalInt(q, "max_price"); err != nil {
+		return filter, err
+	}
+	if filter.InStock, err = parseOptionalBool(q, "in_stock"); err != nil {
+		return filter, err
+	}
+	return filter, nil
 }
 
 // ...existing code...
@@ -52,7 +85,7 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
     var product models.Product
     if err := json.NewDecoder(r.Body).Decode(&product); err != nil {
         h.logger.Error("Failed to decode request body", "error", err)
-        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        badRequest(w, r, "Invalid request body")
         return
     }
 
@@ -61,29 +94,33 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 
     // Validasi input
     if product.Name == "" {
-        http.Error(w, "Product name is required", http.StatusBadRequest)
+        invalid(w, r, "Product name is required")
         return
     }
     if product.Price <= 0 {
-        http.Error(w, "Product price must be greater than 0", http.StatusBadRequest)
+        invalid(w, r, "Product price must be greater than 0")
         return
     }
     if product.Stock < 0 {
-        http.Error(w, "Product stock cannot be negative", http.StatusBadRequest)
+        invalid(w, r, "Product stock cannot be negative")
+        return
+    }
+    if product.CostPrice < 0 {
+        invalid(w, r, "Product cost_price cannot be negative")
+        return
+    }
+    if product.MinStock < 0 || product.ReorderQty < 0 {
+        invalid(w, r, "min_stock and reorder_qty cannot be negative")
         return
     }
     if product.CategoryID <= 0 {
-        http.Error(w, "Valid category_id is required", http.StatusBadRequest)
+        invalid(w, r, "Valid category_id is required")
         return
     }
 
-    err := h.service.Create(&product)
+    err := h.service.Create(&product, auditActor(r))
     if err != nil {
-        if strings.Contains(err.Error(), "violates foreign key constraint") {
-            http.Error(w, "Category ID does not exist", http.StatusBadRequest)
-            return
-        }
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        respondError(w, r, err)
         return
     }
 
@@ -94,6 +131,26 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 
+// / GetLowStock - GET /api/produk/low-stock
+func (h *ProductHandler) GetLowStock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	h.logger.Info("Handler: GET low stock products request")
+	groups, err := h.service.GetLowStock()
+	if err != nil {
+		h.logger.Error("Handler: Failed to get low stock products", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lowStockResponse(r, groups))
+	h.logger.Info("Handler: Successfully returned low stock products", "categories", len(groups))
+}
+
 //MULAI BAGIAN ENDPOINT DENGAN SLUG dengan flow selector handle
 
 // / HandleProductByID - GET/PUT/DELETE /api/produk/{id}
@@ -106,7 +163,7 @@ func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Reques
 	case http.MethodDelete:
 		h.Delete(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r)
 	}
 }
 
@@ -116,7 +173,7 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		h.logger.Error("Handler: Invalid product ID", "error", err, "id_str", idStr)
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		badRequest(w, r, "Invalid product ID")
 		return
 	}
 
@@ -124,12 +181,12 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	product, err := h.service.GetByID(id)
 	if err != nil {
 		h.logger.Error("Handler: Product not found", "error", err, "id", id)
-		http.Error(w, err.Error(), http.StatusNotFound)
+		respondError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(product)
+	json.NewEncoder(w).Encode(productResponse(r, product))
 	h.logger.Info("Handler: Successfully returned product", "id", id)
 }
 
@@ -138,29 +195,33 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		h.logger.Error("Handler: Invalid product ID", "error", err, "id_str", idStr)
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		badRequest(w, r, "Invalid product ID")
 		return
 	}
 
 	h.logger.Info("Handler: PUT update product request", "id", id)
-	var product models.Product
-	err = json.NewDecoder(r.Body).Decode(&product)
+	var update models.ProductUpdate
+	err = json.NewDecoder(r.Body).Decode(&update)
 	if err != nil {
 		h.logger.Error("Handler: Invalid request body", "error", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		badRequest(w, r, "Invalid request body")
+		return
+	}
+	if update.CostPrice != nil && *update.CostPrice < 0 {
+		invalid(w, r, "Product cost_price cannot be negative")
 		return
 	}
 
-	product.ID = id
-	err = h.service.Update(&product)
+	update.ID = id
+	err = h.service.Update(&update, auditActor(r))
 	if err != nil {
 		h.logger.Error("Handler: Failed to update product", "error", err, "id", id)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		respondError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(product)
+	json.NewEncoder(w).Encode(update.Product)
 	h.logger.Info("Handler: Product updated successfully", "id", id)
 }
 
@@ -170,15 +231,15 @@ func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		h.logger.Error("Handler: Invalid product ID", "error", err, "id_str", idStr)
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		badRequest(w, r, "Invalid product ID")
 		return
 	}
 
 	h.logger.Info("Handler: DELETE product request", "id", id)
-	err = h.service.Delete(id)
+	err = h.service.Delete(id, auditActor(r))
 	if err != nil {
 		h.logger.Error("Handler: Failed to delete product", "error", err, "id", id)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondError(w, r, err)
 		return
 	}
 
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type PromotionHandler struct {
+	service *services.PromotionService
+	logger  *slog.Logger
+}
+
+func NewPromotionHandler(service *services.PromotionService, logger *slog.Logger) *PromotionHandler {
+	return &PromotionHandler{service: service, logger: logger}
+}
+
+// / HandlePromotions - GET /api/promotions?active=true, POST /api/promotions
+func (h *PromotionHandler) HandlePromotions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (h *PromotionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: GET all promotions request")
+	promotions, err := h.service.GetAll(r.URL.Query().Get("active") == "true")
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(promotions)
+	h.logger.Info("Handler: Successfully returned promotions", "count", len(promotions))
+}
+
+func (h *PromotionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: POST create promotion request")
+	var promotion models.Promotion
+	if err := json.NewDecoder(r.Body).Decode(&promotion); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	if err := h.service.Create(&promotion, auditActor(r)); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(promotion)
+	h.logger.Info("Handler: Promotion created successfully", "id", promotion.ID)
+}
+
+// / HandlePromotionByID - GET/PUT/DELETE /api/promotions/{id}
+func (h *PromotionHandler) HandlePromotionByID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Handler: Invalid promotion ID", "error", err, "id_str", idStr)
+		badRequest(w, r, "Invalid promotion ID")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r, id)
+	case http.MethodPut:
+		h.Update(w, r, id)
+	case http.MethodDelete:
+		h.Delete(w, r, id)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (h *PromotionHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	h.logger.Info("Handler: GET promotion by ID request", "id", id)
+	promotion, err := h.service.GetByID(id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(promotion)
+}
+
+func (h *PromotionHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	h.logger.Info("Handler: PUT update promotion request", "id", id)
+	var promotion models.Promotion
+	if err := json.NewDecoder(r.Body).Decode(&promotion); err != nil {
+		h.logger.Error("Handler: Invalid request body", "error", err)
+		badRequest(w, r, "Invalid request body")
+		return
+	}
+
+	promotion.ID = id
+	if err := h.service.Update(&promotion, auditActor(r)); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(promotion)
+	h.logger.Info("Handler: Promotion updated successfully", "id", id)
+}
+
+func (h *PromotionHandler) Delete(w http.ResponseWriter, r *http.Request, id int) {
+	h.logger.Info("Handler: DELETE promotion request", "id", id)
+	if err := h.service.Delete(id, auditActor(r)); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Promotion deleted successfully"})
+	h.logger.Info("Handler: Promotion deleted successfully", "id", id)
+}
+
+// / HandleCartPrice - POST /api/cart/price
+// body: {"items": [{"product_id": 1, "quantity": 3}]}, response per baris lengkap sama promo yang kena
+func (h *PromotionHandler) HandleCartPrice(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	h.logger.Info("Handler: POST cart price request")
+	var req models.CartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	price, err := h.service.PriceCart(&req)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(price)
+}
+
+func (h *PromotionHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	h.logger.Error("Handler: Promotion request failed", "error", err)
+	respondError(w, r, err)
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type PurchaseOrderHandler struct {
+	service *services.PurchaseOrderService
+	logger  *slog.Logger
+}
+
+func NewPurchaseOrderHandler(service *services.PurchaseOrderService, logger *slog.Logger) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{service: service, logger: logger}
+}
+
+// / HandlePurchaseOrders - GET/POST /api/purchase-orders
+func (h *PurchaseOrderHandler) HandlePurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+// GetAll - GET /api/purchase-orders?status=&supplier_id=
+func (h *PurchaseOrderHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: GET all purchase orders request")
+	q := r.URL.Query()
+
+	supplierID := 0
+	if v := q.Get("supplier_id"); v != "" {
+		var err error
+		if supplierID, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid supplier_id")
+			return
+		}
+	}
+
+	orders, err := h.service.GetAll(q.Get("status"), supplierID)
+	if err != nil {
+		h.logger.Error("Handler: Failed to get purchase orders", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orders)
+	h.logger.Info("Handler: Successfully returned purchase orders", "count", len(orders))
+}
+
+// Create - POST /api/purchase-orders
+// body: {"supplier_id": 1, "note": "...", "user": "...", "items": [{"product_id": 1, "quantity": 24, "unit_cost": 2800}]}
+func (h *PurchaseOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: POST create purchase order request")
+	var req models.CreatePurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	req.User = currentUsername(r, req.User)
+	order, err := h.service.Create(&req, auditActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(order)
+	h.logger.Info("Handler: Purchase order created", "id", order.ID)
+}
+
+// / HandlePurchaseOrderByID - GET /api/purchase-orders/{id}
+func (h *PurchaseOrderHandler) HandlePurchaseOrderByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	h.logger.Info("Handler: GET purchase order by ID request", "id", id)
+	order, err := h.service.GetByID(id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+	h.logger.Info("Handler: Successfully returned purchase order", "id", id)
+}
+
+// / HandleSend - POST /api/purchase-orders/{id}/send
+func (h *PurchaseOrderHandler) HandleSend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	h.logger.Info("Handler: POST send purchase order request", "id", id)
+	order, err := h.service.MarkSent(id, auditActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+	h.logger.Info("Handler: Purchase order sent", "id", id)
+}
+
+// / HandleReceive - POST /api/purchase-orders/{id}/receive
+// body: {"note": "...", "user": "...", "items": [{"product_id": 1, "quantity": 12, "unit_cost": 2750}]}
+func (h *PurchaseOrderHandler) HandleReceive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ReceiveGoodsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	req.User = currentUsername(r, req.User)
+	h.logger.Info("Handler: POST receive goods request", "id", id, "item_count", len(req.Items))
+	receipt, err := h.service.Receive(id, &req, auditActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(receipt)
+	h.logger.Info("Handler: Goods received", "id", id, "receipt_id", receipt.ID)
+}
+
+func (h *PurchaseOrderHandler) pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Handler: Invalid purchase order ID", "error", err, "id_str", idStr)
+		badRequest(w, r, "Invalid purchase order ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *PurchaseOrderHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	h.logger.Error("Handler: Purchase order request failed", "error", err)
+	respondError(w, r, err)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"kasir-api/internal/receipt"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type ReceiptHandler struct {
+	service *services.ReceiptService
+	logger  *slog.Logger
+}
+
+func NewReceiptHandler(service *services.ReceiptService, logger *slog.Logger) *ReceiptHandler {
+	return &ReceiptHandler{service: service, logger: logger}
+}
+
+// / HandleReceipt - GET /api/transactions/{id}/receipt?format=text|escpos|pdf&paper=58|80
+// default text di kertas 80mm. escpos dikirim mentah ke printer, pdf buat dikirim email
+func (h *ReceiptHandler) HandleReceipt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Handler: Invalid transaction ID", "error", err, "id_str", idStr)
+		badRequest(w, r, "Invalid transaction ID")
+		return
+	}
+
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = receipt.FormatText
+	}
+	paper := receipt.Paper80
+	if v := q.Get("paper"); v != "" {
+		if paper, err = strconv.Atoi(v); err != nil {
+			respondError(w, r, receipt.ErrUnknownPaper)
+			return
+		}
+	}
+
+	h.logger.Info("Handler: GET receipt request", "id", id, "format", format, "paper", paper)
+	body, contentType, err := h.service.Render(id, format, paper)
+	if err != nil {
+		h.logger.Error("Handler: Failed to render receipt", "error", err, "id", id)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	switch format {
+	case receipt.FormatPDF:
+		w.Header().Set("Content-Disposition", "inline; filename=\"struk-"+strconv.Itoa(id)+".pdf\"")
+	case receipt.FormatESCPOS:
+		w.Header().Set("Content-Disposition", "attachment; filename=\"struk-"+strconv.Itoa(id)+".bin\"")
+	}
+	w.Write(body)
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type ReportHandler struct {
+	service *services.ReportService
+	logger  *slog.Logger
+}
+
+func NewReportHandler(service *services.ReportService, logger *slog.Logger) *ReportHandler {
+	return &ReportHandler{service: service, logger: logger}
+}
+
+// / HandleSalesReport - GET /api/reports/sales?from=2025-01-01&to=2025-01-31&group_by=day|week|month
+// from dan to format YYYY-MM-DD di timezone bisnis, dua-duanya inklusif
+func (h *ReportHandler) HandleSalesReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	h.logger.Info("Handler: GET sales report request")
+	q := r.URL.Query()
+	loc := h.service.Location()
+
+	from, to, err := parseDateRange(q.Get("from"), q.Get("to"), loc)
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	report, err := h.service.SalesReport(from, to, q.Get("group_by"))
+	if err != nil {
+		h.logger.Error("Handler: Failed to get sales report", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+	h.logger.Info("Handler: Successfully returned sales report", "buckets", len(report.Buckets))
+}
+
+// / HandleTaxReport - GET /api/reports/tax?from=2025-01-01&to=2025-01-31&group_by=day|week|month
+// rekap DPP, PPN dan penjualan bebas PPN per periode, dikurangin refund
+func (h *ReportHandler) HandleTaxReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	h.logger.Info("Handler: GET tax report request")
+	q := r.URL.Query()
+
+	from, to, err := parseDateRange(q.Get("from"), q.Get("to"), h.service.Location())
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	report, err := h.service.TaxReport(from, to, q.Get("group_by"))
+	if err != nil {
+		h.logger.Error("Handler: Failed to get tax report", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+	h.logger.Info("Handler: Successfully returned tax report", "buckets", len(report.Buckets))
+}
+
+// / HandleTopProducts - GET /api/reports/products/top?from=&to=&sort=quantity|revenue&limit=&category_id=&by_category=true
+func (h *ReportHandler) HandleTopProducts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	h.logger.Info("Handler: GET top products request")
+	q := r.URL.Query()
+	filter, err := parseProductSalesFilter(q)
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+	filter.From, filter.To, err = parseDateRange(q.Get("from"), q.Get("to"), h.service.Location())
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	report, err := h.service.TopProducts(filter)
+	if err != nil {
+		h.logger.Error("Handler: Failed to get top products", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+	h.logger.Info("Handler: Successfully returned top products", "count", len(report.Products))
+}
+
+// / HandleSlowProducts - GET /api/reports/products/slow?days=30&max_quantity=0&sort=&limit=&category_id=&by_category=true
+// produk yang masih ada stok tapi ga (atau hampir ga) laku selama `days` hari terakhir
+func (h *ReportHandler) HandleSlowProducts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	h.logger.Info("Handler: GET slow products request")
+	q := r.URL.Query()
+	filter, err := parseProductSalesFilter(q)
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	days := 0
+	if v := q.Get("days"); v != "" {
+		if days, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid days")
+			return
+		}
+	}
+	if v := q.Get("max_quantity"); v != "" {
+		if filter.MaxQuantity, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid max_quantity")
+			return
+		}
+	}
+
+	report, err := h.service.SlowProducts(days, filter)
+	if err != nil {
+		h.logger.Error("Handler: Failed to get slow products", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+	h.logger.Info("Handler: Successfully returned slow products", "count", len(report.Products))
+}
+
+// / HandleMarginReport - GET /api/reports/margin?from=&to=&group_by=product|category|day
+func (h *ReportHandler) HandleMarginReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	h.logger.Info("Handler: GET margin report request")
+	q := r.URL.Query()
+	from, to, err := parseDateRange(q.Get("from"), q.Get("to"), h.service.Location())
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	report, err := h.service.MarginReport(from, to, q.Get("group_by"))
+	if err != nil {
+		h.logger.Error("Handler: Failed to get margin report", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+	h.logger.Info("Handler: Successfully returned margin report", "rows", len(report.Rows))
+}
+
+// / HandlePaymentReport - GET /api/reports/payments?from=&to=
+func (h *ReportHandler) HandlePaymentReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	h.logger.Info("Handler: GET payment report request")
+	q := r.URL.Query()
+	from, to, err := parseDateRange(q.Get("from"), q.Get("to"), h.service.Location())
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	report, err := h.service.PaymentReport(from, to)
+	if err != nil {
+		h.logger.Error("Handler: Failed to get payment report", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+	h.logger.Info("Handler: Successfully returned payment report", "methods", len(report.Methods))
+}
+
+// parseProductSalesFilter - query param yang sama antara top & slow
+func parseProductSalesFilter(q url.Values) (models.ProductSalesFilter, error) {
+	filter := models.ProductSalesFilter{Sort: q.Get("sort")}
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return filter, errors.New("Invalid limit")
+		}
+	}
+	if v := q.Get("category_id"); v != "" {
+		if filter.CategoryID, err = strconv.Atoi(v); err != nil {
+			return filter, errors.New("Invalid category_id")
+		}
+	}
+	if v := q.Get("by_category"); v != "" {
+		if filter.ByCategory, err = strconv.ParseBool(v); err != nil {
+			return filter, errors.New("Invalid by_category")
+		}
+	}
+	return filter, nil
+}
+
+// parseDateRange - from & to format YYYY-MM-DD di timezone bisnis, dua-duanya inklusif.
+// To yang di-return udah eksklusif (hari berikutnya jam 00:00)
+func parseDateRange(fromStr, toStr string, loc *time.Location) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+	if fromStr != "" {
+		from, err = time.ParseInLocation("2006-01-02", fromStr, loc)
+		if err != nil {
+			return from, to, errors.New("Invalid from date, use YYYY-MM-DD")
+		}
+	}
+	if toStr != "" {
+		to, err = time.ParseInLocation("2006-01-02", toStr, loc)
+		if err != nil {
+			return from, to, errors.New("Invalid to date, use YYYY-MM-DD")
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type ShiftHandler struct {
+	service *services.ShiftService
+	logger  *slog.Logger
+}
+
+func NewShiftHandler(service *services.ShiftService, logger *slog.Logger) *ShiftHandler {
+	return &ShiftHandler{service: service, logger: logger}
+}
+
+// / HandleShifts - GET /api/shifts?status=&user_id=, POST /api/shifts (buka shift)
+func (h *ShiftHandler) HandleShifts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Open(w, r)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (h *ShiftHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: GET all shifts request")
+	q := r.URL.Query()
+	filter := models.ShiftFilter{Status: q.Get("status")}
+	if v := q.Get("user_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			badRequest(w, r, "Invalid user_id")
+			return
+		}
+		filter.UserID = id
+	}
+
+	shifts, err := h.service.GetAll(filter)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shifts)
+	h.logger.Info("Handler: Successfully returned shifts", "count", len(shifts))
+}
+
+// Open - POST /api/shifts
+// body: {"opening_float": 200000, "note": "..."}
+func (h *ShiftHandler) Open(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: POST open shift request")
+	var req models.OpenShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	shift, err := h.service.Open(&req, currentStaff(r), auditActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shift)
+	h.logger.Info("Handler: Shift opened", "id", shift.ID)
+}
+
+// / HandleCurrent - GET /api/shifts/current, shift yang lagi buka punya user yang login
+func (h *ShiftHandler) HandleCurrent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	shift, err := h.service.Current(currentStaff(r))
+	if errors.Is(err, repositories.ErrNoOpenShift) {
+		// di checkout ini conflict, tapi buat GET /current artinya resource-nya emang ga ada
+		apperror.Write(w, r, http.StatusNotFound, apperror.Body{Code: "not_found", Message: err.Error()})
+		return
+	}
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+}
+
+// / HandleShiftByID - GET /api/shifts/{id}
+func (h *ShiftHandler) HandleShiftByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	h.logger.Info("Handler: GET shift by ID request", "id", id)
+	shift, err := h.service.GetByID(id, currentStaff(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+}
+
+// / HandleCashMovement - POST /api/shifts/{id}/cash
+// body: {"type": "out", "amount": 15000, "reason": "beli es batu"}
+func (h *ShiftHandler) HandleCashMovement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CashMovementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	h.logger.Info("Handler: POST cash movement request", "shift_id", id, "type", req.Type)
+	movement, err := h.service.AddCashMovement(id, &req, currentStaff(r), auditActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+}
+
+// / HandleClose - POST /api/shifts/{id}/close
+// body: {"counted_cash": 1250000, "note": "..."}
+func (h *ShiftHandler) HandleClose(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CloseShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	h.logger.Info("Handler: POST close shift request", "shift_id", id)
+	shift, err := h.service.Close(id, &req, currentStaff(r), auditActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+	h.logger.Info("Handler: Shift closed", "id", id)
+}
+
+func (h *ShiftHandler) pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Handler: Invalid shift ID", "error", err, "id_str", idStr)
+		badRequest(w, r, "Invalid shift ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *ShiftHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	h.logger.Error("Handler: Shift request failed", "error", err)
+	respondError(w, r, err)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type StockHandler struct {
+	service *services.StockService
+	logger  *slog.Logger
+}
+
+func NewStockHandler(service *services.StockService, logger *slog.Logger) *StockHandler {
+	return &StockHandler{service: service, logger: logger}
+}
+
+// / HandleStockHistory - GET /api/produk/{id}/stock-history?page=&limit=
+func (h *StockHandler) HandleStockHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Handler: Invalid product ID", "error", err, "id_str", idStr)
+		badRequest(w, r, "Invalid product ID")
+		return
+	}
+
+	q := r.URL.Query()
+	page, limit := 0, 0
+	if v := q.Get("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid page")
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid limit")
+			return
+		}
+	}
+
+	h.logger.Info("Handler: GET stock history request", "product_id", id)
+	history, err := h.service.GetHistory(id, page, limit)
+	if err != nil {
+		h.logger.Error("Handler: Failed to get stock history", "error", err, "product_id", id)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+	h.logger.Info("Handler: Successfully returned stock history", "product_id", id, "count", len(history.Movements))
+}
+
+// / HandleStockAdjustment - POST /api/produk/{id}/stock
+// body: {"reason": "restock|adjustment", "quantity": 10, "note": "...", "user": "..."}
+func (h *StockHandler) HandleStockAdjustment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Handler: Invalid product ID", "error", err, "id_str", idStr)
+		badRequest(w, r, "Invalid product ID")
+		return
+	}
+
+	var req models.StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	req.User = currentUsername(r, req.User)
+	h.logger.Info("Handler: POST stock adjustment request", "product_id", id, "reason", req.Reason)
+	movement, err := h.service.Adjust(id, &req, auditActor(r))
+	if err != nil {
+		h.logger.Error("Handler: Failed to adjust stock", "error", err, "product_id", id)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+	h.logger.Info("Handler: Stock adjusted", "product_id", id, "stock_after", movement.StockAfter)
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type StockOpnameHandler struct {
+	service *services.StockOpnameService
+	logger  *slog.Logger
+}
+
+func NewStockOpnameHandler(service *services.StockOpnameService, logger *slog.Logger) *StockOpnameHandler {
+	return &StockOpnameHandler{service: service, logger: logger}
+}
+
+// / HandleStockOpnames - GET/POST /api/stock-opname
+func (h *StockOpnameHandler) HandleStockOpnames(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+// GetAll - GET /api/stock-opname?status=open|posted|cancelled
+func (h *StockOpnameHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: GET all stock opnames request")
+	opnames, err := h.service.GetAll(r.URL.Query().Get("status"))
+	if err != nil {
+		h.logger.Error("Handler: Failed to get stock opnames", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(opnames)
+	h.logger.Info("Handler: Successfully returned stock opnames", "count", len(opnames))
+}
+
+// Create - POST /api/stock-opname
+// body: {"category_id": 2, "lock_sales": true, "note": "...", "user": "..."}, lock_sales cuma buat owner
+func (h *StockOpnameHandler) Create(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: POST create stock opname request")
+	var req models.CreateStockOpnameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	req.User = currentUsername(r, req.User)
+	opname, err := h.service.Create(&req, currentStaff(r), auditActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(opname)
+	h.logger.Info("Handler: Stock opname created", "id", opname.ID)
+}
+
+// / HandleStockOpnameByID - GET /api/stock-opname/{id}
+func (h *StockOpnameHandler) HandleStockOpnameByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	h.logger.Info("Handler: GET stock opname by ID request", "id", id)
+	opname, err := h.service.GetByID(id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(opname)
+	h.logger.Info("Handler: Successfully returned stock opname", "id", id)
+}
+
+// / HandleCounts - POST /api/stock-opname/{id}/counts
+// body: {"user": "...", "items": [{"product_id": 1, "counted_qty": 48}]}
+func (h *StockOpnameHandler) HandleCounts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SubmitStockOpnameCountsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	req.User = currentUsername(r, req.User)
+	h.logger.Info("Handler: POST stock opname counts request", "id", id, "count", len(req.Items))
+	opname, err := h.service.SubmitCounts(id, &req, auditActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(opname)
+	h.logger.Info("Handler: Stock opname counts submitted", "id", id)
+}
+
+// / HandlePost - POST /api/stock-opname/{id}/post, body opsional: {"user": "..."}
+func (h *StockOpnameHandler) HandlePost(w http.ResponseWriter, r *http.Request) {
+	h.handleClose(w, r, h.service.Post)
+}
+
+// / HandleCancel - POST /api/stock-opname/{id}/cancel, body opsional: {"user": "..."}
+func (h *StockOpnameHandler) HandleCancel(w http.ResponseWriter, r *http.Request) {
+	h.handleClose(w, r, h.service.Cancel)
+}
+
+func (h *StockOpnameHandler) handleClose(w http.ResponseWriter, r *http.Request, action func(int, string, models.AuditActor) (*models.StockOpname, error)) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		User string `json:"user"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	h.logger.Info("Handler: Closing stock opname", "id", id, "path", r.URL.Path)
+	opname, err := action(id, currentUsername(r, req.User), auditActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(opname)
+	h.logger.Info("Handler: Stock opname closed", "id", id, "status", opname.Status)
+}
+
+func (h *StockOpnameHandler) pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Handler: Invalid stock opname ID", "error", err, "id_str", idStr)
+		badRequest(w, r, "Invalid stock opname ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *StockOpnameHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	h.logger.Error("Handler: Stock opname request failed", "error", err)
+	respondError(w, r, err)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type SupplierHandler struct {
+	service *services.SupplierService
+	logger  *slog.Logger
+}
+
+func NewSupplierHandler(service *services.SupplierService, logger *slog.Logger) *SupplierHandler {
+	return &SupplierHandler{service: service, logger: logger}
+}
+
+// / HandleSuppliers - GET/POST /api/suppliers
+func (h *SupplierHandler) HandleSuppliers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (h *SupplierHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: GET all suppliers request")
+	suppliers, err := h.service.GetAll()
+	if err != nil {
+		h.logger.Error("Handler: Failed to get all suppliers", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suppliers)
+	h.logger.Info("Handler: Successfully returned all suppliers", "count", len(suppliers))
+}
+
+func (h *SupplierHandler) Create(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: POST create supplier request")
+	var supplier models.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&supplier); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	if err := h.service.Create(&supplier, auditActor(r)); err != nil {
+		h.logger.Error("Handler: Failed to create supplier", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(supplier)
+	h.logger.Info("Handler: Supplier created successfully", "id", supplier.ID)
+}
+
+// / HandleSupplierByID - GET/PUT /api/suppliers/{id}
+func (h *SupplierHandler) HandleSupplierByID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Handler: Invalid supplier ID", "error", err, "id_str", idStr)
+		badRequest(w, r, "Invalid supplier ID")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r, id)
+	case http.MethodPut:
+		h.Update(w, r, id)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (h *SupplierHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	h.logger.Info("Handler: GET supplier by ID request", "id", id)
+	supplier, err := h.service.GetByID(id)
+	if err != nil {
+		h.logger.Error("Handler: Failed to get supplier", "error", err, "id", id)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(supplier)
+	h.logger.Info("Handler: Successfully returned supplier", "id", id)
+}
+
+func (h *SupplierHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	h.logger.Info("Handler: PUT update supplier request", "id", id)
+	var supplier models.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&supplier); err != nil {
+		h.logger.Error("Handler: Invalid request body", "error", err)
+		badRequest(w, r, "Invalid request body")
+		return
+	}
+
+	supplier.ID = id
+	if err := h.service.Update(&supplier, auditActor(r)); err != nil {
+		h.logger.Error("Handler: Failed to update supplier", "error", err, "id", id)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(supplier)
+	h.logger.Info("Handler: Supplier updated successfully", "id", id)
+}
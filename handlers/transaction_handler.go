@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TransactionHandler struct {
+	service *services.TransactionService
+	logger  *slog.Logger
+}
+
+func NewTransactionHandler(service *services.TransactionService, logger *slog.Logger) *TransactionHandler {
+	return &TransactionHandler{service: service, logger: logger}
+}
+
+// / HandleTransactions - GET/POST /api/transactions
+func (h *TransactionHandler) HandleTransactions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Checkout(w, r)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: POST checkout request")
+	var req models.CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	req.Cashier = currentUsername(r, req.Cashier)
+	req.UserID = currentStaff(r).UserID
+	transaction, err := h.service.Checkout(&req)
+	if err != nil {
+		h.logger.Error("Handler: Checkout failed", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transactionResponse(r, transaction))
+	h.logger.Info("Handler: Checkout successful", "id", transaction.ID, "total_amount", transaction.TotalAmount)
+}
+
+// GetAll - GET /api/transactions?from=2025-01-01&to=2025-01-31&cashier=&payment_method=&customer_id=&page=&limit=
+// from dan to format YYYY-MM-DD, dua-duanya inklusif
+func (h *TransactionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: GET all transactions request")
+	q := r.URL.Query()
+
+	var filter models.TransactionFilter
+	var err error
+	if v := q.Get("from"); v != "" {
+		filter.From, err = time.Parse("2006-01-02", v)
+		if err != nil {
+			badRequest(w, r, "Invalid from date, use YYYY-MM-DD")
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			badRequest(w, r, "Invalid to date, use YYYY-MM-DD")
+			return
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+	if v := q.Get("page"); v != "" {
+		if filter.Page, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid page")
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid limit")
+			return
+		}
+	}
+	if v := q.Get("customer_id"); v != "" {
+		if filter.CustomerID, err = strconv.Atoi(v); err != nil {
+			badRequest(w, r, "Invalid customer_id")
+			return
+		}
+	}
+	filter.Cashier = q.Get("cashier")
+	filter.PaymentMethod = q.Get("payment_method")
+
+	list, err := h.service.GetAll(filter)
+	if err != nil {
+		h.logger.Error("Handler: Failed to get transactions", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+	h.logger.Info("Handler: Successfully returned transactions", "count", len(list.Data))
+}
+
+// / HandleTransactionByID - GET /api/transactions/{id}, POST /api/transactions/{id}/refund
+func (h *TransactionHandler) HandleTransactionByID(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/refund") {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, r)
+			return
+		}
+		h.Refund(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (h *TransactionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/transactions/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Handler: Invalid transaction ID", "error", err, "id_str", idStr)
+		badRequest(w, r, "Invalid transaction ID")
+		return
+	}
+
+	h.logger.Info("Handler: GET transaction by ID request", "id", id)
+	transaction, err := h.service.GetByID(id)
+	if err != nil {
+		h.logger.Error("Handler: Failed to get transaction", "error", err, "id", id)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transactionResponse(r, transaction))
+	h.logger.Info("Handler: Successfully returned transaction", "id", id)
+}
+
+// Refund - POST /api/transactions/{id}/refund
+// body: {"reason": "...", "items": [{"product_id": 1, "quantity": 1}]}, items kosong = refund full
+func (h *TransactionHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/transactions/"), "/refund")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Handler: Invalid transaction ID", "error", err, "id_str", idStr)
+		badRequest(w, r, "Invalid transaction ID")
+		return
+	}
+
+	h.logger.Info("Handler: POST refund request", "transaction_id", id)
+	var req models.RefundRequest
+	// body kosong boleh, artinya refund full
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	staff := currentStaff(r)
+	req.UserID, req.User = staff.UserID, staff.Username
+	refund, err := h.service.Refund(id, &req, auditActor(r))
+	if err != nil {
+		h.logger.Error("Handler: Refund failed", "error", err, "transaction_id", id)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(refund)
+	h.logger.Info("Handler: Refund successful", "id", refund.ID, "transaction_id", id)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+)
+
+type UserHandler struct {
+	service *services.UserService
+	logger  *slog.Logger
+}
+
+func NewUserHandler(service *services.UserService, logger *slog.Logger) *UserHandler {
+	return &UserHandler{service: service, logger: logger}
+}
+
+// / HandleUsers - GET/POST /api/users
+func (h *UserHandler) HandleUsers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (h *UserHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: GET all users request")
+	users, err := h.service.GetAll()
+	if err != nil {
+		h.logger.Error("Handler: Failed to get all users", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+	h.logger.Info("Handler: Successfully returned all users", "count", len(users))
+}
+
+func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Handler: POST create user request")
+	var req models.CreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Handler: Invalid request payload", "error", err)
+		badRequest(w, r, "Invalid request payload")
+		return
+	}
+
+	user, err := h.service.Create(&req, auditActor(r))
+	if err != nil {
+		h.logger.Error("Handler: Failed to create user", "error", err)
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+	h.logger.Info("Handler: User created successfully", "id", user.ID)
+}
@@ -0,0 +1,86 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"kasir-api/models"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Notifier - penerima event low stock. Dipanggil setelah SQL transaction commit,
+// jadi implementasinya ga boleh blocking lama
+type Notifier interface {
+	NotifyLowStock(alert models.LowStockAlert)
+}
+
+// LogNotifier - default, cuma nulis ke log
+type LogNotifier struct {
+	logger *slog.Logger
+}
+
+func NewLogNotifier(logger *slog.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) NotifyLowStock(alert models.LowStockAlert) {
+	n.logger.Warn("Low stock alert",
+		"product_id", alert.ProductID,
+		"product_name", alert.ProductName,
+		"stock", alert.Stock,
+		"min_stock", alert.MinStock,
+		"reorder_qty", alert.ReorderQty,
+		"reason", alert.Reason,
+	)
+}
+
+// WebhookNotifier - POST JSON alert ke URL, jalan di goroutine biar request ga nunggu
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+	logger *slog.Logger
+}
+
+func NewWebhookNotifier(url string, logger *slog.Logger) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+		logger: logger,
+	}
+}
+
+func (n *WebhookNotifier) NotifyLowStock(alert models.LowStockAlert) {
+	go func() {
+		body, err := json.Marshal(map[string]interface{}{
+			"event": "low_stock",
+			"data":  alert,
+		})
+		if err != nil {
+			n.logger.Error("Failed to encode low stock webhook", "error", err)
+			return
+		}
+
+		resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			n.logger.Error("Failed to send low stock webhook", "error", err, "product_id", alert.ProductID)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			n.logger.Error("Low stock webhook rejected", "status", resp.StatusCode, "product_id", alert.ProductID)
+			return
+		}
+		n.logger.Info("Low stock webhook sent", "product_id", alert.ProductID)
+	}()
+}
+
+// Multi - kirim ke beberapa notifier sekaligus
+type Multi []Notifier
+
+func (m Multi) NotifyLowStock(alert models.LowStockAlert) {
+	for _, n := range m {
+		n.NotifyLowStock(alert)
+	}
+}
@@ -0,0 +1,123 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/internal/requestid"
+	"net/http"
+
+	"github.com/lib/pq"
+)
+
+// Jenis error domain. Error spesifik (misal repositories.ErrProductNotFound) dibikin
+// lewat New dengan salah satu jenis ini, jadi handler cukup cek jenisnya
+// pake errors.Is buat nentuin HTTP status
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrForeignKey   = errors.New("foreign key violation")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+)
+
+// Error - error domain: Message yang aman ditampilin ke client, Kind salah satu jenis di atas.
+// Details opsional (misal nama constraint), Err penyebab aslinya kalau ada
+type Error struct {
+	Kind    error
+	Message string
+	Details map[string]any
+	Err     error
+}
+
+func New(kind error, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// Kode SQLSTATE Postgres yang diterjemahin FromDB
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+	pqNotNullViolation    = "23502"
+	pqCheckViolation      = "23514"
+	pqStringTooLong       = "22001"
+	pqNumericOutOfRange   = "22003"
+	pqInvalidText         = "22P02"
+)
+
+// FromDB - terjemahin *pq.Error ke error domain berdasarkan kode SQLSTATE-nya.
+// Error lain (atau kode yang ga dikenal) dibalikin apa adanya
+func FromDB(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	var kind error
+	var message string
+	switch pqErr.Code {
+	case pqUniqueViolation:
+		kind, message = ErrConflict, "data sudah ada"
+	case pqForeignKeyViolation:
+		kind, message = ErrForeignKey, "data masih direferensikan atau referensinya tidak ditemukan"
+	case pqNotNullViolation, pqCheckViolation, pqStringTooLong, pqNumericOutOfRange, pqInvalidText:
+		kind, message = ErrValidation, "data tidak valid"
+	default:
+		return err
+	}
+
+	details := map[string]any{}
+	if pqErr.Constraint != "" {
+		details["constraint"] = pqErr.Constraint
+	}
+	if pqErr.Column != "" {
+		details["column"] = pqErr.Column
+	}
+	if pqErr.Table != "" {
+		details["table"] = pqErr.Table
+	}
+	return &Error{Kind: kind, Message: message, Details: details, Err: err}
+}
+
+// IsCode - true kalau err itu *pq.Error dengan kode SQLSTATE tertentu
+func IsCode(err error, code string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == code
+}
+
+// IsUniqueViolation / IsForeignKeyViolation - buat repo yang mau ganti error DB
+// dengan error domain yang lebih spesifik
+func IsUniqueViolation(err error) bool {
+	return IsCode(err, pqUniqueViolation)
+}
+
+func IsForeignKeyViolation(err error) bool {
+	return IsCode(err, pqForeignKeyViolation)
+}
+
+// Body - envelope JSON error, sama di semua endpoint
+type Body struct {
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details"`
+	RequestID string         `json:"request_id"`
+}
+
+// Write - tulis envelope error, request_id diambil dari context (requestid.Middleware)
+func Write(w http.ResponseWriter, r *http.Request, status int, body Body) {
+	body.RequestID = requestid.FromContext(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
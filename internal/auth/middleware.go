@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"kasir-api/internal/apperror"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type contextKey struct{}
+
+// WithClaims / ClaimsFromContext - handler baca user yang lagi login dari context
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok
+}
+
+// Middleware - wajibin header "Authorization: Bearer <access token>" di semua path,
+// kecuali yang ada di publicPaths (dicocokin persis)
+func Middleware(tm *TokenManager, publicPaths []string, logger *slog.Logger) func(http.Handler) http.Handler {
+	public := make(map[string]bool, len(publicPaths))
+	for _, p := range publicPaths {
+		public[p] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if public[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := r.Header.Get("Authorization")
+			token, found := strings.CutPrefix(header, "Bearer ")
+			if !found || token == "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				apperror.Write(w, r, http.StatusUnauthorized, apperror.Body{Code: "unauthorized", Message: "Missing bearer token"})
+				return
+			}
+
+			claims, err := tm.Parse(token)
+			if err != nil {
+				logger.Warn("Rejected access token", "error", err, "path", r.URL.Path)
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				if errors.Is(err, ErrExpiredToken) {
+					apperror.Write(w, r, http.StatusUnauthorized, apperror.Body{Code: "token_expired", Message: "Token expired"})
+					return
+				}
+				apperror.Write(w, r, http.StatusUnauthorized, apperror.Body{Code: "unauthorized", Message: "Invalid token"})
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), claims)))
+		})
+	}
+}
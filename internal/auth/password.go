@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Format hash: pbkdf2_sha256$<iterasi>$<salt base64>$<hash base64>.
+// Iterasi disimpen di hash biar bisa dinaikin nanti tanpa ngerusak password lama
+const (
+	passwordScheme     = "pbkdf2_sha256"
+	passwordIterations = 600000
+	passwordSaltLen    = 16
+	passwordKeyLen     = 32
+)
+
+var ErrInvalidPasswordHash = errors.New("format password hash tidak dikenali")
+
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, passwordKeyLen)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s$%d$%s$%s", passwordScheme, passwordIterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// CheckPassword - bandingin pake constant time biar ga bocor lewat timing
+func CheckPassword(hash, password string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != passwordScheme {
+		return false, ErrInvalidPasswordHash
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false, ErrInvalidPasswordHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false, ErrInvalidPasswordHash
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false, ErrInvalidPasswordHash
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(expected))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(key, expected) == 1, nil
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPasswordRoundTrip(t *testing.T) {
+	hash, err := HashPassword("rahasia123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, passwordScheme+"$600000$") {
+		t.Fatalf("unexpected hash format %q", hash)
+	}
+
+	ok, err := CheckPassword(hash, "rahasia123")
+	if err != nil || !ok {
+		t.Fatalf("CheckPassword(correct) = %v, %v", ok, err)
+	}
+	ok, err = CheckPassword(hash, "rahasia124")
+	if err != nil || ok {
+		t.Fatalf("CheckPassword(wrong) = %v, %v", ok, err)
+	}
+
+	// salt random, jadi password sama ga boleh ngasilin hash yang sama
+	again, err := HashPassword("rahasia123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == hash {
+		t.Fatal("two hashes of the same password are identical")
+	}
+}
+
+func TestCheckPasswordInvalidHash(t *testing.T) {
+	for _, hash := range []string{
+		"",
+		"plaintext",
+		"bcrypt$10$c2FsdA$a2V5",
+		"pbkdf2_sha256$abc$c2FsdA$a2V5",
+		"pbkdf2_sha256$0$c2FsdA$a2V5",
+		"pbkdf2_sha256$1$!!!$a2V5",
+		"pbkdf2_sha256$1$c2FsdA$!!!",
+	} {
+		if _, err := CheckPassword(hash, "x"); !errors.Is(err, ErrInvalidPasswordHash) {
+			t.Fatalf("CheckPassword(%q) err = %v, want ErrInvalidPasswordHash", hash, err)
+		}
+	}
+}
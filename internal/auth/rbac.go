@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"log/slog"
+	"net/http"
+)
+
+// roleRank - role yang lebih tinggi otomatis dapet semua akses role di bawahnya
+var roleRank = map[string]int{
+	models.RoleCashier:    1,
+	models.RoleSupervisor: 2,
+	models.RoleOwner:      3,
+}
+
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// HasRole - true kalau role minimal setara required
+func HasRole(role, required string) bool {
+	return roleRank[role] > 0 && roleRank[role] >= roleRank[required]
+}
+
+// Permission - satu baris tabel permission. Pattern pake syntax ServeMux
+// ("METHOD /path/{id}"), Role itu role minimal yang boleh akses
+type Permission struct {
+	Pattern string
+	Role    string
+}
+
+// Authorize - cek role dari claims terhadap tabel permission. Request tanpa claims
+// (public path) langsung lewat. Route yang ga ada di tabel cuma boleh owner,
+// jadi handler baru yang lupa didaftarin ga kebuka ke semua orang
+func Authorize(permissions []Permission, logger *slog.Logger) func(http.Handler) http.Handler {
+	// ServeMux dipake cuma buat matching pattern, handler-nya ga pernah dipanggil
+	matcher := http.NewServeMux()
+	required := make(map[string]string, len(permissions))
+	for _, p := range permissions {
+		if !ValidRole(p.Role) {
+			panic("auth: unknown role " + p.Role + " for " + p.Pattern)
+		}
+		matcher.Handle(p.Pattern, http.NotFoundHandler())
+		required[p.Pattern] = p.Role
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			role := models.RoleOwner
+			if _, pattern := matcher.Handler(r); pattern != "" {
+				role = required[pattern]
+			}
+
+			if !HasRole(claims.Role, role) {
+				logger.Warn("Forbidden request", "user_id", claims.UserID, "role", claims.Role,
+					"required_role", role, "method", r.Method, "path", r.URL.Path)
+				apperror.Write(w, r, http.StatusForbidden, apperror.Body{
+					Code:    "forbidden",
+					Message: "role " + claims.Role + " tidak punya akses ke endpoint ini",
+					Details: map[string]any{"required_role": role},
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("token tidak valid")
+	ErrExpiredToken = errors.New("token sudah kadaluarsa")
+)
+
+// Claims - isi access token (JWT HS256)
+type Claims struct {
+	UserID    int    `json:"sub"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// TokenManager - sign & verifikasi access token pake secret dari JWT_SECRET
+type TokenManager struct {
+	secret    []byte
+	accessTTL time.Duration
+}
+
+func NewTokenManager(secret string, accessTTL time.Duration) *TokenManager {
+	return &TokenManager{secret: []byte(secret), accessTTL: accessTTL}
+}
+
+func (tm *TokenManager) AccessTTL() time.Duration {
+	return tm.accessTTL
+}
+
+// jwtHeader - header selalu sama, jadi pas verifikasi cukup dibandingin persis.
+// Ini sekalian nolak token dengan alg lain (misal "none")
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+func (tm *TokenManager) Sign(claims Claims) (string, error) {
+	now := time.Now()
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(tm.accessTTL).Unix()
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + tm.signature(unsigned), nil
+}
+
+func (tm *TokenManager) Parse(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, ErrInvalidToken
+	}
+
+	expected := tm.signature(parts[0] + "." + parts[1])
+	if !hmac.Equal([]byte(parts[2]), []byte(expected)) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+	return &claims, nil
+}
+
+func (tm *TokenManager) signature(unsigned string) string {
+	mac := hmac.New(sha256.New, tm.secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// NewRefreshToken - token random (opaque). Yang disimpen di DB cuma hash-nya
+func NewRefreshToken() (token string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashRefreshToken(token), nil
+}
+
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
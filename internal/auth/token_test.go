@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestTokenRoundTrip(t *testing.T) {
+	tm := NewTokenManager(testSecret, time.Minute)
+	token, err := tm.Sign(Claims{UserID: 7, Username: "budi", Role: "cashier"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := tm.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if claims.UserID != 7 || claims.Username != "budi" || claims.Role != "cashier" {
+		t.Fatalf("claims = %+v", claims)
+	}
+	if claims.ExpiresAt-claims.IssuedAt != 60 {
+		t.Fatalf("ttl = %ds, want 60s", claims.ExpiresAt-claims.IssuedAt)
+	}
+}
+
+func TestTokenRejected(t *testing.T) {
+	tm := NewTokenManager(testSecret, time.Minute)
+	token, err := tm.Sign(Claims{UserID: 7, Username: "budi", Role: "cashier"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+	encode := base64.RawURLEncoding.EncodeToString
+
+	// payload diganti jadi owner tapi signature-nya tetap yang lama
+	escalated := encode([]byte(`{"sub":7,"username":"budi","role":"owner","iat":0,"exp":9999999999}`))
+
+	// header HS512 yang di-sign bener pake secret yang sama tetap harus ditolak
+	hs512Header := encode([]byte(`{"alg":"HS512","typ":"JWT"}`))
+	mac := hmac.New(sha512.New, []byte(testSecret))
+	mac.Write([]byte(hs512Header + "." + parts[1]))
+	hs512 := hs512Header + "." + parts[1] + "." + encode(mac.Sum(nil))
+
+	expired, err := NewTokenManager(testSecret, -time.Minute).Sign(Claims{UserID: 7, Role: "cashier"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSecret, err := NewTokenManager(strings.Repeat("x", 32), time.Minute).Sign(Claims{UserID: 7, Role: "cashier"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstChar := "A"
+	if strings.HasPrefix(parts[2], "A") {
+		firstChar = "B"
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{name: "tampered signature", token: parts[0] + "." + parts[1] + "." + firstChar + parts[2][1:], want: ErrInvalidToken},
+		{name: "tampered payload", token: parts[0] + "." + escalated + "." + parts[2], want: ErrInvalidToken},
+		{name: "missing signature", token: parts[0] + "." + parts[1] + ".", want: ErrInvalidToken},
+		{name: "alg none", token: encode([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + parts[1] + ".", want: ErrInvalidToken},
+		{name: "alg HS512", token: hs512, want: ErrInvalidToken},
+		{name: "signed with another secret", token: otherSecret, want: ErrInvalidToken},
+		{name: "expired", token: expired, want: ErrExpiredToken},
+		{name: "not a jwt", token: "abc", want: ErrInvalidToken},
+		{name: "empty", token: "", want: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tm.Parse(tt.token)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Parse err = %v, want %v", err, tt.want)
+			}
+			if claims != nil {
+				t.Fatalf("Parse returned claims %+v for a rejected token", claims)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenHash(t *testing.T) {
+	token, hash, err := NewRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != HashRefreshToken(token) || hash == token {
+		t.Fatalf("hash %q does not match token %q", hash, token)
+	}
+	other, _, err := NewRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == token {
+		t.Fatal("refresh tokens should be random")
+	}
+}
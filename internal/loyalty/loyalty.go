@@ -0,0 +1,47 @@
+package loyalty
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidConfig = errors.New("LOYALTY_EARN_PER dan LOYALTY_POINT_VALUE harus angka bulat, EARN_PER 0 = poin nonaktif")
+
+// Config - aturan poin member. EarnPer = tiap kelipatan belanja segini dapet 1 poin
+// (10000 = 1 poin per Rp10.000), 0 = ga ada poin. PointValue = nilai rupiah 1 poin pas dipake bayar
+type Config struct {
+	EarnPer    int
+	PointValue int
+}
+
+// ParseConfig - earnPer & pointValue dalam rupiah
+func ParseConfig(earnPer, pointValue string) (Config, error) {
+	per, err := strconv.Atoi(strings.TrimSpace(earnPer))
+	if err != nil || per < 0 {
+		return Config{}, fmt.Errorf("%w: earn per %q", ErrInvalidConfig, earnPer)
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(pointValue))
+	if err != nil || value <= 0 {
+		return Config{}, fmt.Errorf("%w: point value %q", ErrInvalidConfig, pointValue)
+	}
+	return Config{EarnPer: per, PointValue: value}, nil
+}
+
+// Earn - poin yang didapet dari belanja amount rupiah, dibulatkan ke bawah
+func (c Config) Earn(amount int) int {
+	if c.EarnPer <= 0 || amount <= 0 {
+		return 0
+	}
+	return amount / c.EarnPer
+}
+
+// Points - jumlah poin buat bayar amount rupiah. ok false kalau amount
+// bukan kelipatan nilai poin, biar ga ada sisa pecahan poin
+func (c Config) Points(amount int) (points int, ok bool) {
+	if c.PointValue <= 0 || amount%c.PointValue != 0 {
+		return 0, false
+	}
+	return amount / c.PointValue, true
+}
@@ -0,0 +1,130 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const fakeSignatureHeader = "X-Fake-Signature"
+
+// FakeGateway - gateway in-process buat local dev & test. Charge disimpen di memory,
+// pembayaran disimulasiin lewat Simulate yang ngasih webhook ber-signature
+type FakeGateway struct {
+	secret []byte
+
+	mu      sync.Mutex
+	charges map[string]*Charge
+}
+
+func NewFakeGateway(secret string) *FakeGateway {
+	return &FakeGateway{secret: []byte(secret), charges: make(map[string]*Charge)}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	charge := &Charge{
+		ID:        "fake-" + hex.EncodeToString(b),
+		OrderID:   req.OrderID,
+		Amount:    req.Amount,
+		Status:    StatusPending,
+		QRString:  fmt.Sprintf("00020101021226FAKEQRIS%s5303360540%d6304", req.OrderID, req.Amount),
+		ExpiresAt: time.Now().Add(req.ExpiresIn),
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.charges[charge.ID] = charge
+	c := *charge
+	return &c, nil
+}
+
+func (g *FakeGateway) CheckStatus(ctx context.Context, chargeID string) (*Charge, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	charge, ok := g.charges[chargeID]
+	if !ok {
+		return nil, ErrChargeNotFound
+	}
+	if charge.Status == StatusPending && time.Now().After(charge.ExpiresAt) {
+		charge.Status = StatusExpired
+	}
+	c := *charge
+	return &c, nil
+}
+
+type fakeWebhookBody struct {
+	ChargeID string `json:"charge_id"`
+	OrderID  string `json:"order_id"`
+	Amount   int    `json:"amount"`
+	Status   string `json:"status"`
+}
+
+func (g *FakeGateway) ParseWebhook(header http.Header, body []byte) (*WebhookEvent, error) {
+	if !hmac.Equal([]byte(header.Get(fakeSignatureHeader)), []byte(g.sign(body))) {
+		return nil, ErrInvalidSignature
+	}
+	var payload fakeWebhookBody
+	if err := json.Unmarshal(body, &payload); err != nil || payload.ChargeID == "" {
+		return nil, ErrInvalidWebhook
+	}
+	return &WebhookEvent{
+		ChargeID: payload.ChargeID,
+		OrderID:  payload.OrderID,
+		Amount:   payload.Amount,
+		Status:   payload.Status,
+	}, nil
+}
+
+// Simulate - ubah status charge (paid/failed/expired) lalu return webhook yang
+// bakal dikirim gateway beneran: header ber-signature + body JSON
+func (g *FakeGateway) Simulate(chargeID, status string) (http.Header, []byte, error) {
+	if status != StatusPaid && status != StatusFailed && status != StatusExpired {
+		return nil, nil, fmt.Errorf("status simulasi harus paid, failed atau expired")
+	}
+
+	g.mu.Lock()
+	stored, ok := g.charges[chargeID]
+	var charge Charge
+	if ok {
+		stored.Status = status
+		charge = *stored
+	}
+	g.mu.Unlock()
+	if !ok {
+		return nil, nil, ErrChargeNotFound
+	}
+
+	body, err := json.Marshal(fakeWebhookBody{
+		ChargeID: charge.ID,
+		OrderID:  charge.OrderID,
+		Amount:   charge.Amount,
+		Status:   status,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	header := http.Header{}
+	header.Set(fakeSignatureHeader, g.sign(body))
+	return header, body, nil
+}
+
+func (g *FakeGateway) sign(body []byte) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,59 @@
+package payment
+
+import (
+	"context"
+	"kasir-api/internal/apperror"
+	"net/http"
+	"time"
+)
+
+// Status charge di sisi gateway
+const (
+	StatusPending = "pending"
+	StatusPaid    = "paid"
+	StatusExpired = "expired"
+	StatusFailed  = "failed"
+)
+
+var (
+	ErrChargeNotFound   = apperror.New(apperror.ErrNotFound, "charge tidak ditemukan di gateway")
+	ErrInvalidSignature = apperror.New(apperror.ErrUnauthorized, "signature webhook tidak valid")
+	ErrInvalidWebhook   = apperror.New(apperror.ErrValidation, "payload webhook tidak valid")
+)
+
+// ChargeRequest - OrderID harus unik per charge (dipake gateway buat idempotency)
+type ChargeRequest struct {
+	OrderID   string
+	Amount    int
+	ExpiresIn time.Duration
+}
+
+// Charge - QR dinamis yang ditampilin ke customer. QRString itu payload QRIS
+// yang di-render jadi gambar QR di client
+type Charge struct {
+	ID        string
+	OrderID   string
+	Amount    int
+	Status    string
+	QRString  string
+	ExpiresAt time.Time
+}
+
+// WebhookEvent - hasil parsing callback gateway yang signature-nya udah valid
+type WebhookEvent struct {
+	ChargeID string
+	OrderID  string
+	Amount   int
+	Status   string
+}
+
+// PaymentGateway - provider QRIS (Midtrans, Xendit, dll). Implementasi baru cukup
+// memenuhi interface ini lalu dipilih di main.go lewat PAYMENT_GATEWAY
+type PaymentGateway interface {
+	Name() string
+	CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error)
+	CheckStatus(ctx context.Context, chargeID string) (*Charge, error)
+	// ParseWebhook - verifikasi signature dulu, baru decode body.
+	// Wajib return ErrInvalidSignature kalau signature ga cocok
+	ParseWebhook(header http.Header, body []byte) (*WebhookEvent, error)
+}
@@ -0,0 +1,225 @@
+package promotion
+
+import (
+	"kasir-api/models"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ActiveAt - promo berlaku di waktu t (t udah dalam timezone bisnis)
+func ActiveAt(p models.Promotion, t time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !t.Before(*p.EndsAt) {
+		return false
+	}
+	return len(p.Days) == 0 || slices.Contains(p.Days, int(t.Weekday()))
+}
+
+// Evaluate - hitung harga keranjang pake promo yang berlaku di now. Murni hitungan, ga akses DB.
+// lines cukup diisi ProductID, ProductName, CategoryID, Price dan Quantity (satu baris per produk).
+//
+// Urutannya: promo per baris (buy_x_get_y, percent, bundle) dulu sesuai Priority (besar duluan,
+// seri = ID kecil duluan), baru promo keranjang (cart_amount) dengan urutan yang sama.
+// Semua potongan dibulatkan ke bawah ke rupiah dan ga pernah bikin baris/keranjang minus
+func Evaluate(lines []models.CartLine, promos []models.Promotion, now time.Time) models.CartPrice {
+	e := evaluator{lines: make([]models.CartLine, len(lines)), locked: make([]bool, len(lines))}
+	for i, l := range lines {
+		l.Subtotal = l.Price * l.Quantity
+		l.Discount = 0
+		l.Promotions = make([]models.AppliedPromotion, 0)
+		e.lines[i] = l
+	}
+
+	active := make([]models.Promotion, 0, len(promos))
+	for _, p := range promos {
+		if ActiveAt(p, now) {
+			active = append(active, p)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		if active[i].Priority != active[j].Priority {
+			return active[i].Priority > active[j].Priority
+		}
+		return active[i].ID < active[j].ID
+	})
+
+	for _, p := range active {
+		switch p.Type {
+		case models.PromoBuyXGetY:
+			e.buyXGetY(p)
+		case models.PromoPercent:
+			e.percent(p)
+		case models.PromoBundle:
+			e.bundle(p)
+		}
+	}
+	for _, p := range active {
+		if p.Type == models.PromoCartAmount {
+			e.cartAmount(p)
+		}
+	}
+
+	result := models.CartPrice{Lines: e.lines, CartPromotions: e.cartPromotions}
+	if result.CartPromotions == nil {
+		result.CartPromotions = make([]models.AppliedPromotion, 0)
+	}
+	for i := range result.Lines {
+		l := &result.Lines[i]
+		l.Total = l.Subtotal - l.Discount
+		result.Subtotal += l.Subtotal
+		result.LineDiscount += l.Discount
+	}
+	result.CartDiscount = e.cartDiscount
+	result.Total = result.Subtotal - result.LineDiscount - result.CartDiscount
+	return result
+}
+
+type evaluator struct {
+	lines []models.CartLine
+	// locked - baris yang udah kena promo non-stackable, ga boleh dapet promo lain lagi
+	locked         []bool
+	cartPromotions []models.AppliedPromotion
+	cartDiscount   int
+	cartLocked     bool
+}
+
+func (e *evaluator) remaining(i int) int {
+	return e.lines[i].Subtotal - e.lines[i].Discount
+}
+
+func (e *evaluator) eligible(i int, p models.Promotion) bool {
+	return !e.locked[i] && (p.Stackable || e.lines[i].Discount == 0)
+}
+
+func targets(p models.Promotion, l models.CartLine) bool {
+	if len(p.ProductIDs) == 0 && len(p.CategoryIDs) == 0 {
+		return true
+	}
+	return slices.Contains(p.ProductIDs, l.ProductID) || slices.Contains(p.CategoryIDs, l.CategoryID)
+}
+
+// apply - tempel potongan ke baris i, dipotong kalau lebih dari sisa harga baris
+func (e *evaluator) apply(i int, p models.Promotion, discount, freeQty int) {
+	discount = min(discount, e.remaining(i))
+	if discount <= 0 {
+		return
+	}
+	e.lines[i].Discount += discount
+	e.lines[i].Promotions = append(e.lines[i].Promotions, models.AppliedPromotion{
+		PromotionID: p.ID,
+		Name:        p.Name,
+		Type:        p.Type,
+		Discount:    discount,
+		FreeQty:     freeQty,
+	})
+	if !p.Stackable {
+		e.locked[i] = true
+	}
+}
+
+func (e *evaluator) buyXGetY(p models.Promotion) {
+	group := p.BuyQty + p.FreeQty
+	if p.BuyQty <= 0 || p.FreeQty <= 0 {
+		return
+	}
+	for i, l := range e.lines {
+		if !targets(p, l) || !e.eligible(i, p) {
+			continue
+		}
+		free := l.Quantity / group * p.FreeQty
+		e.apply(i, p, free*l.Price, free)
+	}
+}
+
+func (e *evaluator) percent(p models.Promotion) {
+	if p.Percent <= 0 {
+		return
+	}
+	for i, l := range e.lines {
+		if !targets(p, l) || !e.eligible(i, p) {
+			continue
+		}
+		e.apply(i, p, e.remaining(i)*min(p.Percent, 100)/100, 0)
+	}
+}
+
+// bundle - semua produk di ProductIDs wajib ada di keranjang. Potongan per paket
+// dibagi ke baris-baris paket sebanding harga normalnya, sisa pembulatan ke baris terakhir
+func (e *evaluator) bundle(p models.Promotion) {
+	qty := max(p.BundleQty, 1)
+	if len(p.ProductIDs) == 0 {
+		return
+	}
+	idx := make([]int, 0, len(p.ProductIDs))
+	sets, normal := -1, 0
+	for _, productID := range p.ProductIDs {
+		i := slices.IndexFunc(e.lines, func(l models.CartLine) bool { return l.ProductID == productID })
+		if i < 0 || !e.eligible(i, p) {
+			return
+		}
+		idx = append(idx, i)
+		n := e.lines[i].Quantity / qty
+		if sets < 0 || n < sets {
+			sets = n
+		}
+		normal += e.lines[i].Price * qty
+	}
+	perSet := normal - p.BundlePrice
+	if sets <= 0 || perSet <= 0 {
+		return
+	}
+
+	total := perSet * sets
+	allocated := 0
+	for k, i := range idx {
+		share := total * e.lines[i].Price * qty / normal
+		if k == len(idx)-1 {
+			share = total - allocated
+		}
+		allocated += share
+		e.apply(i, p, share, 0)
+	}
+}
+
+// cartAmount - dihitung dari total setelah diskon baris. Promo keranjang dianggap nyentuh
+// semua baris, jadi ga jalan kalau ada baris yang ke-lock promo non-stackable
+func (e *evaluator) cartAmount(p models.Promotion) {
+	if p.Amount <= 0 || e.cartLocked {
+		return
+	}
+	net := -e.cartDiscount
+	discounted := e.cartDiscount > 0
+	for i := range e.lines {
+		net += e.remaining(i)
+		if e.lines[i].Discount > 0 {
+			discounted = true
+		}
+		if e.locked[i] {
+			return
+		}
+	}
+	if (!p.Stackable && discounted) || net < p.MinSubtotal {
+		return
+	}
+
+	discount := min(p.Amount, net)
+	if discount <= 0 {
+		return
+	}
+	e.cartDiscount += discount
+	e.cartPromotions = append(e.cartPromotions, models.AppliedPromotion{
+		PromotionID: p.ID,
+		Name:        p.Name,
+		Type:        p.Type,
+		Discount:    discount,
+	})
+	if !p.Stackable {
+		e.cartLocked = true
+	}
+}
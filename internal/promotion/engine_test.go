@@ -0,0 +1,267 @@
+package promotion
+
+import (
+	"kasir-api/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Rabu, 14 Oktober 2026 jam 10 pagi (timezone bisnis)
+var now = time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+
+func at(hour int) *time.Time {
+	t := time.Date(2026, 10, 14, hour, 0, 0, 0, time.UTC)
+	return &t
+}
+
+// cart - Indomie 3 x 10000 (kategori 10) + Teh Botol 2 x 5000 (kategori 20), subtotal 40000
+func cart() []models.CartLine {
+	return []models.CartLine{
+		{ProductID: 1, ProductName: "Indomie", CategoryID: 10, Price: 10000, Quantity: 3},
+		{ProductID: 2, ProductName: "Teh Botol", CategoryID: 20, Price: 5000, Quantity: 2},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name   string
+		lines  []models.CartLine
+		promos []models.Promotion
+		// lineDiscounts - potongan per baris, urut sama kayak lines
+		lineDiscounts []int
+		cartDiscount  int
+		total         int
+	}{
+		{
+			name:          "no promo",
+			lineDiscounts: []int{0, 0},
+			total:         40000,
+		},
+		{
+			name: "overlapping non-stackable, higher priority wins the line",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoPercent, Active: true, Percent: 10},
+				{ID: 2, Type: models.PromoPercent, Active: true, Priority: 5, Percent: 20, ProductIDs: []int{1}},
+			},
+			lineDiscounts: []int{6000, 1000},
+			total:         33000,
+		},
+		{
+			name: "overlapping same priority, lower id wins",
+			promos: []models.Promotion{
+				{ID: 4, Type: models.PromoPercent, Active: true, Percent: 10, CategoryIDs: []int{10}},
+				{ID: 3, Type: models.PromoPercent, Active: true, Percent: 50, ProductIDs: []int{1}},
+			},
+			lineDiscounts: []int{15000, 0},
+			total:         25000,
+		},
+		{
+			name: "overlapping stackable promos compound on the remaining price",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoPercent, Active: true, Stackable: true, Priority: 2, Percent: 10, ProductIDs: []int{1}},
+				{ID: 2, Type: models.PromoPercent, Active: true, Stackable: true, Priority: 1, Percent: 50, ProductIDs: []int{1}},
+			},
+			lineDiscounts: []int{16500, 0},
+			total:         23500,
+		},
+		{
+			name: "non-stackable skips a line that already has a discount",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoPercent, Active: true, Stackable: true, Priority: 2, Percent: 10, ProductIDs: []int{1}},
+				{ID: 2, Type: models.PromoPercent, Active: true, Priority: 1, Percent: 50},
+			},
+			lineDiscounts: []int{3000, 5000},
+			total:         32000,
+		},
+		{
+			name: "buy 2 get 1 locks the line from a later percent promo",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoBuyXGetY, Active: true, Priority: 3, BuyQty: 2, FreeQty: 1, ProductIDs: []int{1}},
+				{ID: 2, Type: models.PromoPercent, Active: true, Percent: 10},
+			},
+			lineDiscounts: []int{10000, 1000},
+			total:         29000,
+		},
+		{
+			name: "bundle leftover units stay at normal price",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoBundle, Active: true, ProductIDs: []int{1, 2}, BundlePrice: 12000},
+			},
+			// 2 paket (Teh Botol cuma 2), potongan 2 x 3000 dibagi 2:1 sesuai harga normal,
+			// Indomie ketiga tetap 10000
+			lineDiscounts: []int{4000, 2000},
+			total:         34000,
+		},
+		{
+			name: "bundle qty leaves an incomplete set",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoBundle, Active: true, ProductIDs: []int{1, 2}, BundleQty: 2, BundlePrice: 24000},
+			},
+			lineDiscounts: []int{4000, 2000},
+			total:         34000,
+		},
+		{
+			name: "bundle with a missing product does nothing",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoBundle, Active: true, ProductIDs: []int{1, 3}, BundlePrice: 1000},
+			},
+			lineDiscounts: []int{0, 0},
+			total:         40000,
+		},
+		{
+			name: "bundle rounding remainder goes to the last line",
+			lines: []models.CartLine{
+				{ProductID: 1, Price: 1000, Quantity: 1},
+				{ProductID: 2, Price: 2000, Quantity: 1},
+			},
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoBundle, Active: true, ProductIDs: []int{1, 2}, BundlePrice: 2000},
+			},
+			lineDiscounts: []int{333, 667},
+			total:         2000,
+		},
+		{
+			name: "cart amount larger than the subtotal stops at zero",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoCartAmount, Active: true, Amount: 50000},
+			},
+			lineDiscounts: []int{0, 0},
+			cartDiscount:  40000,
+			total:         0,
+		},
+		{
+			name: "cart amount larger than what is left after line discounts",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoPercent, Active: true, Stackable: true, Percent: 10},
+				{ID: 2, Type: models.PromoCartAmount, Active: true, Stackable: true, Amount: 50000},
+			},
+			lineDiscounts: []int{3000, 1000},
+			cartDiscount:  36000,
+			total:         0,
+		},
+		{
+			name: "two stackable cart amounts never go below zero",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoCartAmount, Active: true, Stackable: true, Amount: 30000},
+				{ID: 2, Type: models.PromoCartAmount, Active: true, Stackable: true, Amount: 30000},
+			},
+			lineDiscounts: []int{0, 0},
+			cartDiscount:  40000,
+			total:         0,
+		},
+		{
+			name: "cart amount below minimum subtotal",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoCartAmount, Active: true, Amount: 5000, MinSubtotal: 50000},
+			},
+			lineDiscounts: []int{0, 0},
+			total:         40000,
+		},
+		{
+			name: "non-stackable cart amount skipped when lines are discounted",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoPercent, Active: true, Stackable: true, Percent: 10, ProductIDs: []int{2}},
+				{ID: 2, Type: models.PromoCartAmount, Active: true, Amount: 5000},
+			},
+			lineDiscounts: []int{0, 1000},
+			total:         39000,
+		},
+		{
+			name: "inactive promo",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoPercent, Percent: 10},
+			},
+			lineDiscounts: []int{0, 0},
+			total:         40000,
+		},
+		{
+			name: "promo not valid on wednesday",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoPercent, Active: true, Percent: 10, Days: []int{0, 6}},
+			},
+			lineDiscounts: []int{0, 0},
+			total:         40000,
+		},
+		{
+			name: "promo valid on wednesday",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoPercent, Active: true, Percent: 10, Days: []int{3}},
+			},
+			lineDiscounts: []int{3000, 1000},
+			total:         36000,
+		},
+		{
+			name: "promo not started yet",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoPercent, Active: true, Percent: 10, StartsAt: at(11)},
+			},
+			lineDiscounts: []int{0, 0},
+			total:         40000,
+		},
+		{
+			name: "promo ends exactly now",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoPercent, Active: true, Percent: 10, StartsAt: at(8), EndsAt: at(10)},
+			},
+			lineDiscounts: []int{0, 0},
+			total:         40000,
+		},
+		{
+			name: "promo starts exactly now",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoPercent, Active: true, Percent: 10, StartsAt: at(10), EndsAt: at(12)},
+			},
+			lineDiscounts: []int{3000, 1000},
+			total:         36000,
+		},
+		{
+			name: "inactive high priority promo does not lock the line",
+			promos: []models.Promotion{
+				{ID: 1, Type: models.PromoPercent, Active: true, Priority: 9, Percent: 50, EndsAt: at(9)},
+				{ID: 2, Type: models.PromoPercent, Active: true, Percent: 10},
+			},
+			lineDiscounts: []int{3000, 1000},
+			total:         36000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := tt.lines
+			if lines == nil {
+				lines = cart()
+			}
+			got := Evaluate(lines, tt.promos, now)
+
+			discounts := make([]int, len(got.Lines))
+			for i, l := range got.Lines {
+				discounts[i] = l.Discount
+				if l.Total != l.Subtotal-l.Discount || l.Total < 0 {
+					t.Fatalf("line %d total = %d, subtotal %d discount %d", i, l.Total, l.Subtotal, l.Discount)
+				}
+			}
+			if !slices.Equal(discounts, tt.lineDiscounts) {
+				t.Fatalf("line discounts = %v, want %v", discounts, tt.lineDiscounts)
+			}
+			if got.CartDiscount != tt.cartDiscount {
+				t.Fatalf("cart discount = %d, want %d", got.CartDiscount, tt.cartDiscount)
+			}
+			if got.Total != tt.total {
+				t.Fatalf("total = %d, want %d", got.Total, tt.total)
+			}
+			if got.Total != got.Subtotal-got.LineDiscount-got.CartDiscount {
+				t.Fatalf("total %d != subtotal %d - line %d - cart %d", got.Total, got.Subtotal, got.LineDiscount, got.CartDiscount)
+			}
+		})
+	}
+}
+
+// Evaluate ga boleh ngubah slice keranjang punya caller
+func TestEvaluateDoesNotMutateInput(t *testing.T) {
+	lines := cart()
+	Evaluate(lines, []models.Promotion{{ID: 1, Type: models.PromoPercent, Active: true, Percent: 10}}, now)
+	if lines[0].Discount != 0 || lines[0].Subtotal != 0 {
+		t.Fatalf("input line mutated: %+v", lines[0])
+	}
+}
@@ -0,0 +1,36 @@
+package receipt
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Perintah ESC/POS yang dipake, aman di hampir semua printer thermal 58/80mm
+var (
+	escInit    = []byte{0x1b, '@'}
+	escBoldOn  = []byte{0x1b, 'E', 1}
+	escBoldOff = []byte{0x1b, 'E', 0}
+	escFeed    = []byte{0x1b, 'd', 4}
+	gsCut      = []byte{0x1d, 'V', 1}
+)
+
+// renderESCPOS - baris dari Layout dikirim apa adanya (udah rata pake spasi),
+// nama toko & TOTAL ditebalin, lalu feed dan potong kertas
+func renderESCPOS(r *Receipt, width int) []byte {
+	var buf bytes.Buffer
+	buf.Write(escInit)
+	for i, line := range r.Layout(width) {
+		bold := (i == 0 && r.Store.Name != "") || strings.HasPrefix(line, "TOTAL")
+		if bold {
+			buf.Write(escBoldOn)
+		}
+		buf.WriteString(ascii(line))
+		buf.WriteByte('\n')
+		if bold {
+			buf.Write(escBoldOff)
+		}
+	}
+	buf.Write(escFeed)
+	buf.Write(gsCut)
+	return buf.Bytes()
+}
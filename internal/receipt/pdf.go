@@ -0,0 +1,56 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pdfFontSize   = 9.0
+	pdfLineHeight = 11.0
+	pdfMargin     = 14.0
+)
+
+// renderPDF - PDF satu halaman seukuran struk, teks Courier biar kolomnya sama
+// kayak versi thermal. Ditulis manual biar ga perlu library PDF
+func renderPDF(r *Receipt, width int) []byte {
+	lines := r.Layout(width)
+	pageWidth := float64(width)*pdfFontSize*0.6 + 2*pdfMargin
+	pageHeight := float64(len(lines))*pdfLineHeight + 2*pdfMargin
+
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "BT\n/F1 %.0f Tf\n%.0f TL\n%.2f %.2f Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pageHeight-pdfMargin-pdfFontSize)
+	for _, line := range lines {
+		fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(ascii(line)))
+	}
+	content.WriteString("ET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>", pageWidth, pageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func pdfEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+}
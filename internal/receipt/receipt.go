@@ -0,0 +1,237 @@
+package receipt
+
+import (
+	"fmt"
+	"kasir-api/internal/apperror"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Format output struk
+const (
+	FormatText   = "text"
+	FormatESCPOS = "escpos"
+	FormatPDF    = "pdf"
+)
+
+// Lebar kertas printer thermal dalam mm, dipetakan ke jumlah karakter per baris (font A)
+const (
+	Paper58 = 58
+	Paper80 = 80
+)
+
+var (
+	ErrUnknownFormat = apperror.New(apperror.ErrValidation, "format struk harus text, escpos atau pdf")
+	ErrUnknownPaper  = apperror.New(apperror.ErrValidation, "paper harus 58 atau 80")
+)
+
+// Store - identitas toko di kepala & kaki struk, diisi dari config
+type Store struct {
+	Name    string
+	Address string
+	NPWP    string
+	Footer  string
+}
+
+// Line - satu baris produk. Discount itu potongan total buat baris ini (bukan per unit)
+type Line struct {
+	Name     string
+	Quantity int
+	Price    int
+	Discount int
+	Subtotal int
+}
+
+// Payment - Tendered yang diserahin customer, bukan yang masuk ke penjualan
+type Payment struct {
+	Label    string
+	Tendered int
+}
+
+// Receipt - isi struk yang udah siap dicetak, semua nominal dalam rupiah
+type Receipt struct {
+	Store     Store
+	Number    string
+	Cashier   string
+	CreatedAt time.Time
+	Lines     []Line
+	Subtotal  int
+	Discount  int
+	TaxLabel  string
+	Tax       int
+	Total     int
+	Payments  []Payment
+	Change    int
+	Refunded  int
+}
+
+// Columns - jumlah karakter per baris buat lebar kertas
+func Columns(paper int) (int, error) {
+	switch paper {
+	case Paper58:
+		return 32, nil
+	case Paper80:
+		return 48, nil
+	}
+	return 0, ErrUnknownPaper
+}
+
+// Render - return isi struk + content type sesuai format
+func Render(r *Receipt, format string, paper int) ([]byte, string, error) {
+	width, err := Columns(paper)
+	if err != nil {
+		return nil, "", err
+	}
+	switch format {
+	case FormatText:
+		return []byte(strings.Join(r.Layout(width), "\n") + "\n"), "text/plain; charset=utf-8", nil
+	case FormatESCPOS:
+		return renderESCPOS(r, width), "application/octet-stream", nil
+	case FormatPDF:
+		return renderPDF(r, width), "application/pdf", nil
+	}
+	return nil, "", ErrUnknownFormat
+}
+
+// Layout - struk dalam bentuk baris teks monospace selebar width.
+// Dipake langsung buat format text, dan jadi dasar ESC/POS & PDF
+func (r *Receipt) Layout(width int) []string {
+	sep := strings.Repeat("-", width)
+	lines := make([]string, 0, 32)
+
+	if r.Store.Name != "" {
+		lines = append(lines, center(r.Store.Name, width))
+	}
+	for _, l := range wrap(r.Store.Address, width) {
+		lines = append(lines, center(l, width))
+	}
+	if r.Store.NPWP != "" {
+		lines = append(lines, center("NPWP: "+r.Store.NPWP, width))
+	}
+	lines = append(lines, sep)
+	lines = append(lines, row("No: "+r.Number, r.CreatedAt.Format("02/01/2006 15:04"), width))
+	if r.Cashier != "" {
+		lines = append(lines, "Kasir: "+r.Cashier)
+	}
+	lines = append(lines, sep)
+
+	for _, item := range r.Lines {
+		lines = append(lines, wrap(item.Name, width)...)
+		qty := fmt.Sprintf("  %d x %s", item.Quantity, Rupiah(item.Price))
+		lines = append(lines, row(qty, Rupiah(item.Quantity*item.Price), width))
+		if item.Discount != 0 {
+			lines = append(lines, row("  Diskon", "-"+Rupiah(item.Discount), width))
+		}
+	}
+	lines = append(lines, sep)
+
+	lines = append(lines, row("Subtotal", Rupiah(r.Subtotal), width))
+	if r.Discount != 0 {
+		lines = append(lines, row("Diskon", "-"+Rupiah(r.Discount), width))
+	}
+	if r.Tax != 0 {
+		label := r.TaxLabel
+		if label == "" {
+			label = "Pajak"
+		}
+		lines = append(lines, row(label, Rupiah(r.Tax), width))
+	}
+	lines = append(lines, row("TOTAL", Rupiah(r.Total), width))
+	for _, p := range r.Payments {
+		lines = append(lines, row(p.Label, Rupiah(p.Tendered), width))
+	}
+	if r.Change != 0 {
+		lines = append(lines, row("Kembali", Rupiah(r.Change), width))
+	}
+	if r.Refunded != 0 {
+		lines = append(lines, row("Direfund", "-"+Rupiah(r.Refunded), width))
+	}
+
+	if r.Store.Footer != "" {
+		lines = append(lines, sep)
+		for _, l := range wrap(r.Store.Footer, width) {
+			lines = append(lines, center(l, width))
+		}
+	}
+	return lines
+}
+
+// Rupiah - 1500000 -> "1.500.000"
+func Rupiah(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	s := fmt.Sprintf("%d", amount)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "." + s[i:]
+	}
+	return sign + s
+}
+
+// row - teks kiri dan kanan dalam satu baris, kiri dipotong kalau ga muat
+func row(left, right string, width int) string {
+	space := width - utf8.RuneCountInString(right) - 1
+	if space < 0 {
+		space = 0
+	}
+	left = truncate(left, space)
+	return left + strings.Repeat(" ", width-utf8.RuneCountInString(left)-utf8.RuneCountInString(right)) + right
+}
+
+func center(s string, width int) string {
+	s = truncate(s, width)
+	pad := (width - utf8.RuneCountInString(s)) / 2
+	return strings.Repeat(" ", pad) + s
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// wrap - pecah teks per kata biar ga lewat width, kata yang kepanjangan dipotong paksa
+func wrap(s string, width int) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(s) {
+		for utf8.RuneCountInString(word) > width {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+		}
+		switch {
+		case current == "":
+			current = word
+		case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= width:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// ascii - printer thermal & font standar PDF ga ngerti UTF-8, karakter non-ASCII diganti "?"
+func ascii(s string) string {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			r = '?'
+		}
+		b = append(b, byte(r))
+	}
+	return string(b)
+}
@@ -0,0 +1,31 @@
+package tax
+
+import "kasir-api/models"
+
+// ApplyCart - isi Net/Tax/Gross tiap baris dan keranjang dari hasil promotion.Evaluate.
+// Diskon keranjang dibagi ke baris sebanding Total baris (kumulatif, dibulatkan ke bawah)
+// biar dasar pajaknya ikut turun, baru tiap baris di-Split sendiri-sendiri
+func (c Config) ApplyCart(price *models.CartPrice) {
+	price.TaxMode = c.Mode
+	price.TaxRate = c.RatePercent()
+	price.Net, price.Tax, price.Gross = 0, 0, 0
+
+	base := 0
+	for _, l := range price.Lines {
+		base += l.Total
+	}
+	cumulative := 0
+	for i := range price.Lines {
+		l := &price.Lines[i]
+		l.CartDiscount = 0
+		if base > 0 {
+			before := price.CartDiscount * cumulative / base
+			cumulative += l.Total
+			l.CartDiscount = price.CartDiscount*cumulative/base - before
+		}
+		l.Net, l.Tax, l.Gross = c.Split(l.Total-l.CartDiscount, l.TaxExempt)
+		price.Net += l.Net
+		price.Tax += l.Tax
+		price.Gross += l.Gross
+	}
+}
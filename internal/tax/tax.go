@@ -0,0 +1,66 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Mode harga jual
+const (
+	// ModeInclusive - harga produk udah termasuk PPN, pajak diambil dari dalam harga
+	ModeInclusive = "inclusive"
+	// ModeExclusive - harga produk belum termasuk PPN, pajak ditambahin di atas harga
+	ModeExclusive = "exclusive"
+)
+
+var ErrInvalidConfig = errors.New("TAX_RATE harus angka 0-100 dan TAX_MODE harus inclusive atau exclusive")
+
+// Config - tarif dalam basis point (1100 = 11%) biar semua hitungan tetap integer
+type Config struct {
+	RateBP int
+	Mode   string
+}
+
+// ParseConfig - rate dalam persen ("11", "12", "11.5"), mode inclusive/exclusive
+func ParseConfig(rate, mode string) (Config, error) {
+	pct, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+	if err != nil || pct < 0 || pct > 100 {
+		return Config{}, fmt.Errorf("%w: rate %q", ErrInvalidConfig, rate)
+	}
+	if mode != ModeInclusive && mode != ModeExclusive {
+		return Config{}, fmt.Errorf("%w: mode %q", ErrInvalidConfig, mode)
+	}
+	return Config{RateBP: int(math.Round(pct * 100)), Mode: mode}, nil
+}
+
+// RatePercent - tarif dalam persen, buat ditampilin
+func (c Config) RatePercent() float64 {
+	return float64(c.RateBP) / 100
+}
+
+// Split - pecah nominal satu baris jadi DPP (net), PPN dan total (gross).
+// amount itu nominal baris sesuai mode: udah termasuk pajak (inclusive) atau belum (exclusive).
+//
+// Aturan pembulatan: dihitung per baris ke rupiah terdekat, setengah dibulatkan ke atas.
+// Inclusive: net = round(amount * 10000 / (10000 + rate)), tax = amount - net.
+// Exclusive: tax = round(amount * rate / 10000), gross = amount + tax.
+// Total keranjang/struk selalu jumlah per baris, jadi net + tax = gross di level mana pun
+func (c Config) Split(amount int, exempt bool) (net, tax, gross int) {
+	if exempt || c.RateBP == 0 || amount == 0 {
+		return amount, 0, amount
+	}
+	if c.Mode == ModeInclusive {
+		net = divRound(amount*10000, 10000+c.RateBP)
+		return net, amount - net, amount
+	}
+	tax = divRound(amount*c.RateBP, 10000)
+	return amount, tax, amount + tax
+}
+
+// divRound - a/b dibulatkan ke terdekat, setengah ke atas (a, b >= 0)
+func divRound(a, b int) int {
+	return (2*a + b) / (2 * b)
+}
@@ -0,0 +1,154 @@
+package tax
+
+import (
+	"kasir-api/models"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	ppn11 := 1100
+	tests := []struct {
+		name            string
+		config          Config
+		amount          int
+		exempt          bool
+		net, tax, gross int
+	}{
+		{name: "inclusive exact", config: Config{RateBP: ppn11, Mode: ModeInclusive}, amount: 11100, net: 10000, tax: 1100, gross: 11100},
+		{name: "inclusive rounds net", config: Config{RateBP: ppn11, Mode: ModeInclusive}, amount: 1000, net: 901, tax: 99, gross: 1000},
+		{name: "inclusive tiny amount", config: Config{RateBP: ppn11, Mode: ModeInclusive}, amount: 5, net: 5, tax: 0, gross: 5},
+		{name: "exclusive exact", config: Config{RateBP: ppn11, Mode: ModeExclusive}, amount: 10000, net: 10000, tax: 1100, gross: 11100},
+		{name: "exclusive half rounds up", config: Config{RateBP: ppn11, Mode: ModeExclusive}, amount: 50, net: 50, tax: 6, gross: 56},
+		{name: "exclusive fractional rate", config: Config{RateBP: 1150, Mode: ModeExclusive}, amount: 1000, net: 1000, tax: 115, gross: 1115},
+		{name: "exempt inclusive", config: Config{RateBP: ppn11, Mode: ModeInclusive}, amount: 1000, exempt: true, net: 1000, gross: 1000},
+		{name: "exempt exclusive", config: Config{RateBP: ppn11, Mode: ModeExclusive}, amount: 1000, exempt: true, net: 1000, gross: 1000},
+		{name: "zero rate", config: Config{Mode: ModeExclusive}, amount: 1000, net: 1000, gross: 1000},
+		{name: "zero amount", config: Config{RateBP: ppn11, Mode: ModeInclusive}, amount: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, tax, gross := tt.config.Split(tt.amount, tt.exempt)
+			if net != tt.net || tax != tt.tax || gross != tt.gross {
+				t.Fatalf("Split(%d) = %d, %d, %d, want %d, %d, %d", tt.amount, net, tax, gross, tt.net, tt.tax, tt.gross)
+			}
+			if net+tax != gross {
+				t.Fatalf("net %d + tax %d != gross %d", net, tax, gross)
+			}
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	c, err := ParseConfig("11.5", ModeInclusive)
+	if err != nil || c.RateBP != 1150 || c.Mode != ModeInclusive {
+		t.Fatalf("ParseConfig = %+v, %v", c, err)
+	}
+	for _, tt := range [][2]string{{"abc", ModeInclusive}, {"-1", ModeInclusive}, {"101", ModeExclusive}, {"11", "gross"}} {
+		if _, err := ParseConfig(tt[0], tt[1]); err == nil {
+			t.Fatalf("ParseConfig(%q, %q) should fail", tt[0], tt[1])
+		}
+	}
+}
+
+// Total per baris (DPP + PPN) harus selalu sama persis sama total keranjang,
+// diskon keranjang kebagi habis ke baris, dan baris bebas pajak ga kena PPN
+func TestApplyCart(t *testing.T) {
+	ppn11 := 1100
+	tests := []struct {
+		name            string
+		config          Config
+		lines           []models.CartLine
+		cartDiscount    int
+		net, tax, gross int
+	}{
+		{
+			name:   "inclusive",
+			config: Config{RateBP: ppn11, Mode: ModeInclusive},
+			lines:  []models.CartLine{{Total: 10000}, {Total: 3333}, {Total: 6667}},
+			net:    18018, tax: 1982, gross: 20000,
+		},
+		{
+			name:         "inclusive with exempt line and cart discount",
+			config:       Config{RateBP: ppn11, Mode: ModeInclusive},
+			lines:        []models.CartLine{{Total: 10000}, {Total: 3333, TaxExempt: true}, {Total: 6667}},
+			cartDiscount: 1000,
+			net:          17431, tax: 1569, gross: 19000,
+		},
+		{
+			name:   "exclusive",
+			config: Config{RateBP: ppn11, Mode: ModeExclusive},
+			lines:  []models.CartLine{{Total: 10000}, {Total: 3333}, {Total: 6667}},
+			net:    20000, tax: 2200, gross: 22200,
+		},
+		{
+			name:         "exclusive with exempt line and cart discount",
+			config:       Config{RateBP: ppn11, Mode: ModeExclusive},
+			lines:        []models.CartLine{{Total: 10000}, {Total: 3333, TaxExempt: true}, {Total: 6667}},
+			cartDiscount: 1000,
+			net:          19000, tax: 1742, gross: 20742,
+		},
+		{
+			name:         "all lines exempt",
+			config:       Config{RateBP: ppn11, Mode: ModeExclusive},
+			lines:        []models.CartLine{{Total: 7000, TaxExempt: true}, {Total: 3000, TaxExempt: true}},
+			cartDiscount: 999,
+			net:          9001, tax: 0, gross: 9001,
+		},
+		{
+			name:         "cart discount eats the whole cart",
+			config:       Config{RateBP: ppn11, Mode: ModeInclusive},
+			lines:        []models.CartLine{{Total: 2500}, {Total: 7500}},
+			cartDiscount: 10000,
+		},
+		{
+			name:   "free cart",
+			config: Config{RateBP: ppn11, Mode: ModeExclusive},
+			lines:  []models.CartLine{{Total: 0}, {Total: 0, TaxExempt: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := models.CartPrice{Lines: tt.lines, CartDiscount: tt.cartDiscount}
+			for _, l := range tt.lines {
+				price.Total += l.Total
+			}
+			price.Total -= tt.cartDiscount
+			tt.config.ApplyCart(&price)
+
+			if price.Net != tt.net || price.Tax != tt.tax || price.Gross != tt.gross {
+				t.Fatalf("cart = %d, %d, %d, want %d, %d, %d", price.Net, price.Tax, price.Gross, tt.net, tt.tax, tt.gross)
+			}
+			var net, tax, gross, cartDiscount int
+			for i, l := range price.Lines {
+				if l.Net+l.Tax != l.Gross {
+					t.Fatalf("line %d: net %d + tax %d != gross %d", i, l.Net, l.Tax, l.Gross)
+				}
+				if l.TaxExempt && l.Tax != 0 {
+					t.Fatalf("line %d is exempt but taxed %d", i, l.Tax)
+				}
+				if l.CartDiscount < 0 || l.CartDiscount > l.Total {
+					t.Fatalf("line %d cart discount %d outside 0..%d", i, l.CartDiscount, l.Total)
+				}
+				net += l.Net
+				tax += l.Tax
+				gross += l.Gross
+				cartDiscount += l.CartDiscount
+			}
+			if net != price.Net || tax != price.Tax || gross != price.Gross {
+				t.Fatalf("lines sum to %d, %d, %d, cart says %d, %d, %d", net, tax, gross, price.Net, price.Tax, price.Gross)
+			}
+			if cartDiscount != tt.cartDiscount {
+				t.Fatalf("cart discount allocated %d, want %d", cartDiscount, tt.cartDiscount)
+			}
+			want := price.Total
+			if tt.config.Mode == ModeExclusive {
+				want += price.Tax
+			}
+			if price.Gross != want {
+				t.Fatalf("gross = %d, want %d", price.Gross, want)
+			}
+		})
+	}
+}
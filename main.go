@@ -1,27 +1,55 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"kasir-api/database"
 	"kasir-api/handlers"
+	"kasir-api/internal/alert"
+	"kasir-api/internal/auth"
 	"kasir-api/internal/logger"
+	"kasir-api/internal/loyalty"
+	"kasir-api/internal/payment"
+	"kasir-api/internal/receipt"
+	"kasir-api/internal/requestid"
+	"kasir-api/internal/tax"
+	"kasir-api/models"
 	"kasir-api/repositories"
 	"kasir-api/services"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+	_ "time/tzdata" // biar LoadLocation jalan di container tanpa zoneinfo
 
 	"github.com/spf13/viper"
 )
 
-// Config hanya pegang PORT dan DB_CONN.
+// Config pegang PORT, DB_CONN, timezone bisnis, webhook low stock, secret JWT, payment gateway
+// dan identitas toko buat struk.
 // Railway set env di OS level, bukan di file .env,
 // jadi kita baca os.Getenv() langsung sebagai prioritas utama.
 type Config struct {
-	Port   string
-	DBConn string
+	AppEnv             string
+	Port               string
+	DBConn             string
+	Timezone           string
+	LowStockWebhookURL string
+	JWTSecret          string
+	AdminUsername      string
+	AdminPassword      string
+	PaymentGateway     string
+	QRISWebhookSecret  string
+	QRISStockPolicy    string
+	TaxRate            string
+	TaxMode            string
+	LoyaltyEarnPer     string
+	LoyaltyPointValue  string
+	MigrateOnStart     string
+	Store              receipt.Store
 }
 
 // loadConfig baca config dengan urutan prioritas:
@@ -42,6 +70,10 @@ func loadConfig() Config {
 
 	cfg := Config{}
 
+	// APP_ENV: "dev" buat local development, selain itu dianggap production.
+	// Fitur yang cuma aman buat testing (fake gateway QRIS, simulasi bayar) wajib APP_ENV=dev
+	cfg.AppEnv = envOrConfig("APP_ENV", "production")
+
 	// PORT: OS env > .env > default "8080"
 	if port := os.Getenv("PORT"); port != "" {
 		cfg.Port = port
@@ -63,9 +95,109 @@ func loadConfig() Config {
 		cfg.DBConn = viper.GetString("DB_CONN")
 	}
 
+	// BUSINESS_TIMEZONE: OS env > .env > default "Asia/Jakarta"
+	// dipake buat motong hari/minggu/bulan di laporan
+	if tz := os.Getenv("BUSINESS_TIMEZONE"); tz != "" {
+		cfg.Timezone = tz
+	} else if tz := viper.GetString("BUSINESS_TIMEZONE"); tz != "" {
+		cfg.Timezone = tz
+	} else {
+		cfg.Timezone = "Asia/Jakarta"
+	}
+
+	// LOW_STOCK_WEBHOOK_URL: opsional, kosong = alert cuma ke log
+	if url := os.Getenv("LOW_STOCK_WEBHOOK_URL"); url != "" {
+		cfg.LowStockWebhookURL = url
+	} else {
+		cfg.LowStockWebhookURL = viper.GetString("LOW_STOCK_WEBHOOK_URL")
+	}
+
+	// JWT_SECRET: wajib, minimal 32 byte (HS256), dipake buat sign access token
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		cfg.JWTSecret = secret
+	} else {
+		cfg.JWTSecret = viper.GetString("JWT_SECRET")
+	}
+
+	// ADMIN_USERNAME / ADMIN_PASSWORD: opsional, cuma dipake kalau tabel users masih kosong
+	if username := os.Getenv("ADMIN_USERNAME"); username != "" {
+		cfg.AdminUsername = username
+	} else {
+		cfg.AdminUsername = viper.GetString("ADMIN_USERNAME")
+	}
+	if password := os.Getenv("ADMIN_PASSWORD"); password != "" {
+		cfg.AdminPassword = password
+	} else {
+		cfg.AdminPassword = viper.GetString("ADMIN_PASSWORD")
+	}
+
+	// PAYMENT_GATEWAY: provider QRIS, kosong = QRIS nonaktif. "fake" (in-process) cuma boleh
+	// kalau APP_ENV=dev, dan jadi default di dev biar local development ga perlu set apa-apa
+	defaultGateway := ""
+	if cfg.AppEnv == appEnvDev {
+		defaultGateway = "fake"
+	}
+	cfg.PaymentGateway = envOrConfig("PAYMENT_GATEWAY", defaultGateway)
+
+	// QRIS_WEBHOOK_SECRET: dipake verifikasi signature webhook dari gateway, wajib kalau QRIS aktif
+	if secret := os.Getenv("QRIS_WEBHOOK_SECRET"); secret != "" {
+		cfg.QRISWebhookSecret = secret
+	} else {
+		cfg.QRISWebhookSecret = viper.GetString("QRIS_WEBHOOK_SECRET")
+	}
+
+	// QRIS_STOCK_POLICY: none (default) = stok dipotong pas lunas, reserve = stok dipesan pas QR dibuat
+	if policy := os.Getenv("QRIS_STOCK_POLICY"); policy != "" {
+		cfg.QRISStockPolicy = policy
+	} else if policy := viper.GetString("QRIS_STOCK_POLICY"); policy != "" {
+		cfg.QRISStockPolicy = policy
+	} else {
+		cfg.QRISStockPolicy = models.StockPolicyNone
+	}
+
+	// TAX_RATE / TAX_MODE: tarif PPN dalam persen (default 11) dan apakah harga produk
+	// udah termasuk PPN (inclusive, default) atau PPN ditambahin pas checkout (exclusive)
+	cfg.TaxRate = envOrConfig("TAX_RATE", "11")
+	cfg.TaxMode = envOrConfig("TAX_MODE", tax.ModeInclusive)
+
+	// LOYALTY_EARN_PER / LOYALTY_POINT_VALUE: member dapet 1 poin tiap kelipatan belanja
+	// LOYALTY_EARN_PER rupiah (default 10000, 0 = poin nonaktif), 1 poin bernilai LOYALTY_POINT_VALUE
+	// rupiah pas dipake bayar (default 1)
+	cfg.LoyaltyEarnPer = envOrConfig("LOYALTY_EARN_PER", "10000")
+	cfg.LoyaltyPointValue = envOrConfig("LOYALTY_POINT_VALUE", "1")
+
+	// MIGRATE_ON_START: apply migrasi schema pas server start (default true).
+	// Matiin kalau migrasi mau dijalanin manual lewat `kasir-api migrate up`
+	cfg.MigrateOnStart = envOrConfig("MIGRATE_ON_START", "true")
+
+	// STORE_NAME / STORE_ADDRESS / STORE_NPWP / RECEIPT_FOOTER: kepala & kaki struk
+	cfg.Store = receipt.Store{
+		Name:    envOrConfig("STORE_NAME", "Kasir API"),
+		Address: envOrConfig("STORE_ADDRESS", ""),
+		NPWP:    envOrConfig("STORE_NPWP", ""),
+		Footer:  envOrConfig("RECEIPT_FOOTER", "Terima kasih atas kunjungan Anda"),
+	}
+
 	return cfg
 }
 
+// appEnvDev - nilai APP_ENV buat local development
+const appEnvDev = "dev"
+
+// minJWTSecretLen - secret HS256 minimal sepanjang output SHA-256
+const minJWTSecretLen = 32
+
+// envOrConfig - OS env > .env > default, buat config opsional yang ga butuh fallback khusus
+func envOrConfig(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	if v := viper.GetString(key); v != "" {
+		return v
+	}
+	return def
+}
+
 func main() {
 	config := loadConfig()
 
@@ -77,9 +209,67 @@ func main() {
 
 	// Initialize logger
 	appLogger := logger.New()
+
+	// kasir-api migrate ... - jalanin subcommand lalu keluar, ga start server
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], db, appLogger); err != nil {
+			db.Close()
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if config.JWTSecret == "" {
+		log.Fatal("JWT_SECRET is required")
+	}
+	if len(config.JWTSecret) < minJWTSecretLen {
+		log.Fatalf("JWT_SECRET must be at least %d bytes", minJWTSecretLen)
+	}
 	appLogger.Info("Starting Kasir API", "port", config.Port)
 
+	// Advisory lock di MigrateUp bikin replica lain nunggu sampe migrasi selesai
+	if migrateOnStart, err := strconv.ParseBool(config.MigrateOnStart); err != nil {
+		log.Fatal("Invalid MIGRATE_ON_START:", err)
+	} else if migrateOnStart {
+		if _, err := database.MigrateUp(db, appLogger); err != nil {
+			log.Fatal("Failed to migrate database:", err)
+		}
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		log.Fatal("Invalid BUSINESS_TIMEZONE:", err)
+	}
+
+	taxConfig, err := tax.ParseConfig(config.TaxRate, config.TaxMode)
+	if err != nil {
+		log.Fatal("Invalid tax config:", err)
+	}
+	loyaltyConfig, err := loyalty.ParseConfig(config.LoyaltyEarnPer, config.LoyaltyPointValue)
+	if err != nil {
+		log.Fatal("Invalid loyalty config:", err)
+	}
+
+	// Notifier low stock: selalu ke log, plus webhook kalau LOW_STOCK_WEBHOOK_URL diisi
+	var stockNotifier alert.Notifier = alert.NewLogNotifier(appLogger)
+	if config.LowStockWebhookURL != "" {
+		stockNotifier = alert.Multi{stockNotifier, alert.NewWebhookNotifier(config.LowStockWebhookURL, appLogger)}
+	}
+
+	// Access token umurnya pendek, refresh token 7 hari
+	tokenManager := auth.NewTokenManager(config.JWTSecret, 15*time.Minute)
+
 	// Dep injection
+	userRepo := repositories.NewUserRepository(db, appLogger)
+	userService := services.NewUserService(userRepo, appLogger)
+	authService := services.NewAuthService(userRepo, tokenManager, 7*24*time.Hour, appLogger)
+	authHandler := handlers.NewAuthHandler(authService, userService, appLogger)
+	userHandler := handlers.NewUserHandler(userService, appLogger)
+
+	if err := userService.EnsureAdmin(config.AdminUsername, config.AdminPassword); err != nil {
+		log.Fatal("Failed to create bootstrap admin:", err)
+	}
+
 	productRepo := repositories.NewProductRepository(db, appLogger)
 	productService := services.NewProductService(productRepo, appLogger)
 	productHandler := handlers.NewProductHandler(productService, appLogger)
@@ -88,6 +278,81 @@ func main() {
 	categoryService := services.NewCategoryService(categoryRepo, appLogger)
 	categoryHandler := handlers.NewCategoryHandler(categoryService, appLogger)
 
+	promotionRepo := repositories.NewPromotionRepository(db, appLogger)
+	promotionService := services.NewPromotionService(promotionRepo, taxConfig, location, appLogger)
+	promotionHandler := handlers.NewPromotionHandler(promotionService, appLogger)
+
+	transactionRepo := repositories.NewTransactionRepository(db, taxConfig, loyaltyConfig, location, appLogger)
+	transactionService := services.NewTransactionService(transactionRepo, stockNotifier, appLogger)
+	transactionHandler := handlers.NewTransactionHandler(transactionService, appLogger)
+	receiptService := services.NewReceiptService(transactionRepo, config.Store, location, appLogger)
+	receiptHandler := handlers.NewReceiptHandler(receiptService, appLogger)
+
+	customerRepo := repositories.NewCustomerRepository(db, appLogger)
+	customerService := services.NewCustomerService(customerRepo, transactionRepo, loyaltyConfig, appLogger)
+	customerHandler := handlers.NewCustomerHandler(customerService, appLogger)
+
+	stockRepo := repositories.NewStockRepository(db, appLogger)
+	stockService := services.NewStockService(stockRepo, stockNotifier, appLogger)
+	stockHandler := handlers.NewStockHandler(stockService, appLogger)
+
+	stockOpnameRepo := repositories.NewStockOpnameRepository(db, appLogger)
+	stockOpnameService := services.NewStockOpnameService(stockOpnameRepo, stockNotifier, appLogger)
+	stockOpnameHandler := handlers.NewStockOpnameHandler(stockOpnameService, appLogger)
+
+	supplierRepo := repositories.NewSupplierRepository(db, appLogger)
+	supplierService := services.NewSupplierService(supplierRepo, appLogger)
+	supplierHandler := handlers.NewSupplierHandler(supplierService, appLogger)
+
+	purchaseOrderRepo := repositories.NewPurchaseOrderRepository(db, appLogger)
+	purchaseOrderService := services.NewPurchaseOrderService(purchaseOrderRepo, appLogger)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(purchaseOrderService, appLogger)
+
+	reportRepo := repositories.NewReportRepository(db, appLogger)
+	reportService := services.NewReportService(reportRepo, taxConfig, location, appLogger)
+	reportHandler := handlers.NewReportHandler(reportService, appLogger)
+
+	shiftRepo := repositories.NewShiftRepository(db, appLogger)
+	shiftService := services.NewShiftService(shiftRepo, appLogger)
+	shiftHandler := handlers.NewShiftHandler(shiftService, appLogger)
+
+	// Gateway QRIS. Baru ada fake; provider beneran tinggal implement payment.PaymentGateway.
+	// Webhook itu endpoint public, jadi secret kosong (HMAC key kosong) ga boleh
+	var paymentGateway payment.PaymentGateway
+	var fakeGateway *payment.FakeGateway
+	switch config.PaymentGateway {
+	case "":
+		appLogger.Warn("PAYMENT_GATEWAY not set, QRIS endpoints are disabled")
+	case "fake":
+		if config.AppEnv != appEnvDev {
+			log.Fatal("PAYMENT_GATEWAY=fake is only allowed with APP_ENV=dev")
+		}
+		fakeGateway = payment.NewFakeGateway(config.QRISWebhookSecret)
+		paymentGateway = fakeGateway
+		appLogger.Warn("Using fake QRIS gateway, do not use in production")
+	default:
+		log.Fatal("Unknown PAYMENT_GATEWAY: ", config.PaymentGateway)
+	}
+	if paymentGateway != nil && config.QRISWebhookSecret == "" {
+		log.Fatal("QRIS_WEBHOOK_SECRET is required when PAYMENT_GATEWAY is set")
+	}
+
+	var pendingSaleHandler *handlers.PendingSaleHandler
+	if paymentGateway != nil {
+		pendingSaleRepo := repositories.NewPendingSaleRepository(db, taxConfig, location, appLogger)
+		pendingSaleService, err := services.NewPendingSaleService(pendingSaleRepo, paymentGateway, config.QRISStockPolicy, stockNotifier, appLogger)
+		if err != nil {
+			log.Fatal("Invalid QRIS config:", err)
+		}
+		pendingSaleHandler = handlers.NewPendingSaleHandler(pendingSaleService, fakeGateway, appLogger)
+		// sale QRIS yang lewat batas waktu di-expire di background
+		go pendingSaleService.RunSweeper(context.Background(), time.Minute)
+	}
+
+	auditRepo := repositories.NewAuditRepository(db, appLogger)
+	auditService := services.NewAuditService(auditRepo, appLogger)
+	auditHandler := handlers.NewAuditHandler(auditService, appLogger)
+
 	// Root endpoint
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/" {
@@ -100,12 +365,25 @@ func main() {
 			"version":   "v0.1",
 			"developer": "👨‍💻 benedictuserwdev@gmail.com",
 			"endpoints": map[string]interface{}{
+				"auth": map[string]string{
+					"login":   "POST /api/auth/login",
+					"refresh": "POST /api/auth/refresh",
+					"logout":  "POST /api/auth/logout",
+					"me":      "GET /api/auth/me",
+				},
+				"users": map[string]string{
+					"get_all": "GET /api/users",
+					"create":  "POST /api/users",
+				},
 				"produk": map[string]string{
-					"get_all":   "GET /api/produk",
-					"get_by_id": "GET /api/produk/:id",
-					"create":    "POST /api/produk",
-					"update":    "PUT /api/produk/:id",
-					"delete":    "DELETE /api/produk/:id",
+					"get_all":       "GET /api/produk",
+					"get_by_id":     "GET /api/produk/:id",
+					"create":        "POST /api/produk",
+					"update":        "PUT /api/produk/:id",
+					"delete":        "DELETE /api/produk/:id",
+					"stock_history": "GET /api/produk/:id/stock-history",
+					"adjust_stock":  "POST /api/produk/:id/stock",
+					"low_stock":     "GET /api/produk/low-stock",
 				},
 				"categories": map[string]string{
 					"get_all":   "GET /categories",
@@ -114,6 +392,73 @@ func main() {
 					"update":    "PUT /categories/:id",
 					"delete":    "DELETE /categories/:id",
 				},
+				"promotions": map[string]string{
+					"get_all":    "GET /api/promotions?active=true",
+					"get_by_id":  "GET /api/promotions/:id",
+					"create":     "POST /api/promotions",
+					"update":     "PUT /api/promotions/:id",
+					"delete":     "DELETE /api/promotions/:id",
+					"cart_price": "POST /api/cart/price {items}",
+				},
+				"transactions": map[string]string{
+					"checkout":  "POST /api/transactions {items, customer_phone, payments: [{method, amount, reference}]}",
+					"get_all":   "GET /api/transactions?from=&to=&cashier=&payment_method=&customer_id=&page=&limit=",
+					"get_by_id": "GET /api/transactions/:id",
+					"refund":    "POST /api/transactions/:id/refund",
+					"receipt":   "GET /api/transactions/:id/receipt?format=text|escpos|pdf&paper=58|80",
+				},
+				"customers": map[string]string{
+					"get_all":      "GET /api/customers?q=",
+					"get_by_id":    "GET /api/customers/:id",
+					"create":       "POST /api/customers {phone, name}",
+					"update":       "PUT /api/customers/:id",
+					"points":       "GET /api/customers/:id/points?limit=",
+					"transactions": "GET /api/customers/:id/transactions?page=&limit=",
+				},
+				"qris": map[string]string{
+					"create":  "POST /api/qris/sales {items}",
+					"get":     "GET /api/qris/sales/:id",
+					"cancel":  "POST /api/qris/sales/:id/cancel",
+					"webhook": "POST /api/payments/qris/webhook",
+				},
+				"shifts": map[string]string{
+					"get_all":   "GET /api/shifts?status=&user_id=",
+					"open":      "POST /api/shifts",
+					"current":   "GET /api/shifts/current",
+					"get_by_id": "GET /api/shifts/:id",
+					"cash":      "POST /api/shifts/:id/cash",
+					"close":     "POST /api/shifts/:id/close",
+				},
+				"stock_opname": map[string]string{
+					"get_all":       "GET /api/stock-opname?status=",
+					"get_by_id":     "GET /api/stock-opname/:id",
+					"create":        "POST /api/stock-opname",
+					"submit_counts": "POST /api/stock-opname/:id/counts",
+					"post":          "POST /api/stock-opname/:id/post",
+					"cancel":        "POST /api/stock-opname/:id/cancel",
+				},
+				"suppliers": map[string]string{
+					"get_all":   "GET /api/suppliers",
+					"get_by_id": "GET /api/suppliers/:id",
+					"create":    "POST /api/suppliers",
+					"update":    "PUT /api/suppliers/:id",
+				},
+				"purchase_orders": map[string]string{
+					"get_all":   "GET /api/purchase-orders?status=&supplier_id=",
+					"get_by_id": "GET /api/purchase-orders/:id",
+					"create":    "POST /api/purchase-orders",
+					"send":      "POST /api/purchase-orders/:id/send",
+					"receive":   "POST /api/purchase-orders/:id/receive",
+				},
+				"reports": map[string]string{
+					"sales":         "GET /api/reports/sales?from=&to=&group_by=day|week|month",
+					"top_products":  "GET /api/reports/products/top?from=&to=&sort=quantity|revenue&limit=&category_id=&by_category=",
+					"slow_products": "GET /api/reports/products/slow?days=&max_quantity=&sort=&limit=&category_id=&by_category=",
+					"margin":        "GET /api/reports/margin?from=&to=&group_by=product|category|day",
+					"payments":      "GET /api/reports/payments?from=&to=",
+					"tax":           "GET /api/reports/tax?from=&to=&group_by=day|week|month",
+				},
+				"audit":  "GET /api/audit?entity_type=&entity_id=&actor=&from=&to=&page=&limit=",
 				"health": "GET /health",
 			},
 			"status": "✅ Running",
@@ -136,21 +481,100 @@ func main() {
 		})
 	})
 
+	// Auth & user endpoints
+	http.HandleFunc("/api/auth/login", authHandler.Login)
+	http.HandleFunc("/api/auth/refresh", authHandler.Refresh)
+	http.HandleFunc("/api/auth/logout", authHandler.Logout)
+	http.HandleFunc("/api/auth/me", authHandler.Me)
+	http.HandleFunc("/api/users", userHandler.HandleUsers)
+
 	// Produk endpoints
 	http.HandleFunc("/api/produk", productHandler.HandleProducts)
 	http.HandleFunc("/api/produk/", productHandler.HandleProductByID)
+	http.HandleFunc("/api/produk/low-stock", productHandler.GetLowStock)
+	http.HandleFunc("/api/produk/{id}/stock-history", stockHandler.HandleStockHistory)
+	http.HandleFunc("/api/produk/{id}/stock", stockHandler.HandleStockAdjustment)
 
 	// Categories endpoints
 	http.HandleFunc("/categories", categoryHandler.HandleCategories)
 	http.HandleFunc("/categories/", categoryHandler.HandleCategoryByID)
 
+	// Promo & hitung harga keranjang
+	http.HandleFunc("/api/promotions", promotionHandler.HandlePromotions)
+	http.HandleFunc("/api/promotions/{id}", promotionHandler.HandlePromotionByID)
+	http.HandleFunc("/api/cart/price", promotionHandler.HandleCartPrice)
+
+	// Transaction endpoints
+	http.HandleFunc("/api/transactions", transactionHandler.HandleTransactions)
+	http.HandleFunc("/api/transactions/", transactionHandler.HandleTransactionByID)
+	http.HandleFunc("/api/transactions/{id}/receipt", receiptHandler.HandleReceipt)
+
+	// Member & poin
+	http.HandleFunc("/api/customers", customerHandler.HandleCustomers)
+	http.HandleFunc("/api/customers/{id}", customerHandler.HandleCustomerByID)
+	http.HandleFunc("/api/customers/{id}/points", customerHandler.HandlePoints)
+	http.HandleFunc("/api/customers/{id}/transactions", customerHandler.HandleTransactions)
+
+	// QRIS dinamis endpoints, cuma ada kalau PAYMENT_GATEWAY diisi.
+	// Webhook public, diverifikasi lewat signature gateway. Simulasi bayar cuma di fake gateway (APP_ENV=dev)
+	if pendingSaleHandler != nil {
+		http.HandleFunc("/api/qris/sales", pendingSaleHandler.HandleSales)
+		http.HandleFunc("/api/qris/sales/{id}", pendingSaleHandler.HandleSaleByID)
+		http.HandleFunc("/api/qris/sales/{id}/cancel", pendingSaleHandler.HandleCancel)
+		http.HandleFunc("/api/payments/qris/webhook", pendingSaleHandler.HandleWebhook)
+	}
+	if fakeGateway != nil {
+		http.HandleFunc("/api/dev/qris/{charge_id}/simulate", pendingSaleHandler.HandleSimulate)
+	}
+
+	// Shift kasir endpoints
+	http.HandleFunc("/api/shifts", shiftHandler.HandleShifts)
+	http.HandleFunc("/api/shifts/current", shiftHandler.HandleCurrent)
+	http.HandleFunc("/api/shifts/{id}", shiftHandler.HandleShiftByID)
+	http.HandleFunc("/api/shifts/{id}/cash", shiftHandler.HandleCashMovement)
+	http.HandleFunc("/api/shifts/{id}/close", shiftHandler.HandleClose)
+
+	// Stock opname endpoints
+	http.HandleFunc("/api/stock-opname", stockOpnameHandler.HandleStockOpnames)
+	http.HandleFunc("/api/stock-opname/{id}", stockOpnameHandler.HandleStockOpnameByID)
+	http.HandleFunc("/api/stock-opname/{id}/counts", stockOpnameHandler.HandleCounts)
+	http.HandleFunc("/api/stock-opname/{id}/post", stockOpnameHandler.HandlePost)
+	http.HandleFunc("/api/stock-opname/{id}/cancel", stockOpnameHandler.HandleCancel)
+
+	// Supplier & purchase order endpoints
+	http.HandleFunc("/api/suppliers", supplierHandler.HandleSuppliers)
+	http.HandleFunc("/api/suppliers/{id}", supplierHandler.HandleSupplierByID)
+	http.HandleFunc("/api/purchase-orders", purchaseOrderHandler.HandlePurchaseOrders)
+	http.HandleFunc("/api/purchase-orders/{id}", purchaseOrderHandler.HandlePurchaseOrderByID)
+	http.HandleFunc("/api/purchase-orders/{id}/send", purchaseOrderHandler.HandleSend)
+	http.HandleFunc("/api/purchase-orders/{id}/receive", purchaseOrderHandler.HandleReceive)
+
+	// Report endpoints
+	http.HandleFunc("/api/reports/sales", reportHandler.HandleSalesReport)
+	http.HandleFunc("/api/reports/products/top", reportHandler.HandleTopProducts)
+	http.HandleFunc("/api/reports/products/slow", reportHandler.HandleSlowProducts)
+	http.HandleFunc("/api/reports/margin", reportHandler.HandleMarginReport)
+	http.HandleFunc("/api/reports/payments", reportHandler.HandlePaymentReport)
+	http.HandleFunc("/api/reports/tax", reportHandler.HandleTaxReport)
+
+	// Audit log
+	http.HandleFunc("/api/audit", auditHandler.HandleAuditLogs)
+
 	// Start server
 	addr := "0.0.0.0:" + config.Port
 	fmt.Println("Server running at http://" + addr)
 	appLogger.Info("Server started successfully", "address", addr)
 
-	if err := http.ListenAndServe(addr, nil); err != nil {
+	// Semua endpoint wajib login, kecuali root, health check, login/refresh dan webhook QRIS.
+	// Setelah itu role dicek ke tabel permissions (permissions.go).
+	// Request ID dipasang paling luar biar kebawa sampai audit log
+	publicPaths := []string{"/", "/health", "/api/auth/login", "/api/auth/refresh", "/api/payments/qris/webhook"}
+	server := requestid.Middleware(auth.Middleware(tokenManager, publicPaths, appLogger)(
+		auth.Authorize(permissions, appLogger)(http.DefaultServeMux),
+	))
+
+	if err := http.ListenAndServe(addr, server); err != nil {
 		appLogger.Error("Error starting server", "error", err)
 		log.Fatal("Error starting server:", err)
 	}
-}
\ No newline at end of file
+}
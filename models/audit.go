@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditActor - siapa & dari mana perubahan dilakukan, diisi handler dari request
+type AuditActor struct {
+	UserID    int
+	Username  string
+	RequestID string
+	IP        string
+}
+
+// AuditLog - Before kosong buat create, After kosong buat delete
+type AuditLog struct {
+	ID         int             `json:"id"`
+	ActorID    *int            `json:"actor_id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int             `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+	IP         string          `json:"ip"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type AuditFilter struct {
+	EntityType string
+	EntityID   int
+	Actor      string
+	From       time.Time
+	To         time.Time
+	Page       int
+	Limit      int
+}
+
+type AuditLogList struct {
+	Data  []AuditLog `json:"data"`
+	Page  int        `json:"page"`
+	Limit int        `json:"limit"`
+	Total int        `json:"total"`
+}
@@ -4,5 +4,7 @@ type Category struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// TaxExempt - semua produk di kategori ini bebas PPN
+	TaxExempt bool `json:"tax_exempt"`
 
 }
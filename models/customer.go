@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Alasan mutasi poin member
+const (
+	PointReasonEarn   = "earn"
+	PointReasonRedeem = "redeem"
+	// PointReasonRefund - koreksi poin karena barangnya di-refund: poin hasil belanja ditarik (negatif),
+	// poin yang dulu dipake bayar dibalikin (positif)
+	PointReasonRefund = "refund"
+)
+
+// Customer - member toko, dikenali dari nomor HP (unik, disimpan format 08xxx).
+// Points itu saldo sekarang, riwayatnya ada di ledger customer_points
+type Customer struct {
+	ID        int       `json:"id"`
+	Phone     string    `json:"phone"`
+	Name      string    `json:"name"`
+	Points    int       `json:"points"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PointEntry - satu mutasi poin. Points plus = nambah, minus = kepake/ditarik,
+// Balance = saldo setelah mutasi ini
+type PointEntry struct {
+	ID            int       `json:"id"`
+	CustomerID    int       `json:"customer_id"`
+	Reason        string    `json:"reason"`
+	Points        int       `json:"points"`
+	Balance       int       `json:"balance"`
+	TransactionID int       `json:"transaction_id,omitempty"`
+	RefundID      int       `json:"refund_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// CustomerPoints - saldo poin + nilai rupiahnya kalau dipake bayar, plus mutasi terbaru
+type CustomerPoints struct {
+	Customer   Customer     `json:"customer"`
+	Balance    int          `json:"balance"`
+	PointValue int          `json:"point_value"`
+	Value      int          `json:"value"`
+	Entries    []PointEntry `json:"entries"`
+}
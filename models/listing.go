@@ -0,0 +1,43 @@
+package models
+
+// ListParams - paging keyset yang dipake bareng list produk & kategori.
+// Sort mentah dari query ("price,-name"), divalidasi di repo pake whitelist kolom.
+// Cursor opaque dari NextCursor halaman sebelumnya, cuma valid buat Sort yang sama
+type ListParams struct {
+	Sort         string
+	Limit        int
+	Cursor       string
+	IncludeTotal bool
+}
+
+// ProductFilter - query GET /api/produk. Pointer nil / 0 berarti ga difilter
+type ProductFilter struct {
+	ListParams
+	CategoryID int
+	MinPrice   *int
+	MaxPrice   *int
+	InStock    *bool
+}
+
+// CategoryFilter - query GET /categories
+type CategoryFilter struct {
+	ListParams
+	TaxExempt *bool
+}
+
+// ProductList - response GET /api/produk. NextCursor kosong = udah halaman terakhir.
+// Total cuma diisi kalau diminta (include_total=true), COUNT(*) di katalog gede ga murah
+type ProductList struct {
+	Data       []Product `json:"data"`
+	Limit      int       `json:"limit"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Total      *int      `json:"total,omitempty"`
+}
+
+// CategoryList - response GET /categories, sama kayak ProductList
+type CategoryList struct {
+	Data       []Category `json:"data"`
+	Limit      int        `json:"limit"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+	Total      *int       `json:"total,omitempty"`
+}
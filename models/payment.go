@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Metode pembayaran. PaymentSplit cuma dipake di header transaksi
+// kalau pembayarannya campur lebih dari satu metode
+const (
+	PaymentCash        = "cash"
+	PaymentDebit       = "debit"
+	PaymentCredit      = "credit"
+	PaymentQRIS        = "qris"
+	PaymentEWallet     = "ewallet"
+	PaymentStoreCredit = "store_credit"
+	// PaymentPoints - bayar pake poin member, wajib ada customer di transaksi
+	PaymentPoints = "points"
+	PaymentSplit  = "split"
+)
+
+// Payment - satu baris pembayaran di transaksi. Amount itu yang masuk ke penjualan,
+// Tendered yang diserahin customer. Selisihnya (kembalian) cuma mungkin di cash
+type Payment struct {
+	ID            int    `json:"id"`
+	TransactionID int    `json:"transaction_id"`
+	Method        string `json:"method"`
+	Amount        int    `json:"amount"`
+	Tendered      int    `json:"tendered"`
+	Reference     string `json:"reference,omitempty"`
+}
+
+// PaymentInput - pembayaran dari client. Reference wajib buat kartu debit/kredit
+// (approval code EDC) dan store credit (nomor nota kredit)
+type PaymentInput struct {
+	Method    string `json:"method"`
+	Amount    int    `json:"amount"`
+	Reference string `json:"reference"`
+}
+
+// PaymentMethodTotal - total per metode, buat rekonsiliasi shift & laporan
+type PaymentMethodTotal struct {
+	Method       string `json:"method"`
+	Transactions int    `json:"transactions"`
+	Amount       int    `json:"amount"`
+}
+
+type PaymentReport struct {
+	From     time.Time            `json:"from"`
+	To       time.Time            `json:"to"`
+	Timezone string               `json:"timezone"`
+	Methods  []PaymentMethodTotal `json:"methods"`
+	Total    int                  `json:"total"`
+}
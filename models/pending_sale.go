@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Status sale QRIS. Alurnya cuma pending -> paid / expired / failed,
+// status selain pending udah final dan ga bisa pindah lagi
+const (
+	SaleStatusPending = "pending"
+	SaleStatusPaid    = "paid"
+	SaleStatusExpired = "expired"
+	SaleStatusFailed  = "failed"
+)
+
+// Kebijakan stok buat sale QRIS yang belum dibayar
+const (
+	// StockPolicyNone - stok baru dipotong pas pembayaran dikonfirmasi
+	StockPolicyNone = "none"
+	// StockPolicyReserve - stok langsung dipesan pas QR dibuat, dilepas lagi kalau expired/gagal
+	StockPolicyReserve = "reserve"
+)
+
+// PendingSale - penjualan yang nunggu pembayaran QRIS dinamis. Harga & nama produk
+// di-snapshot pas QR dibuat; Transaction baru dibuat setelah gateway bilang paid
+type PendingSale struct {
+	ID            int               `json:"id"`
+	UserID        int               `json:"user_id"`
+	Cashier       string            `json:"cashier"`
+	ShiftID       int               `json:"shift_id,omitempty"`
+	Status        string            `json:"status"`
+	TotalAmount   int               `json:"total_amount"`
+	StockReserved bool              `json:"stock_reserved"`
+	Gateway       string            `json:"gateway"`
+	ChargeID      string            `json:"charge_id,omitempty"`
+	QRString      string            `json:"qr_string,omitempty"`
+	ExpiresAt     time.Time         `json:"expires_at"`
+	TransactionID int               `json:"transaction_id,omitempty"`
+	Note          string            `json:"note,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	SettledAt     *time.Time        `json:"settled_at,omitempty"`
+	Items         []PendingSaleItem `json:"items"`
+	// Transaction - diisi pas sale baru aja lunas, biar client bisa langsung cetak struk
+	Transaction *Transaction `json:"transaction,omitempty"`
+}
+
+// PendingSaleItem - harga & diskon promo di-snapshot pas QR dibuat,
+// dipake lagi pas sale lunas biar total transaksi = nominal QR
+type PendingSaleItem struct {
+	ID            int    `json:"id"`
+	PendingSaleID int    `json:"pending_sale_id"`
+	ProductID     int    `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	Price         int    `json:"price"`
+	Quantity      int    `json:"quantity"`
+	Subtotal      int    `json:"subtotal"`
+	Discount      int    `json:"discount"`
+	CartDiscount  int    `json:"cart_discount"`
+}
+
+// PendingSaleRequest - keranjang buat bayar QRIS. UserID & Cashier diisi handler dari token
+type PendingSaleRequest struct {
+	UserID  int            `json:"-"`
+	Cashier string         `json:"-"`
+	Items   []CheckoutItem `json:"items"`
+}
@@ -1,11 +1,62 @@
 package models
+
+import (
+	"math"
+	"time"
+)
+
 type Product struct {
-	ID int `json:"id"`
-	Name string `json:"name"`
-	Price int	`json:"price"`
-	Stock int `json:"stock"`
-	CategoryID int `json:"category_id"`
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Price        int    `json:"price"`
+	CostPrice    int    `json:"cost_price"`
+	Stock        int    `json:"stock"`
+	MinStock     int    `json:"min_stock"`
+	ReorderQty   int    `json:"reorder_qty"`
+	CategoryID   int    `json:"category_id"`
 	CategoryName string `json:"category_name"`
+	// TaxExempt - produk bebas PPN (misal sembako). Produk juga bebas PPN kalau kategorinya TaxExempt
+	TaxExempt bool `json:"tax_exempt"`
+	// Margin & MarginPercent dihitung dari Price - CostPrice, ga disimpen di DB.
+	// CostPrice, Margin & MarginPercent cuma dikirim ke owner (dibuang di handler)
+	Margin        int     `json:"margin"`
+	MarginPercent float64 `json:"margin_percent"`
+}
+
+//category name itu buat hasil dari join
+
+// ProductUpdate - body PUT /api/produk/{id}. CostPrice pointer biar bisa bedain ga dikirim (nil,
+// harga pokok lama dipertahankan biar moving average hasil penerimaan barang ga ke-reset) sama di-set 0.
+// Hasil update ditulis balik ke Product
+type ProductUpdate struct {
+	Product
+	CostPrice *int `json:"cost_price"`
 }
 
-//category name itu buat hasil dari join 
\ No newline at end of file
+// CalculateMargin - isi Margin & MarginPercent dari harga jual dan harga pokok
+func (p *Product) CalculateMargin() {
+	p.Margin = p.Price - p.CostPrice
+	p.MarginPercent = 0
+	if p.Price > 0 {
+		p.MarginPercent = math.Round(float64(p.Margin)*10000/float64(p.Price)) / 100
+	}
+}
+
+// LowStockAlert - event pas stok turun sampai/di bawah MinStock.
+// Cuma dikirim sekali pas nyebrang threshold, bukan tiap transaksi
+type LowStockAlert struct {
+	ProductID   int       `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	Stock       int       `json:"stock"`
+	MinStock    int       `json:"min_stock"`
+	ReorderQty  int       `json:"reorder_qty"`
+	Reason      string    `json:"reason"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// LowStockGroup - hasil GET /api/produk/low-stock, dikelompokin per kategori
+type LowStockGroup struct {
+	CategoryID   int       `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	Products     []Product `json:"products"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// Jenis aturan promo
+const (
+	// PromoBuyXGetY - beli BuyQty gratis FreeQty, dihitung per baris produk target ("beli 2 gratis 1")
+	PromoBuyXGetY = "buy_x_get_y"
+	// PromoPercent - potongan Percent % buat produk/kategori target, tanpa target = semua produk
+	PromoPercent = "percent"
+	// PromoCartAmount - potongan Amount rupiah kalau belanja minimal MinSubtotal
+	PromoCartAmount = "cart_amount"
+	// PromoBundle - BundleQty unit tiap produk target (ProductIDs) jadi satu paket seharga BundlePrice
+	PromoBundle = "bundle"
+)
+
+// Promotion - satu aturan promo. Berlaku kalau Active, waktu sekarang ada di [StartsAt, EndsAt)
+// dan (kalau Days diisi) hari ini termasuk Days (0 = Minggu ... 6 = Sabtu, timezone bisnis).
+// Promo dengan Priority lebih besar dievaluasi duluan. Stackable = boleh digabung sama promo lain
+// di produk yang sama; promo yang ga stackable cuma kena di baris yang belum dapet diskon
+// dan ngunci baris itu dari promo berikutnya
+type Promotion struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	Priority    int        `json:"priority"`
+	Stackable   bool       `json:"stackable"`
+	Active      bool       `json:"active"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+	Days        []int      `json:"days,omitempty"`
+	ProductIDs  []int      `json:"product_ids,omitempty"`
+	CategoryIDs []int      `json:"category_ids,omitempty"`
+	BuyQty      int        `json:"buy_qty,omitempty"`
+	FreeQty     int        `json:"free_qty,omitempty"`
+	Percent     int        `json:"percent,omitempty"`
+	Amount      int        `json:"amount,omitempty"`
+	MinSubtotal int        `json:"min_subtotal,omitempty"`
+	BundleQty   int        `json:"bundle_qty,omitempty"`
+	BundlePrice int        `json:"bundle_price,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// AppliedPromotion - promo yang kena ke satu baris atau ke keranjang
+type AppliedPromotion struct {
+	PromotionID int    `json:"promotion_id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Discount    int    `json:"discount"`
+	// FreeQty - jumlah unit gratis, cuma buat buy_x_get_y
+	FreeQty int `json:"free_qty,omitempty"`
+}
+
+// CartRequest - keranjang yang mau dihitung harganya
+type CartRequest struct {
+	Items []CheckoutItem `json:"items"`
+}
+
+// CartLine - satu baris hasil hitung. Total = Subtotal - Discount.
+// CartDiscount itu bagian diskon keranjang yang dialokasiin ke baris ini (buat dasar pajak),
+// Net + Tax = Gross = Total - CartDiscount (inclusive) atau Total - CartDiscount + Tax (exclusive)
+type CartLine struct {
+	ProductID    int                `json:"product_id"`
+	ProductName  string             `json:"product_name"`
+	CategoryID   int                `json:"category_id"`
+	Price        int                `json:"price"`
+	Quantity     int                `json:"quantity"`
+	Subtotal     int                `json:"subtotal"`
+	Discount     int                `json:"discount"`
+	Total        int                `json:"total"`
+	Promotions   []AppliedPromotion `json:"promotions"`
+	CartDiscount int                `json:"cart_discount"`
+	TaxExempt    bool               `json:"tax_exempt"`
+	Net          int                `json:"net"`
+	Tax          int                `json:"tax"`
+	Gross        int                `json:"gross"`
+}
+
+// CartPrice - hasil hitung keranjang. Total itu harga setelah diskon sebelum pajak dihitung,
+// yang dibayar customer = Gross
+type CartPrice struct {
+	Lines          []CartLine         `json:"lines"`
+	Subtotal       int                `json:"subtotal"`
+	LineDiscount   int                `json:"line_discount"`
+	CartDiscount   int                `json:"cart_discount"`
+	Total          int                `json:"total"`
+	CartPromotions []AppliedPromotion `json:"cart_promotions"`
+	TaxMode        string             `json:"tax_mode"`
+	TaxRate        float64            `json:"tax_rate"`
+	Net            int                `json:"net"`
+	Tax            int                `json:"tax"`
+	Gross          int                `json:"gross"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// Status PO: draft -> sent -> partial -> received. Barang cuma bisa diterima
+// kalau status sent atau partial
+const (
+	POStatusDraft     = "draft"
+	POStatusSent      = "sent"
+	POStatusPartial   = "partial"
+	POStatusReceived  = "received"
+	POStatusCancelled = "cancelled"
+)
+
+type PurchaseOrder struct {
+	ID           int                 `json:"id"`
+	SupplierID   int                 `json:"supplier_id"`
+	SupplierName string              `json:"supplier_name"`
+	Status       string              `json:"status"`
+	Note         string              `json:"note"`
+	TotalCost    int                 `json:"total_cost"`
+	CreatedBy    string              `json:"created_by"`
+	CreatedAt    time.Time           `json:"created_at"`
+	SentAt       *time.Time          `json:"sent_at"`
+	Items        []PurchaseOrderItem `json:"items,omitempty"`
+	Receipts     []GoodsReceipt      `json:"receipts,omitempty"`
+}
+
+// PurchaseOrderItem - UnitCost di sini harga yang disepakati pas order,
+// harga aktual per penerimaan dicatat di GoodsReceiptItem
+type PurchaseOrderItem struct {
+	ID               int    `json:"id"`
+	PurchaseOrderID  int    `json:"purchase_order_id"`
+	ProductID        int    `json:"product_id"`
+	ProductName      string `json:"product_name"`
+	QuantityOrdered  int    `json:"quantity_ordered"`
+	QuantityReceived int    `json:"quantity_received"`
+	UnitCost         int    `json:"unit_cost"`
+}
+
+type GoodsReceipt struct {
+	ID              int                `json:"id"`
+	PurchaseOrderID int                `json:"purchase_order_id"`
+	Note            string             `json:"note"`
+	ReceivedBy      string             `json:"received_by"`
+	CreatedAt       time.Time          `json:"created_at"`
+	Items           []GoodsReceiptItem `json:"items"`
+}
+
+type GoodsReceiptItem struct {
+	ID                  int `json:"id"`
+	GoodsReceiptID      int `json:"goods_receipt_id"`
+	PurchaseOrderItemID int `json:"purchase_order_item_id"`
+	ProductID           int `json:"product_id"`
+	Quantity            int `json:"quantity"`
+	UnitCost            int `json:"unit_cost"`
+}
+
+type PurchaseOrderLine struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+	UnitCost  int `json:"unit_cost"`
+}
+
+type CreatePurchaseOrderRequest struct {
+	SupplierID int                 `json:"supplier_id"`
+	Note       string              `json:"note"`
+	User       string              `json:"user"`
+	Items      []PurchaseOrderLine `json:"items"`
+}
+
+// ReceiveGoodsRequest - UnitCost 0 artinya pake unit_cost dari PO
+type ReceiveGoodsRequest struct {
+	Note  string              `json:"note"`
+	User  string              `json:"user"`
+	Items []PurchaseOrderLine `json:"items"`
+}
@@ -0,0 +1,115 @@
+package models
+
+import "time"
+
+// SalesReportRow - satu bucket (hari/minggu/bulan). Refunds dan RefundCount
+// dihitung dari tanggal refund-nya, bukan tanggal transaksi asal.
+// Refunds bernilai negatif biar NetRevenue = GrossRevenue + Refunds.
+// Semua nominal tanpa PPN (DPP), PPN-nya ada di TaxReportRow
+type SalesReportRow struct {
+	Period         string  `json:"period"`
+	Transactions   int     `json:"transactions"`
+	GrossRevenue   int     `json:"gross_revenue"`
+	ItemsSold      int     `json:"items_sold"`
+	AvgBasketValue int     `json:"avg_basket_value"`
+	AvgBasketItems float64 `json:"avg_basket_items"`
+	Refunds        int     `json:"refunds"`
+	RefundCount    int     `json:"refund_count"`
+	NetRevenue     int     `json:"net_revenue"`
+	// COGS = HPP dari cost_price yang di-snapshot per baris, udah dikurangin refund
+	COGS           int     `json:"cogs"`
+	GrossProfit    int     `json:"gross_profit"`
+	GrossMarginPct float64 `json:"gross_margin_pct"`
+}
+
+type SalesReport struct {
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	GroupBy  string           `json:"group_by"`
+	Timezone string           `json:"timezone"`
+	Buckets  []SalesReportRow `json:"buckets"`
+	Totals   SalesReportRow   `json:"totals"`
+}
+
+// ProductSales - satu baris ranking produk. QuantitySold dan Revenue udah
+// dikurangin refund dari item yang terjual di window yang sama. Revenue tanpa PPN (DPP)
+type ProductSales struct {
+	Rank         int        `json:"rank"`
+	ProductID    int        `json:"product_id"`
+	ProductName  string     `json:"product_name"`
+	CategoryID   int        `json:"category_id"`
+	CategoryName string     `json:"category_name"`
+	Stock        int        `json:"stock"`
+	QuantitySold int        `json:"quantity_sold"`
+	Revenue      int        `json:"revenue"`
+	LastSoldAt   *time.Time `json:"last_sold_at"`
+}
+
+// ProductSalesFilter - Sort "quantity" atau "revenue", ByCategory = ranking per kategori
+type ProductSalesFilter struct {
+	From       time.Time
+	To         time.Time
+	Sort       string
+	CategoryID int
+	ByCategory bool
+	Limit      int
+	// MaxQuantity cuma dipake slow mover: produk dengan qty terjual <= ini
+	MaxQuantity int
+}
+
+type ProductSalesReport struct {
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Sort       string         `json:"sort"`
+	ByCategory bool           `json:"by_category"`
+	Products   []ProductSales `json:"products"`
+}
+
+// MarginReportRow - Key itu product_id / category_id / tanggal tergantung group_by
+type MarginReportRow struct {
+	Key            string  `json:"key"`
+	Label          string  `json:"label"`
+	QuantitySold   int     `json:"quantity_sold"`
+	Revenue        int     `json:"revenue"`
+	COGS           int     `json:"cogs"`
+	GrossProfit    int     `json:"gross_profit"`
+	GrossMarginPct float64 `json:"gross_margin_pct"`
+}
+
+type MarginReport struct {
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	GroupBy  string            `json:"group_by"`
+	Timezone string            `json:"timezone"`
+	Rows     []MarginReportRow `json:"rows"`
+}
+
+// TaxReportRow - rekap PPN satu bucket buat lapor pajak. DPP = dasar pengenaan pajak
+// (net_amount baris yang kena PPN), ExemptSales = penjualan barang bebas PPN.
+// Kolom refund dihitung dari tanggal refund-nya dan bernilai negatif,
+// jadi Net* = kolom penjualan + kolom refund
+type TaxReportRow struct {
+	Period         string `json:"period"`
+	Transactions   int    `json:"transactions"`
+	TaxableBase    int    `json:"taxable_base"`
+	TaxCollected   int    `json:"tax_collected"`
+	ExemptSales    int    `json:"exempt_sales"`
+	RefundCount    int    `json:"refund_count"`
+	RefundedBase   int    `json:"refunded_base"`
+	RefundedTax    int    `json:"refunded_tax"`
+	RefundedExempt int    `json:"refunded_exempt"`
+	NetTaxableBase int    `json:"net_taxable_base"`
+	NetTax         int    `json:"net_tax"`
+	NetExemptSales int    `json:"net_exempt_sales"`
+}
+
+type TaxReport struct {
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	GroupBy  string         `json:"group_by"`
+	Timezone string         `json:"timezone"`
+	TaxRate  float64        `json:"tax_rate"`
+	TaxMode  string         `json:"tax_mode"`
+	Buckets  []TaxReportRow `json:"buckets"`
+	Totals   TaxReportRow   `json:"totals"`
+}
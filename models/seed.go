@@ -0,0 +1,26 @@
+package models
+
+// SeedOptions - Reset kosongin katalog & data penjualan dulu (CLI minta --confirm-wipe).
+// ResetStock nyamain stok produk yang udah ada ke data seed; tanpa flag ini
+// stok produk lama ga disentuh, cuma produk baru yang stoknya diisi
+type SeedOptions struct {
+	Reset      bool
+	ResetStock bool
+}
+
+// SeedResult - ringkasan hasil `kasir-api seed`
+type SeedResult struct {
+	CategoriesCreated int               `json:"categories_created"`
+	CategoriesUpdated int               `json:"categories_updated"`
+	ProductsCreated   int               `json:"products_created"`
+	ProductsUpdated   int               `json:"products_updated"`
+	StockChanges      []SeedStockChange `json:"stock_changes"`
+}
+
+// SeedStockChange - stok produk lama yang dikoreksi --reset-stock
+type SeedStockChange struct {
+	ProductID int    `json:"product_id"`
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+const (
+	ShiftStatusOpen   = "open"
+	ShiftStatusClosed = "closed"
+
+	CashMovementIn  = "in"
+	CashMovementOut = "out"
+)
+
+// Shift - satu sesi laci kas kasir. Ringkasan kas (CashSales dst) dihitung dari
+// transaksi, refund dan cash in/out yang ke-stamp shift ini.
+// ExpectedCash = OpeningFloat + CashSales - CashRefunds + CashIn - CashOut
+type Shift struct {
+	ID           int            `json:"id"`
+	UserID       int            `json:"user_id"`
+	Cashier      string         `json:"cashier"`
+	Status       string         `json:"status"`
+	OpeningFloat int            `json:"opening_float"`
+	CashSales    int            `json:"cash_sales"`
+	CashRefunds  int            `json:"cash_refunds"`
+	CashIn       int            `json:"cash_in"`
+	CashOut      int            `json:"cash_out"`
+	ExpectedCash int            `json:"expected_cash"`
+	CountedCash  *int           `json:"counted_cash"`
+	Difference   *int           `json:"difference"` // counted - expected, minus = kurang (short)
+	Note         string         `json:"note"`
+	ClosingNote  string         `json:"closing_note"`
+	OpenedAt     time.Time      `json:"opened_at"`
+	ClosedAt     *time.Time     `json:"closed_at"`
+	Movements    []CashMovement `json:"cash_movements,omitempty"`
+	// Payments - penjualan shift ini per metode bayar
+	Payments []PaymentMethodTotal `json:"payments,omitempty"`
+}
+
+// CashMovement - uang masuk/keluar laci di luar penjualan (beli es, bayar kurir, dll)
+type CashMovement struct {
+	ID        int       `json:"id"`
+	ShiftID   int       `json:"shift_id"`
+	Type      string    `json:"type"`
+	Amount    int       `json:"amount"`
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Staff - user yang lagi akses, diisi handler dari token
+type Staff struct {
+	UserID   int
+	Username string
+	Role     string
+}
+
+type OpenShiftRequest struct {
+	OpeningFloat int    `json:"opening_float"`
+	Note         string `json:"note"`
+}
+
+type CashMovementRequest struct {
+	Type   string `json:"type"`
+	Amount int    `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+// CloseShiftRequest - CountedCash pointer biar bisa bedain "ga diisi" sama 0
+type CloseShiftRequest struct {
+	CountedCash *int   `json:"counted_cash"`
+	Note        string `json:"note"`
+}
+
+type ShiftFilter struct {
+	Status string
+	UserID int
+}
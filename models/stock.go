@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Alasan perubahan stok. Semua perubahan products.stock wajib lewat ledger ini
+const (
+	StockReasonInitial    = "initial"
+	StockReasonSale       = "sale"
+	StockReasonRefund     = "refund"
+	StockReasonRestock    = "restock"
+	StockReasonAdjustment = "adjustment"
+	StockReasonOpname     = "opname"
+	// reservasi stok buat sale QRIS yang belum dibayar (kalau QRIS_STOCK_POLICY=reserve)
+	StockReasonReservation = "reservation"
+	StockReasonRelease     = "reservation_release"
+)
+
+// StockMovement - satu baris ledger (append-only). Quantity itu delta,
+// positif = stok masuk, negatif = stok keluar. StockAfter = saldo setelah movement ini
+type StockMovement struct {
+	ID            int       `json:"id"`
+	ProductID     int       `json:"product_id"`
+	Reason        string    `json:"reason"`
+	Quantity      int       `json:"quantity"`
+	StockAfter    int       `json:"stock_after"`
+	ReferenceType string    `json:"reference_type"`
+	ReferenceID   int       `json:"reference_id"`
+	Note          string    `json:"note"`
+	CreatedBy     string    `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// StockAdjustmentRequest - input manual dari API (restock / adjustment)
+type StockAdjustmentRequest struct {
+	Reason   string `json:"reason"`
+	Quantity int    `json:"quantity"`
+	Note     string `json:"note"`
+	User     string `json:"user"`
+}
+
+type StockHistory struct {
+	ProductID int             `json:"product_id"`
+	Stock     int             `json:"stock"`
+	Movements []StockMovement `json:"movements"`
+	Page      int             `json:"page"`
+	Limit     int             `json:"limit"`
+	Total     int             `json:"total"`
+}
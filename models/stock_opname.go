@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+const (
+	OpnameStatusOpen      = "open"
+	OpnameStatusPosted    = "posted"
+	OpnameStatusCancelled = "cancelled"
+)
+
+// StockOpname - sesi hitung fisik. CategoryID 0 = semua produk.
+// LockSales cuma berlaku kalau CategoryID diisi: produk di kategori itu
+// ga bisa dijual selama sesi masih open
+type StockOpname struct {
+	ID         int               `json:"id"`
+	Status     string            `json:"status"`
+	CategoryID int               `json:"category_id"`
+	LockSales  bool              `json:"lock_sales"`
+	Note       string            `json:"note"`
+	CreatedBy  string            `json:"created_by"`
+	CreatedAt  time.Time         `json:"created_at"`
+	PostedBy   string            `json:"posted_by"`
+	PostedAt   *time.Time        `json:"posted_at"`
+	Lines      []StockOpnameLine `json:"lines,omitempty"`
+}
+
+// StockOpnameLine - hasil hitung satu produk. SystemStock di-snapshot pas count
+// di-submit, jadi penjualan setelahnya ga ngerusak selisih
+type StockOpnameLine struct {
+	ProductID   int       `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	SystemStock int       `json:"system_stock"`
+	CountedQty  int       `json:"counted_qty"`
+	Difference  int       `json:"difference"`
+	CountedBy   string    `json:"counted_by"`
+	CountedAt   time.Time `json:"counted_at"`
+}
+
+type CreateStockOpnameRequest struct {
+	CategoryID int    `json:"category_id"`
+	LockSales  bool   `json:"lock_sales"`
+	Note       string `json:"note"`
+	User       string `json:"user"`
+}
+
+type StockOpnameCount struct {
+	ProductID  int `json:"product_id"`
+	CountedQty int `json:"counted_qty"`
+}
+
+type SubmitStockOpnameCountsRequest struct {
+	User  string             `json:"user"`
+	Items []StockOpnameCount `json:"items"`
+}
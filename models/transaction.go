@@ -0,0 +1,145 @@
+package models
+
+import "time"
+
+// Transaction itu header penjualan (struk), Items isinya baris per produk.
+// TotalAmount itu yang dibayar customer (udah termasuk PPN di dua mode).
+// TaxRate & TaxMode di-snapshot pas checkout biar struk lama tetap sama kalau config pajak berubah.
+// CustomerID diisi kalau belanjanya pake nomor member
+type Transaction struct {
+	ID            int               `json:"id"`
+	Cashier       string            `json:"cashier"`
+	ShiftID       int               `json:"shift_id,omitempty"`
+	CustomerID    int               `json:"customer_id,omitempty"`
+	PaymentMethod string            `json:"payment_method"`
+	TotalAmount   int               `json:"total_amount"`
+	TaxAmount     int               `json:"tax_amount"`
+	TaxRate       float64           `json:"tax_rate"`
+	TaxMode       string            `json:"tax_mode"`
+	PaidAmount    int               `json:"paid_amount"`
+	ChangeAmount  int               `json:"change_amount"`
+	PointsEarned  int               `json:"points_earned,omitempty"`
+	PointsUsed    int               `json:"points_used,omitempty"`
+	RefundAmount  int               `json:"refund_amount"`
+	ItemCount     int               `json:"item_count"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Items         []TransactionItem `json:"items,omitempty"`
+	Payments      []Payment         `json:"payments,omitempty"`
+	// LowStockAlerts - produk yang stoknya nyebrang min_stock gara-gara checkout ini
+	LowStockAlerts []LowStockAlert `json:"low_stock_alerts,omitempty"`
+}
+
+// TransactionItem - nama dan harga di-snapshot pas checkout,
+// jadi kalau produk di-update nanti struk lama tetap sama.
+// Subtotal = Price x Quantity sebelum promo. Discount = potongan promo baris ini,
+// CartDiscount = bagian diskon keranjang yang dialokasiin ke baris ini.
+// NetAmount (DPP) + TaxAmount = yang dibayar buat baris ini (udah setelah diskon)
+type TransactionItem struct {
+	ID            int    `json:"id"`
+	TransactionID int    `json:"transaction_id"`
+	ProductID     int    `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	CategoryID    int    `json:"category_id"`
+	CategoryName  string `json:"category_name"`
+	Price         int    `json:"price"`
+	CostPrice     int    `json:"cost_price"`
+	Quantity      int    `json:"quantity"`
+	Subtotal      int    `json:"subtotal"`
+	Discount      int    `json:"discount"`
+	CartDiscount  int    `json:"cart_discount"`
+	TaxExempt     bool   `json:"tax_exempt"`
+	NetAmount     int    `json:"net_amount"`
+	TaxAmount     int    `json:"tax_amount"`
+	RefundedQty   int    `json:"refunded_quantity"`
+}
+
+// CheckoutItem - satu baris keranjang dari client
+type CheckoutItem struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// CheckoutRequest - UserID diisi handler dari token, dipake buat nyari shift yang lagi buka.
+// Payments kosong = dibayar pas satu kali pake PaymentMethod (default cash).
+// CustomerPhone opsional; nomor yang belum terdaftar otomatis jadi member baru
+type CheckoutRequest struct {
+	UserID        int            `json:"-"`
+	Cashier       string         `json:"cashier"`
+	CustomerPhone string         `json:"customer_phone"`
+	PaymentMethod string         `json:"payment_method"`
+	Items         []CheckoutItem `json:"items"`
+	Payments      []PaymentInput `json:"payments"`
+}
+
+// TransactionFilter - filter buat list riwayat transaksi.
+// From inklusif, To eksklusif; nilai kosong berarti ga difilter
+type TransactionFilter struct {
+	From          time.Time
+	To            time.Time
+	Cashier       string
+	PaymentMethod string
+	CustomerID    int
+	Page          int
+	Limit         int
+}
+
+// TransactionList - response list transaksi + info paging
+type TransactionList struct {
+	Data  []Transaction `json:"data"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+	Total int           `json:"total"`
+}
+
+// Refund - dokumen retur yang nge-link ke transaksi asal
+type Refund struct {
+	ID            int    `json:"id"`
+	TransactionID int    `json:"transaction_id"`
+	ShiftID       int    `json:"shift_id,omitempty"`
+	Reason        string `json:"reason"`
+	TotalAmount   int    `json:"total_amount"`
+	TaxAmount     int    `json:"tax_amount"`
+	// PointsReversed - poin hasil belanja yang ditarik lagi dari member
+	PointsReversed int `json:"points_reversed,omitempty"`
+	// PointsRestored - poin yang dulu dipake bayar, dibalikin ke saldo member (bukan jadi uang)
+	PointsRestored int       `json:"points_restored,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	// Payments - TotalAmount dibagi ke metode bayar transaksi asal, yang cash keluar dari laci.
+	// PaidOut = uang yang beneran dibalikin, yaitu semua baris Payments selain points
+	Payments []RefundPayment `json:"payments"`
+	PaidOut  int             `json:"paid_out"`
+	Items    []RefundItem    `json:"items"`
+}
+
+// RefundItem - Subtotal itu uang yang balik ke customer (termasuk PPN-nya),
+// dibagi proporsional dari NetAmount & TaxAmount baris asal
+type RefundItem struct {
+	ID                int    `json:"id"`
+	RefundID          int    `json:"refund_id"`
+	TransactionItemID int    `json:"transaction_item_id"`
+	ProductID         int    `json:"product_id"`
+	ProductName       string `json:"product_name"`
+	Price             int    `json:"price"`
+	Quantity          int    `json:"quantity"`
+	Subtotal          int    `json:"subtotal"`
+	NetAmount         int    `json:"net_amount"`
+	TaxAmount         int    `json:"tax_amount"`
+}
+
+// RefundPayment - bagian refund yang balik lewat satu metode bayar
+type RefundPayment struct {
+	ID       int    `json:"id"`
+	RefundID int    `json:"refund_id"`
+	Method   string `json:"method"`
+	Amount   int    `json:"amount"`
+}
+
+// RefundRequest - Items kosong artinya refund full semua sisa item.
+// UserID & User diisi handler; kalau user itu lagi buka shift, uang refund dicatat keluar dari laci shift itu.
+// User dicatat di ledger stok sebagai yang balikin barang
+type RefundRequest struct {
+	UserID int            `json:"-"`
+	User   string         `json:"-"`
+	Reason string         `json:"reason"`
+	Items  []CheckoutItem `json:"items"`
+}
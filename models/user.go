@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Role staff, urut dari akses paling sempit: cashier < supervisor < owner
+const (
+	RoleCashier    = "cashier"
+	RoleSupervisor = "supervisor"
+	RoleOwner      = "owner"
+)
+
+type User struct {
+	ID           int       `json:"id"`
+	Username     string    `json:"username"`
+	Name         string    `json:"name"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CreateUserRequest struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenResponse - ExpiresIn dalam detik, buat access token
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	User         User   `json:"user"`
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"kasir-api/internal/auth"
+	"kasir-api/models"
+)
+
+// permissions - tabel akses per endpoint, dicek auth.Authorize sebelum masuk handler.
+// Role di sini role minimal: supervisor otomatis boleh semua yang cashier boleh, owner boleh semuanya.
+// Endpoint yang ga kedaftar di sini cuma bisa diakses owner, jadi kalau nambah route baru
+// jangan lupa tambahin juga di sini.
+var permissions = []auth.Permission{
+	// Auth
+	{Pattern: "GET /api/auth/me", Role: models.RoleCashier},
+	{Pattern: "POST /api/auth/logout", Role: models.RoleCashier},
+
+	// Produk: cashier cuma baca, ubah harga/hapus produk cuma owner
+	{Pattern: "GET /api/produk", Role: models.RoleCashier},
+	{Pattern: "GET /api/produk/{id}", Role: models.RoleCashier},
+	{Pattern: "GET /api/produk/low-stock", Role: models.RoleCashier},
+	{Pattern: "GET /api/produk/{id}/stock-history", Role: models.RoleSupervisor},
+	{Pattern: "POST /api/produk/{id}/stock", Role: models.RoleSupervisor},
+	{Pattern: "POST /api/produk", Role: models.RoleOwner},
+	{Pattern: "PUT /api/produk/{id}", Role: models.RoleOwner},
+	{Pattern: "DELETE /api/produk/{id}", Role: models.RoleOwner},
+
+	// Categories
+	{Pattern: "GET /categories", Role: models.RoleCashier},
+	{Pattern: "GET /categories/{id}", Role: models.RoleCashier},
+	{Pattern: "POST /categories", Role: models.RoleSupervisor},
+	{Pattern: "PUT /categories/{id}", Role: models.RoleSupervisor},
+	{Pattern: "DELETE /categories/{id}", Role: models.RoleOwner},
+
+	// Promo: kasir cuma lihat & hitung keranjang, atur promo supervisor
+	{Pattern: "GET /api/promotions", Role: models.RoleCashier},
+	{Pattern: "GET /api/promotions/{id}", Role: models.RoleCashier},
+	{Pattern: "POST /api/cart/price", Role: models.RoleCashier},
+	{Pattern: "POST /api/promotions", Role: models.RoleSupervisor},
+	{Pattern: "PUT /api/promotions/{id}", Role: models.RoleSupervisor},
+	{Pattern: "DELETE /api/promotions/{id}", Role: models.RoleSupervisor},
+
+	// Transaksi: checkout cashier, refund minimal supervisor
+	{Pattern: "POST /api/transactions", Role: models.RoleCashier},
+	{Pattern: "GET /api/transactions", Role: models.RoleCashier},
+	{Pattern: "GET /api/transactions/{id}", Role: models.RoleCashier},
+	{Pattern: "GET /api/transactions/{id}/receipt", Role: models.RoleCashier},
+	{Pattern: "POST /api/transactions/{id}/refund", Role: models.RoleSupervisor},
+
+	// Member: kasir daftarin & cari member pas checkout
+	{Pattern: "GET /api/customers", Role: models.RoleCashier},
+	{Pattern: "POST /api/customers", Role: models.RoleCashier},
+	{Pattern: "GET /api/customers/{id}", Role: models.RoleCashier},
+	{Pattern: "PUT /api/customers/{id}", Role: models.RoleCashier},
+	{Pattern: "GET /api/customers/{id}/points", Role: models.RoleCashier},
+	{Pattern: "GET /api/customers/{id}/transactions", Role: models.RoleCashier},
+
+	// QRIS dinamis: kasir cuma bisa lihat/batalin sale sendiri (dicek di service).
+	// Simulasi bayar cuma ada di fake gateway (APP_ENV=dev) dan cuma owner, biar kasir ga bisa "lunasin" sale sendiri
+	{Pattern: "POST /api/qris/sales", Role: models.RoleCashier},
+	{Pattern: "GET /api/qris/sales/{id}", Role: models.RoleCashier},
+	{Pattern: "POST /api/qris/sales/{id}/cancel", Role: models.RoleCashier},
+	{Pattern: "POST /api/dev/qris/{charge_id}/simulate", Role: models.RoleOwner},
+
+	// Shift kasir: kasir cuma bisa akses shift sendiri (dicek di service), list semua shift supervisor
+	{Pattern: "POST /api/shifts", Role: models.RoleCashier},
+	{Pattern: "GET /api/shifts/current", Role: models.RoleCashier},
+	{Pattern: "GET /api/shifts/{id}", Role: models.RoleCashier},
+	{Pattern: "POST /api/shifts/{id}/cash", Role: models.RoleCashier},
+	{Pattern: "POST /api/shifts/{id}/close", Role: models.RoleCashier},
+	{Pattern: "GET /api/shifts", Role: models.RoleSupervisor},
+
+	// Stock opname: hitung fisik boleh cashier, buka/posting/batal supervisor (buka pake lock_sales cuma owner)
+	{Pattern: "GET /api/stock-opname", Role: models.RoleCashier},
+	{Pattern: "GET /api/stock-opname/{id}", Role: models.RoleCashier},
+	{Pattern: "POST /api/stock-opname/{id}/counts", Role: models.RoleCashier},
+	{Pattern: "POST /api/stock-opname", Role: models.RoleSupervisor},
+	{Pattern: "POST /api/stock-opname/{id}/post", Role: models.RoleSupervisor},
+	{Pattern: "POST /api/stock-opname/{id}/cancel", Role: models.RoleSupervisor},
+
+	// Supplier & purchase order
+	{Pattern: "GET /api/suppliers", Role: models.RoleSupervisor},
+	{Pattern: "GET /api/suppliers/{id}", Role: models.RoleSupervisor},
+	{Pattern: "POST /api/suppliers", Role: models.RoleSupervisor},
+	{Pattern: "PUT /api/suppliers/{id}", Role: models.RoleSupervisor},
+	{Pattern: "GET /api/purchase-orders", Role: models.RoleSupervisor},
+	{Pattern: "GET /api/purchase-orders/{id}", Role: models.RoleSupervisor},
+	{Pattern: "POST /api/purchase-orders", Role: models.RoleSupervisor},
+	{Pattern: "POST /api/purchase-orders/{id}/send", Role: models.RoleSupervisor},
+	{Pattern: "POST /api/purchase-orders/{id}/receive", Role: models.RoleSupervisor},
+
+	// Laporan: margin/HPP cuma owner
+	{Pattern: "GET /api/reports/sales", Role: models.RoleSupervisor},
+	{Pattern: "GET /api/reports/products/top", Role: models.RoleSupervisor},
+	{Pattern: "GET /api/reports/products/slow", Role: models.RoleSupervisor},
+	{Pattern: "GET /api/reports/payments", Role: models.RoleSupervisor},
+	{Pattern: "GET /api/reports/tax", Role: models.RoleSupervisor},
+	{Pattern: "GET /api/reports/margin", Role: models.RoleOwner},
+
+	// Audit log
+	{Pattern: "GET /api/audit", Role: models.RoleOwner},
+
+	// User management
+	{Pattern: "GET /api/users", Role: models.RoleOwner},
+	{Pattern: "POST /api/users", Role: models.RoleOwner},
+}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"kasir-api/models"
+	"log/slog"
+)
+
+type AuditRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewAuditRepository(db *sql.DB, logger *slog.Logger) *AuditRepository {
+	return &AuditRepository{db: db, logger: logger}
+}
+
+// insertAudit - dipanggil repository lain di dalam tx yang sama dengan perubahannya,
+// jadi kalau audit gagal perubahannya ikut di-rollback. before/after nil = NULL
+func insertAudit(tx *sql.Tx, actor models.AuditActor, action, entityType string, entityID int, before, after interface{}) error {
+	beforeJSON, err := auditSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	var actorID *int
+	if actor.UserID > 0 {
+		actorID = &actor.UserID
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO audit_logs (actor_id, actor, action, entity_type, entity_id, before_data, after_data, request_id, ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, actorID, actor.Username, action, entityType, entityID, beforeJSON, afterJSON, actor.RequestID, actor.IP)
+	return err
+}
+
+func auditSnapshot(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (repo *AuditRepository) GetAll(filter models.AuditFilter) ([]models.AuditLog, int, error) {
+	repo.logger.Info("Fetching audit logs", "entity_type", filter.EntityType, "actor", filter.Actor, "page", filter.Page)
+
+	where := "WHERE 1=1"
+	args := make([]interface{}, 0, 7)
+	if filter.EntityType != "" {
+		args = append(args, filter.EntityType)
+		where += fmt.Sprintf(" AND entity_type = $%d", len(args))
+	}
+	if filter.EntityID > 0 {
+		args = append(args, filter.EntityID)
+		where += fmt.Sprintf(" AND entity_id = $%d", len(args))
+	}
+	if filter.Actor != "" {
+		args = append(args, filter.Actor)
+		where += fmt.Sprintf(" AND actor = $%d", len(args))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		where += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		where += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+
+	var total int
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM audit_logs "+where, args...).Scan(&total); err != nil {
+		repo.logger.Error("Failed to count audit logs", "error", err)
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query := fmt.Sprintf(`
+		SELECT id, actor_id, actor, action, entity_type, entity_id, before_data, after_data, request_id, ip, created_at
+		FROM audit_logs
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		repo.logger.Error("Failed to fetch audit logs", "error", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	logs := make([]models.AuditLog, 0)
+	for rows.Next() {
+		var l models.AuditLog
+		var actorID sql.NullInt64
+		var before, after []byte
+		err := rows.Scan(&l.ID, &actorID, &l.Actor, &l.Action, &l.EntityType, &l.EntityID, &before, &after, &l.RequestID, &l.IP, &l.CreatedAt)
+		if err != nil {
+			repo.logger.Error("Failed to scan audit log", "error", err)
+			return nil, 0, err
+		}
+		if actorID.Valid {
+			id := int(actorID.Int64)
+			l.ActorID = &id
+		}
+		if before != nil {
+			l.Before = before
+		}
+		if after != nil {
+			l.After = after
+		}
+		logs = append(logs, l)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate audit logs", "error", err)
+		return nil, 0, err
+	}
+
+	repo.logger.Info("Successfully fetched audit logs", "count", len(logs), "total", total)
+	return logs, total, nil
+}
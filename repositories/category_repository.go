@@ -2,11 +2,28 @@ package repositories
 
 import (
 	"database/sql"
-	"errors"
+	"fmt"
+	"kasir-api/internal/apperror"
 	"kasir-api/models"
 	"log/slog"
 )
 
+var (
+	ErrCategoryNotFound = apperror.New(apperror.ErrNotFound, "category tidak ditemukan")
+	ErrCategoryInUse    = apperror.New(apperror.ErrForeignKey, "category masih dipake produk")
+)
+
+// unknownCategory - category_id di input produk ga ada. Tetap nge-wrap ErrCategoryNotFound,
+// tapi jenisnya validasi karena yang salah input client, bukan resource di URL
+func unknownCategory(id int) error {
+	return &apperror.Error{
+		Kind:    apperror.ErrValidation,
+		Message: fmt.Sprintf("category_id %d tidak ditemukan", id),
+		Details: map[string]any{"field": "category_id"},
+		Err:     ErrCategoryNotFound,
+	}
+}
+
 type CategoryRepository struct {
 	db     *sql.DB
 	logger *slog.Logger
@@ -16,22 +33,93 @@ func NewCategoryRepository(db *sql.DB, logger *slog.Logger) *CategoryRepository
 	return &CategoryRepository{db: db, logger: logger}
 }
 
-func (repo *CategoryRepository) Create(category *models.Category) error {
+func (repo *CategoryRepository) Create(category *models.Category, actor models.AuditActor) error {
 	repo.logger.Info("Creating category", "name", category.Name, "description", category.Description)
-	query := "INSERT INTO categories (Name, Description) VALUES ($1, $2) RETURNING id"
-	err := repo.db.QueryRow(query, category.Name, category.Description).Scan(&category.ID)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO categories (Name, Description, tax_exempt) VALUES ($1, $2, $3) RETURNING id"
+	err = tx.QueryRow(query, category.Name, category.Description, category.TaxExempt).Scan(&category.ID)
 	if err != nil {
 		repo.logger.Error("Failed to create category", "error", err, "name", category.Name)
+		return apperror.FromDB(err)
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "category", category.ID, nil, category); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", category.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit category", "error", err)
 		return err
 	}
 	repo.logger.Info("Category created successfully", "id", category.ID, "name", category.Name)
 	return nil
 }
 
-func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
-	repo.logger.Info("Fetching all categories")
-	query := "SELECT id, Name, Description FROM categories"
-	rows, err := repo.db.Query(query)
+// categorySortColumns - whitelist sort GET /categories
+var categorySortColumns = map[string]listColumn{
+	"id":   {expr: "id"},
+	"name": {expr: `name COLLATE "C"`, text: true},
+}
+
+func categoryKeyValues(c models.Category, keys []sortKey) []any {
+	values := make([]any, len(keys))
+	for i, k := range keys {
+		switch k.field {
+		case "id":
+			values[i] = c.ID
+		case "name":
+			values[i] = c.Name
+		}
+	}
+	return values
+}
+
+// GetAll - list kategori pake keyset pagination, polanya sama kayak ProductRepository.GetAll
+func (repo *CategoryRepository) GetAll(filter models.CategoryFilter) (*models.CategoryList, error) {
+	repo.logger.Info("Fetching categories", "sort", filter.Sort, "limit", filter.Limit)
+	keys, after, err := parseListParams(filter.ListParams, categorySortColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	where := "WHERE 1=1"
+	args := make([]interface{}, 0, 4)
+	if filter.TaxExempt != nil {
+		args = append(args, *filter.TaxExempt)
+		where += fmt.Sprintf(" AND tax_exempt = $%d", len(args))
+	}
+
+	list := &models.CategoryList{Data: make([]models.Category, 0), Limit: filter.Limit}
+	if filter.IncludeTotal {
+		var total int
+		err := repo.db.QueryRow("SELECT COUNT(*) FROM categories "+where, args...).Scan(&total)
+		if err != nil {
+			repo.logger.Error("Failed to count categories", "error", err)
+			return nil, err
+		}
+		list.Total = &total
+	}
+
+	if after != nil {
+		where += " AND " + keysetCondition(keys, len(args)+1)
+		args = append(args, after...)
+	}
+	limit := ""
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit+1)
+		limit = fmt.Sprintf("LIMIT $%d", len(args))
+	}
+	query := fmt.Sprintf("SELECT id, Name, Description, tax_exempt FROM categories %s ORDER BY %s %s", where, orderBy(keys), limit)
+
+	rows, err := repo.db.Query(query, args...)
 	if err != nil {
 		repo.logger.Error("Failed to fetch categories", "error", err)
 		return nil, err
@@ -39,37 +127,40 @@ func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
 	defer rows.Close()
 	// jalan di akhir close connect db always
 
-	//kode di bawah buat ubah hasil query mentah jadi bentuk struct product
-	categories := make([]models.Category, 0)
 	for rows.Next() {
 		var p models.Category
 
-		err := rows.Scan(&p.ID, &p.Name, &p.Description)
+		err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.TaxExempt)
 		if err != nil {
 			repo.logger.Error("Failed to scan category", "error", err)
 			return nil, err
 		}
-		//bentuk mentahnya: 1, "produk A", 1000, 10
+		list.Data = append(list.Data, p)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate categories", "error", err)
+		return nil, err
+	}
 
-		//assign hasil slicing
-		categories = append(categories, p)
-		//bentuknya {{id:1, name:"produk A", price:1000, stock:10}, {id:2, name:"produk B", price:2000, stock:20}  }
+	if filter.Limit > 0 && len(list.Data) > filter.Limit {
+		list.Data = list.Data[:filter.Limit]
+		list.NextCursor = encodeCursor(keys, categoryKeyValues(list.Data[filter.Limit-1], keys))
 	}
 
-	repo.logger.Info("Successfully fetched categories", "count", len(categories))
-	return categories, nil
+	repo.logger.Info("Successfully fetched categories", "count", len(list.Data), "has_next", list.NextCursor != "")
+	return list, nil
 }
 
 // GetByID - ambil produk by ID
 func (repo *CategoryRepository) GetByID(id int) (*models.Category, error) {
 	repo.logger.Info("Fetching category by ID", "id", id)
-	query := "SELECT id, Name, Description FROM categories WHERE id = $1"
+	query := "SELECT id, Name, Description, tax_exempt FROM categories WHERE id = $1"
 
 	var p models.Category
-	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Description)
+	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Description, &p.TaxExempt)
 	if err == sql.ErrNoRows {
 		repo.logger.Warn("Category not found", "id", id)
-		return nil, errors.New("produk tidak ditemukan")
+		return nil, ErrCategoryNotFound
 	}
 	if err != nil {
 		repo.logger.Error("Failed to fetch category by ID", "error", err, "id", id)
@@ -80,51 +171,95 @@ func (repo *CategoryRepository) GetByID(id int) (*models.Category, error) {
 	return &p, nil
 }
 
-func (repo *CategoryRepository) Update(category *models.Category) error {
+func (repo *CategoryRepository) Update(category *models.Category, actor models.AuditActor) error {
 	repo.logger.Info("Updating category", "id", category.ID, "name", category.Name)
-	query := "UPDATE categories SET name = $1, description = $2 WHERE id = $3"
 
-	result, err := repo.db.Exec(query, category.Name, category.Description, category.ID)
+	tx, err := repo.db.Begin()
 	if err != nil {
-		repo.logger.Error("Failed to update category", "error", err, "id", category.ID)
+		repo.logger.Error("Failed to begin transaction", "error", err)
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	before, err := lockCategory(tx, category.ID)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Category not found for update", "id", category.ID)
+		return ErrCategoryNotFound
+	}
 	if err != nil {
-		repo.logger.Error("Failed to get rows affected", "error", err, "id", category.ID)
+		repo.logger.Error("Failed to lock category", "error", err, "id", category.ID)
 		return err
 	}
 
-	if rows == 0 {
-		repo.logger.Warn("Category not found for update", "id", category.ID)
-		return errors.New("produk tidak ditemukan")
+	query := "UPDATE categories SET name = $1, description = $2, tax_exempt = $3 WHERE id = $4"
+	if _, err := tx.Exec(query, category.Name, category.Description, category.TaxExempt, category.ID); err != nil {
+		repo.logger.Error("Failed to update category", "error", err, "id", category.ID)
+		return apperror.FromDB(err)
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionUpdate, "category", category.ID, before, category); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", category.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit category update", "error", err)
+		return err
 	}
 
 	repo.logger.Info("Category updated successfully", "id", category.ID, "name", category.Name)
 	return nil
 }
 
-func (repo *CategoryRepository) Delete(id int) error {
+func (repo *CategoryRepository) Delete(id int, actor models.AuditActor) error {
 	repo.logger.Info("Deleting category", "id", id)
-	query := "DELETE FROM categories WHERE id = $1"
-	result, err := repo.db.Exec(query, id)
+
+	tx, err := repo.db.Begin()
 	if err != nil {
-		repo.logger.Error("Failed to delete category - possibly has products referencing it", "error", err, "id", id)
+		repo.logger.Error("Failed to begin transaction", "error", err)
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	before, err := lockCategory(tx, id)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Category not found for deletion", "id", id)
+		return ErrCategoryNotFound
+	}
 	if err != nil {
-		repo.logger.Error("Failed to get rows affected", "error", err, "id", id)
+		repo.logger.Error("Failed to lock category", "error", err, "id", id)
 		return err
 	}
 
-	if rows == 0 {
-		repo.logger.Warn("Category not found for deletion", "id", id)
-		return errors.New("category tidak ditemukan")
+	if _, err := tx.Exec("DELETE FROM categories WHERE id = $1", id); err != nil {
+		repo.logger.Error("Failed to delete category - possibly has products referencing it", "error", err, "id", id)
+		if apperror.IsForeignKeyViolation(err) {
+			return ErrCategoryInUse
+		}
+		return err
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionDelete, "category", id, before, nil); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", id)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit category delete", "error", err)
+		return err
 	}
 
 	repo.logger.Info("Category deleted successfully", "id", id)
 	return nil
 }
+
+// lockCategory - ambil & lock baris kategori buat snapshot "before" audit
+func lockCategory(tx *sql.Tx, id int) (*models.Category, error) {
+	var c models.Category
+	err := tx.QueryRow("SELECT id, name, description, tax_exempt FROM categories WHERE id = $1 FOR UPDATE", id).
+		Scan(&c.ID, &c.Name, &c.Description, &c.TaxExempt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
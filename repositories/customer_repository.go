@@ -0,0 +1,245 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"log/slog"
+)
+
+var (
+	ErrCustomerNotFound   = apperror.New(apperror.ErrNotFound, "customer tidak ditemukan")
+	ErrCustomerPhoneTaken = apperror.New(apperror.ErrConflict, "nomor HP sudah terdaftar sebagai member lain")
+	ErrInsufficientPoints = apperror.New(apperror.ErrConflict, "poin member tidak mencukupi")
+)
+
+type CustomerRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewCustomerRepository(db *sql.DB, logger *slog.Logger) *CustomerRepository {
+	return &CustomerRepository{db: db, logger: logger}
+}
+
+const customerSelect = "SELECT id, phone, name, points, created_at FROM customers"
+
+func scanCustomer(row interface{ Scan(...interface{}) error }, c *models.Customer) error {
+	return row.Scan(&c.ID, &c.Phone, &c.Name, &c.Points, &c.CreatedAt)
+}
+
+func (repo *CustomerRepository) Create(customer *models.Customer, actor models.AuditActor) error {
+	repo.logger.Info("Creating customer", "phone", customer.Phone)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"INSERT INTO customers (phone, name) VALUES ($1, $2) RETURNING id, points, created_at",
+		customer.Phone, customer.Name,
+	).Scan(&customer.ID, &customer.Points, &customer.CreatedAt)
+	if err != nil {
+		if apperror.IsUniqueViolation(err) {
+			repo.logger.Warn("Customer phone already registered", "phone", customer.Phone)
+			return ErrCustomerPhoneTaken
+		}
+		repo.logger.Error("Failed to create customer", "error", err, "phone", customer.Phone)
+		return apperror.FromDB(err)
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "customer", customer.ID, nil, customer); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", customer.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit customer", "error", err)
+		return err
+	}
+	repo.logger.Info("Customer created successfully", "id", customer.ID)
+	return nil
+}
+
+// GetAll - search kosong = semua. Search dicocokin ke awalan nomor HP atau potongan nama,
+// dibatesin 100 baris
+func (repo *CustomerRepository) GetAll(search string) ([]models.Customer, error) {
+	repo.logger.Info("Fetching customers", "search", search)
+	rows, err := repo.db.Query(
+		customerSelect+` WHERE $1 = '' OR phone LIKE $1 || '%' OR name ILIKE '%' || $1 || '%'
+		ORDER BY name, phone LIMIT 100`,
+		search,
+	)
+	if err != nil {
+		repo.logger.Error("Failed to fetch customers", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := make([]models.Customer, 0)
+	for rows.Next() {
+		var c models.Customer
+		if err := scanCustomer(rows, &c); err != nil {
+			repo.logger.Error("Failed to scan customer", "error", err)
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate customers", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched customers", "count", len(customers))
+	return customers, nil
+}
+
+func (repo *CustomerRepository) GetByID(id int) (*models.Customer, error) {
+	repo.logger.Info("Fetching customer by ID", "id", id)
+	var c models.Customer
+	err := scanCustomer(repo.db.QueryRow(customerSelect+" WHERE id = $1", id), &c)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Customer not found", "id", id)
+		return nil, ErrCustomerNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to fetch customer by ID", "error", err, "id", id)
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Update - cuma nama & nomor HP, saldo poin cuma berubah lewat ledger
+func (repo *CustomerRepository) Update(customer *models.Customer, actor models.AuditActor) error {
+	repo.logger.Info("Updating customer", "id", customer.ID)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var before models.Customer
+	err = scanCustomer(tx.QueryRow(customerSelect+" WHERE id = $1 FOR UPDATE", customer.ID), &before)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Customer not found for update", "id", customer.ID)
+		return ErrCustomerNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to lock customer", "error", err, "id", customer.ID)
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE customers SET phone = $1, name = $2 WHERE id = $3", customer.Phone, customer.Name, customer.ID)
+	if err != nil {
+		if apperror.IsUniqueViolation(err) {
+			repo.logger.Warn("Customer phone already registered", "phone", customer.Phone)
+			return ErrCustomerPhoneTaken
+		}
+		repo.logger.Error("Failed to update customer", "error", err, "id", customer.ID)
+		return apperror.FromDB(err)
+	}
+	customer.Points = before.Points
+	customer.CreatedAt = before.CreatedAt
+
+	if err := insertAudit(tx, actor, models.AuditActionUpdate, "customer", customer.ID, before, customer); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", customer.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit customer update", "error", err)
+		return err
+	}
+	repo.logger.Info("Customer updated successfully", "id", customer.ID)
+	return nil
+}
+
+// PointEntries - mutasi poin terbaru duluan
+func (repo *CustomerRepository) PointEntries(customerID, limit int) ([]models.PointEntry, error) {
+	repo.logger.Info("Fetching point entries", "customer_id", customerID, "limit", limit)
+	rows, err := repo.db.Query(
+		`SELECT id, customer_id, reason, points, balance, COALESCE(transaction_id, 0), COALESCE(refund_id, 0), created_at
+		 FROM customer_points WHERE customer_id = $1
+		 ORDER BY id DESC LIMIT $2`,
+		customerID, limit,
+	)
+	if err != nil {
+		repo.logger.Error("Failed to fetch point entries", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]models.PointEntry, 0)
+	for rows.Next() {
+		var e models.PointEntry
+		err := rows.Scan(&e.ID, &e.CustomerID, &e.Reason, &e.Points, &e.Balance, &e.TransactionID, &e.RefundID, &e.CreatedAt)
+		if err != nil {
+			repo.logger.Error("Failed to scan point entry", "error", err)
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate point entries", "error", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// lockCustomerByPhone - ambil & lock member berdasarkan nomor HP, dibikin baru kalau belum ada.
+// ON CONFLICT DO UPDATE sengaja dipake biar row-nya ke-lock juga pas udah ada
+func lockCustomerByPhone(tx *sql.Tx, phone string) (*models.Customer, error) {
+	var c models.Customer
+	err := tx.QueryRow(
+		`INSERT INTO customers (phone) VALUES ($1)
+		 ON CONFLICT (phone) DO UPDATE SET phone = EXCLUDED.phone
+		 RETURNING id, phone, name, points, created_at`,
+		phone,
+	).Scan(&c.ID, &c.Phone, &c.Name, &c.Points, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// applyPoints - satu-satunya jalan buat ngubah saldo poin: update customers.points
+// dan catat mutasinya di ledger, di tx yang sama. Saldo ga boleh minus
+func applyPoints(tx *sql.Tx, e *models.PointEntry) error {
+	err := tx.QueryRow(
+		"UPDATE customers SET points = points + $1 WHERE id = $2 AND points + $1 >= 0 RETURNING points",
+		e.Points, e.CustomerID,
+	).Scan(&e.Balance)
+	if err == sql.ErrNoRows {
+		var points int
+		err := tx.QueryRow("SELECT points FROM customers WHERE id = $1", e.CustomerID).Scan(&points)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: id %d", ErrCustomerNotFound, e.CustomerID)
+		}
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: sisa %d, dipakai %d", ErrInsufficientPoints, points, -e.Points)
+	}
+	if err != nil {
+		return err
+	}
+
+	var transactionID, refundID interface{}
+	if e.TransactionID != 0 {
+		transactionID = e.TransactionID
+	}
+	if e.RefundID != 0 {
+		refundID = e.RefundID
+	}
+	return tx.QueryRow(
+		`INSERT INTO customer_points (customer_id, reason, points, balance, transaction_id, refund_id)
+		 VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+		e.CustomerID, e.Reason, e.Points, e.Balance, transactionID, refundID,
+	).Scan(&e.ID, &e.CreatedAt)
+}
@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidSortField = apperror.New(apperror.ErrValidation, "sort tidak dikenal")
+	ErrInvalidCursor    = apperror.New(apperror.ErrValidation, "cursor tidak valid atau ga cocok sama sort-nya")
+)
+
+// listColumn - kolom yang boleh dipake buat sort & keyset. expr masuk ke SQL apa adanya,
+// makanya cuma boleh diisi dari whitelist di kode, jangan pernah dari input user
+type listColumn struct {
+	expr string
+	text bool
+}
+
+type sortKey struct {
+	field  string
+	column listColumn
+	desc   bool
+}
+
+// parseListParams - validasi sort pake whitelist columns, terus decode cursor (kalau ada).
+// after nil berarti mulai dari halaman pertama
+func parseListParams(params models.ListParams, columns map[string]listColumn) ([]sortKey, []any, error) {
+	keys, err := parseSort(params.Sort, columns)
+	if err != nil {
+		return nil, nil, err
+	}
+	if params.Cursor == "" {
+		return keys, nil, nil
+	}
+	after, err := decodeCursor(params.Cursor, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keys, after, nil
+}
+
+// parseSort - "price,-name" -> price ASC, name DESC, id ASC. id selalu jadi
+// tie-breaker terakhir biar urutannya total, jadi keyset-nya ga loncat atau dobel
+func parseSort(raw string, columns map[string]listColumn) ([]sortKey, error) {
+	keys := make([]sortKey, 0, 3)
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field, desc := strings.CutPrefix(part, "-")
+		column, ok := columns[field]
+		if !ok || seen[field] {
+			return nil, invalidSort(part, columns)
+		}
+		seen[field] = true
+		keys = append(keys, sortKey{field: field, column: column, desc: desc})
+	}
+	if !seen["id"] {
+		keys = append(keys, sortKey{field: "id", column: columns["id"]})
+	}
+	return keys, nil
+}
+
+func invalidSort(part string, columns map[string]listColumn) error {
+	allowed := make([]string, 0, len(columns))
+	for field := range columns {
+		allowed = append(allowed, field)
+	}
+	slices.Sort(allowed)
+	return &apperror.Error{
+		Kind:    apperror.ErrValidation,
+		Message: fmt.Sprintf("sort %q ga bisa dipake, pilihan: %s (prefix - buat descending)", part, strings.Join(allowed, ", ")),
+		Details: map[string]any{"field": "sort", "allowed": allowed},
+		Err:     ErrInvalidSortField,
+	}
+}
+
+// sortSpec - bentuk normal sort, disimpen di cursor biar cursor dari sort lain ketolak
+func sortSpec(keys []sortKey) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k.field
+		if k.desc {
+			parts[i] = "-" + k.field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// listCursor - isi cursor: nilai sort baris terakhir halaman sebelumnya.
+// Di-encode base64 biar client nganggep opaque, bukan buat nyembunyiin isinya
+type listCursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+func encodeCursor(keys []sortKey, values []any) string {
+	b, _ := json.Marshal(listCursor{Sort: sortSpec(keys), Values: values})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor - angka balik jadi int, teks jadi string, sesuai tipe kolomnya
+func decodeCursor(raw string, keys []sortKey) ([]any, error) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var c listCursor
+	if err := dec.Decode(&c); err != nil || c.Sort != sortSpec(keys) || len(c.Values) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	values := make([]any, len(keys))
+	for i, k := range keys {
+		switch v := c.Values[i].(type) {
+		case string:
+			if !k.column.text {
+				return nil, ErrInvalidCursor
+			}
+			values[i] = v
+		case json.Number:
+			n, err := strconv.Atoi(v.String())
+			if err != nil || k.column.text {
+				return nil, ErrInvalidCursor
+			}
+			values[i] = n
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+	return values, nil
+}
+
+// orderBy - isi ORDER BY, disusun cuma dari expr whitelist
+func orderBy(keys []sortKey) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k.column.expr + " ASC"
+		if k.desc {
+			parts[i] = k.column.expr + " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// keysetCondition - baris yang posisinya setelah cursor:
+// (a > $1) OR (a = $1 AND b < $2) OR (a = $1 AND b = $2 AND id > $3).
+// Ga pake row comparison (a, b) > ($1, $2) karena arah sort tiap kolom bisa beda.
+// first = nomor placeholder buat nilai key pertama, nilainya di-append urut sesuai keys
+func keysetCondition(keys []sortKey, first int) string {
+	ors := make([]string, len(keys))
+	for i, k := range keys {
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, fmt.Sprintf("%s = $%d", keys[j].column.expr, first+j))
+		}
+		op := ">"
+		if k.desc {
+			op = "<"
+		}
+		ands = append(ands, fmt.Sprintf("%s %s $%d", k.column.expr, op, first+i))
+		ors[i] = "(" + strings.Join(ands, " AND ") + ")"
+	}
+	return "(" + strings.Join(ors, " OR ") + ")"
+}
+
+// compareKeys - versi Go dari orderBy, dipake memory store. Teks dibandingin per byte,
+// sama kayak kolom teks yang di-sort COLLATE "C" di Postgres
+func compareKeys(keys []sortKey, a, b []any) int {
+	for i, k := range keys {
+		var c int
+		switch x := a[i].(type) {
+		case int:
+			c = cmp.Compare(x, b[i].(int))
+		case string:
+			c = strings.Compare(x, b[i].(string))
+		}
+		if k.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,287 @@
+package repositories
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"sort"
+	"sync"
+)
+
+// memoryCatalog - state bareng produk & kategori in-memory, biar cek FK
+// (produk -> kategori) bisa jalan di bawah satu lock kayak di Postgres
+type memoryCatalog struct {
+	mu             sync.RWMutex
+	products       map[int]models.Product
+	categories     map[int]models.Category
+	nextProductID  int
+	nextCategoryID int
+}
+
+// MemoryProductStore - ProductStore tanpa database buat unit test.
+// Semantiknya ngikutin ProductRepository: ID auto-increment, category wajib ada,
+// stok ga boleh negatif, stok cuma berubah lewat Create (stok awal). Audit log ga dicatat
+type MemoryProductStore struct {
+	catalog *memoryCatalog
+}
+
+// MemoryCategoryStore - CategoryStore tanpa database, share state sama MemoryProductStore
+type MemoryCategoryStore struct {
+	catalog *memoryCatalog
+}
+
+// NewMemoryStores - sepasang store yang share data, kayak dua tabel di satu database
+func NewMemoryStores() (*MemoryProductStore, *MemoryCategoryStore) {
+	catalog := &memoryCatalog{
+		products:       make(map[int]models.Product),
+		categories:     make(map[int]models.Category),
+		nextProductID:  1,
+		nextCategoryID: 1,
+	}
+	return &MemoryProductStore{catalog: catalog}, &MemoryCategoryStore{catalog: catalog}
+}
+
+func (s *MemoryProductStore) Create(product *models.Product, actor models.AuditActor) error {
+	c := s.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.categories[product.CategoryID]; !ok {
+		return unknownCategory(product.CategoryID)
+	}
+	if product.Stock < 0 {
+		return fmt.Errorf("%w: id %d (sisa %d, perubahan %d)", ErrInsufficientStock, c.nextProductID, 0, product.Stock)
+	}
+
+	product.ID = c.nextProductID
+	c.nextProductID++
+	stored := *product
+	stored.CategoryName = ""
+	stored.Margin, stored.MarginPercent = 0, 0
+	c.products[stored.ID] = stored
+	return nil
+}
+
+func (s *MemoryProductStore) GetAll(filter models.ProductFilter) (*models.ProductList, error) {
+	keys, after, err := parseListParams(filter.ListParams, productSortColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	c := s.catalog
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	products := make([]models.Product, 0, len(c.products))
+	for _, p := range c.products {
+		switch {
+		case filter.CategoryID != 0 && p.CategoryID != filter.CategoryID,
+			filter.MinPrice != nil && p.Price < *filter.MinPrice,
+			filter.MaxPrice != nil && p.Price > *filter.MaxPrice,
+			filter.InStock != nil && *filter.InStock != (p.Stock > 0):
+			continue
+		}
+		products = append(products, c.withCategoryName(p))
+	}
+
+	list := &models.ProductList{Limit: filter.Limit}
+	if filter.IncludeTotal {
+		total := len(products)
+		list.Total = &total
+	}
+	list.Data, list.NextCursor = pageOf(products, keys, after, filter.Limit, productKeyValues)
+	return list, nil
+}
+
+func (s *MemoryProductStore) GetByID(id int) (*models.Product, error) {
+	c := s.catalog
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	p, ok := c.products[id]
+	if !ok {
+		return nil, ErrProductNotFound
+	}
+	p = c.withCategoryName(p)
+	return &p, nil
+}
+
+// Update - sama kayak ProductRepository.Update: category_id ga ikut di-update,
+// cost_price nil = ga diubah, stok di body diabaikan (diisi stok sekarang)
+func (s *MemoryProductStore) Update(update *models.ProductUpdate, actor models.AuditActor) error {
+	product := &update.Product
+	c := s.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current, ok := c.products[product.ID]
+	if !ok {
+		return ErrProductNotFound
+	}
+
+	product.CostPrice = current.CostPrice
+	if update.CostPrice != nil {
+		product.CostPrice = *update.CostPrice
+	}
+	product.Stock = current.Stock
+	updated := current
+	updated.Name = product.Name
+	updated.Price = product.Price
+	updated.MinStock = product.MinStock
+	updated.ReorderQty = product.ReorderQty
+	updated.CostPrice = product.CostPrice
+	updated.TaxExempt = product.TaxExempt
+	c.products[product.ID] = updated
+	return nil
+}
+
+func (s *MemoryProductStore) Delete(id int, actor models.AuditActor) error {
+	c := s.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.products[id]; !ok {
+		return ErrProductNotFound
+	}
+	delete(c.products, id)
+	return nil
+}
+
+// GetLowStock - urutannya sama kayak query Postgres:
+// nama kategori, category_id, selisih stok ke min_stock, nama produk.
+// Produk tanpa kategori (c.name NULL) di paling belakang, kayak default NULLS LAST Postgres
+func (s *MemoryProductStore) GetLowStock() ([]models.Product, error) {
+	c := s.catalog
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	products := make([]models.Product, 0)
+	for _, p := range c.products {
+		if p.Stock <= p.MinStock {
+			products = append(products, c.withCategoryName(p))
+		}
+	}
+	sort.Slice(products, func(i, j int) bool {
+		a, b := products[i], products[j]
+		_, aCategorized := c.categories[a.CategoryID]
+		_, bCategorized := c.categories[b.CategoryID]
+		if aCategorized != bCategorized {
+			return aCategorized
+		}
+		if a.CategoryName != b.CategoryName {
+			return a.CategoryName < b.CategoryName
+		}
+		if a.CategoryID != b.CategoryID {
+			return a.CategoryID < b.CategoryID
+		}
+		if a.Stock-a.MinStock != b.Stock-b.MinStock {
+			return a.Stock-a.MinStock < b.Stock-b.MinStock
+		}
+		return a.Name < b.Name
+	})
+	return products, nil
+}
+
+// withCategoryName - pengganti LEFT JOIN categories
+func (c *memoryCatalog) withCategoryName(p models.Product) models.Product {
+	p.CategoryName = c.categories[p.CategoryID].Name
+	return p
+}
+
+func (s *MemoryCategoryStore) Create(category *models.Category, actor models.AuditActor) error {
+	c := s.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	category.ID = c.nextCategoryID
+	c.nextCategoryID++
+	c.categories[category.ID] = *category
+	return nil
+}
+
+func (s *MemoryCategoryStore) GetAll(filter models.CategoryFilter) (*models.CategoryList, error) {
+	keys, after, err := parseListParams(filter.ListParams, categorySortColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	c := s.catalog
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	categories := make([]models.Category, 0, len(c.categories))
+	for _, category := range c.categories {
+		if filter.TaxExempt != nil && category.TaxExempt != *filter.TaxExempt {
+			continue
+		}
+		categories = append(categories, category)
+	}
+
+	list := &models.CategoryList{Limit: filter.Limit}
+	if filter.IncludeTotal {
+		total := len(categories)
+		list.Total = &total
+	}
+	list.Data, list.NextCursor = pageOf(categories, keys, after, filter.Limit, categoryKeyValues)
+	return list, nil
+}
+
+func (s *MemoryCategoryStore) GetByID(id int) (*models.Category, error) {
+	c := s.catalog
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	category, ok := c.categories[id]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+	return &category, nil
+}
+
+func (s *MemoryCategoryStore) Update(category *models.Category, actor models.AuditActor) error {
+	c := s.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.categories[category.ID]; !ok {
+		return ErrCategoryNotFound
+	}
+	c.categories[category.ID] = *category
+	return nil
+}
+
+// Delete - kategori yang masih dipake produk ditolak, kayak FK di Postgres
+func (s *MemoryCategoryStore) Delete(id int, actor models.AuditActor) error {
+	c := s.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.categories[id]; !ok {
+		return ErrCategoryNotFound
+	}
+	for _, p := range c.products {
+		if p.CategoryID == id {
+			return ErrCategoryInUse
+		}
+	}
+	delete(c.categories, id)
+	return nil
+}
+
+// pageOf - versi in-memory dari ORDER BY + keyset + LIMIT di repo Postgres:
+// urutin sesuai keys, buang sampai baris cursor, ambil limit, bikin cursor halaman berikutnya
+func pageOf[T any](rows []T, keys []sortKey, after []any, limit int, values func(T, []sortKey) []any) ([]T, string) {
+	sort.Slice(rows, func(i, j int) bool {
+		return compareKeys(keys, values(rows[i], keys), values(rows[j], keys)) < 0
+	})
+	if after != nil {
+		start := sort.Search(len(rows), func(i int) bool {
+			return compareKeys(keys, values(rows[i], keys), after) > 0
+		})
+		rows = rows[start:]
+	}
+	if limit <= 0 || len(rows) <= limit {
+		return rows, ""
+	}
+	rows = rows[:limit]
+	return rows, encodeCursor(keys, values(rows[limit-1], keys))
+}
@@ -0,0 +1,455 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/internal/apperror"
+	"kasir-api/internal/loyalty"
+	"kasir-api/internal/tax"
+	"kasir-api/models"
+	"log/slog"
+	"time"
+)
+
+var (
+	ErrPendingSaleNotFound = apperror.New(apperror.ErrNotFound, "sale QRIS tidak ditemukan")
+	ErrSaleNotPending      = apperror.New(apperror.ErrConflict, "sale QRIS sudah tidak pending")
+)
+
+type PendingSaleRepository struct {
+	db     *sql.DB
+	sales  *TransactionRepository
+	logger *slog.Logger
+}
+
+// NewPendingSaleRepository - sale QRIS belum bisa pakai member, loyalty.Config kosong = ga ada poin
+func NewPendingSaleRepository(db *sql.DB, taxConfig tax.Config, location *time.Location, logger *slog.Logger) *PendingSaleRepository {
+	return &PendingSaleRepository{db: db, sales: NewTransactionRepository(db, taxConfig, loyalty.Config{}, location, logger), logger: logger}
+}
+
+const pendingSaleSelect = `
+	SELECT id, user_id, cashier, COALESCE(shift_id, 0), status, total_amount, stock_reserved, gateway,
+		COALESCE(charge_id, ''), qr_string, expires_at, COALESCE(transaction_id, 0), note, created_at, settled_at
+	FROM pending_sales`
+
+func scanPendingSale(row interface{ Scan(...interface{}) error }, s *models.PendingSale) error {
+	return row.Scan(&s.ID, &s.UserID, &s.Cashier, &s.ShiftID, &s.Status, &s.TotalAmount, &s.StockReserved, &s.Gateway,
+		&s.ChargeID, &s.QRString, &s.ExpiresAt, &s.TransactionID, &s.Note, &s.CreatedAt, &s.SettledAt)
+}
+
+// Create - kunci harga dari baris products sekarang plus promo yang berlaku, lalu simpan sale berstatus pending.
+// TotalAmount udah termasuk diskon & PPN, dihitung per baris persis kayak insertSale biar nominal QR
+// sama dengan total transaksi yang dibuat pas lunas (diskon tiap baris ikut di-snapshot).
+// Stok ga disentuh sama sekali, kecuali reserve = true: stok langsung dipesan lewat ledger
+// dan baru dilepas lagi pas sale lunas/expired/gagal. Wajib ada shift open kayak checkout biasa
+func (repo *PendingSaleRepository) Create(sale *models.PendingSale, items []models.CheckoutItem, reserve bool, actor models.AuditActor) ([]models.LowStockAlert, error) {
+	repo.logger.Info("Creating pending sale", "item_count", len(items), "cashier", sale.Cashier, "reserve", reserve)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	sale.ShiftID, err = lockOpenShift(tx, sale.UserID)
+	if err != nil {
+		repo.logger.Warn("Pending sale without open shift", "user_id", sale.UserID, "error", err)
+		return nil, err
+	}
+
+	sale.Items = make([]models.PendingSaleItem, 0, len(items))
+	lines := make([]models.CartLine, 0, len(items))
+	for _, item := range items {
+		// lock biar harga & stok yang dicek ga berubah sampai sale ini ke-commit
+		product, err := lockProduct(tx, item.ProductID)
+		if err == sql.ErrNoRows {
+			repo.logger.Warn("Product not found for pending sale", "product_id", item.ProductID)
+			return nil, fmt.Errorf("%w: id %d", ErrProductNotFound, item.ProductID)
+		}
+		if err != nil {
+			repo.logger.Error("Failed to lock product", "error", err, "product_id", item.ProductID)
+			return nil, err
+		}
+		if err := checkSaleLock(tx, product.CategoryID); err != nil {
+			repo.logger.Warn("Product locked by stock opname", "product_id", item.ProductID, "error", err)
+			return nil, fmt.Errorf("%w: %s", err, product.Name)
+		}
+		if product.Stock < item.Quantity {
+			repo.logger.Warn("Insufficient stock", "product_id", item.ProductID, "stock", product.Stock, "requested", item.Quantity)
+			return nil, fmt.Errorf("%w: %s (sisa %d, diminta %d)", ErrInsufficientStock, product.Name, product.Stock, item.Quantity)
+		}
+
+		var exempt bool
+		err = tx.QueryRow("SELECT "+taxExemptColumn+" FROM products p WHERE p.id = $1", product.ID).Scan(&exempt)
+		if err != nil {
+			repo.logger.Error("Failed to fetch tax exemption", "error", err, "product_id", item.ProductID)
+			return nil, err
+		}
+
+		lines = append(lines, models.CartLine{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			CategoryID:  product.CategoryID,
+			Price:       product.Price,
+			Quantity:    item.Quantity,
+			TaxExempt:   exempt,
+		})
+	}
+
+	price, err := repo.sales.priceCart(tx, lines)
+	if err != nil {
+		repo.logger.Error("Failed to apply promotions", "error", err)
+		return nil, err
+	}
+	sale.TotalAmount = price.Gross
+	for _, l := range price.Lines {
+		sale.Items = append(sale.Items, models.PendingSaleItem{
+			ProductID:    l.ProductID,
+			ProductName:  l.ProductName,
+			Price:        l.Price,
+			Quantity:     l.Quantity,
+			Subtotal:     l.Price * l.Quantity,
+			Discount:     l.Discount,
+			CartDiscount: l.CartDiscount,
+		})
+	}
+
+	sale.Status = models.SaleStatusPending
+	sale.StockReserved = reserve
+	err = tx.QueryRow(
+		`INSERT INTO pending_sales (user_id, cashier, shift_id, status, total_amount, stock_reserved, gateway, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at`,
+		sale.UserID, sale.Cashier, sale.ShiftID, sale.Status, sale.TotalAmount, reserve, sale.Gateway, sale.ExpiresAt,
+	).Scan(&sale.ID, &sale.CreatedAt)
+	if err != nil {
+		repo.logger.Error("Failed to insert pending sale", "error", err)
+		return nil, apperror.FromDB(err)
+	}
+
+	var alerts []models.LowStockAlert
+	for i := range sale.Items {
+		item := &sale.Items[i]
+		item.PendingSaleID = sale.ID
+		err := tx.QueryRow(
+			`INSERT INTO pending_sale_items (pending_sale_id, product_id, product_name, price, quantity, subtotal,
+				discount_amount, cart_discount)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+			sale.ID, item.ProductID, item.ProductName, item.Price, item.Quantity, item.Subtotal,
+			item.Discount, item.CartDiscount,
+		).Scan(&item.ID)
+		if err != nil {
+			repo.logger.Error("Failed to insert pending sale item", "error", err, "pending_sale_id", sale.ID)
+			return nil, apperror.FromDB(err)
+		}
+
+		if !reserve {
+			continue
+		}
+		lowStock, err := applyStockMovement(tx, &models.StockMovement{
+			ProductID:     item.ProductID,
+			Reason:        models.StockReasonReservation,
+			Quantity:      -item.Quantity,
+			ReferenceType: "pending_sale",
+			ReferenceID:   sale.ID,
+			CreatedBy:     sale.Cashier,
+		})
+		if err != nil {
+			repo.logger.Error("Failed to reserve stock", "error", err, "product_id", item.ProductID)
+			return nil, err
+		}
+		if lowStock != nil {
+			alerts = append(alerts, *lowStock)
+		}
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "pending_sale", sale.ID, nil, sale); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", sale.ID)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit pending sale", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Pending sale created", "id", sale.ID, "total_amount", sale.TotalAmount)
+	return alerts, nil
+}
+
+// SetCharge - simpan charge dari gateway. expires_at ikut punya gateway biar sweeper sinkron
+func (repo *PendingSaleRepository) SetCharge(id int, chargeID, qrString string, expiresAt time.Time) error {
+	repo.logger.Info("Setting pending sale charge", "id", id, "charge_id", chargeID)
+	res, err := repo.db.Exec(
+		"UPDATE pending_sales SET charge_id = $1, qr_string = $2, expires_at = $3 WHERE id = $4 AND status = 'pending'",
+		chargeID, qrString, expiresAt, id,
+	)
+	if err != nil {
+		repo.logger.Error("Failed to set pending sale charge", "error", err, "id", id)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrSaleNotPending
+	}
+	return nil
+}
+
+func (repo *PendingSaleRepository) GetByID(id int) (*models.PendingSale, error) {
+	repo.logger.Info("Fetching pending sale by ID", "id", id)
+	return repo.get(repo.db.QueryRow(pendingSaleSelect+" WHERE id = $1", id))
+}
+
+// GetByChargeID - dipake webhook, gateway cuma tau charge_id
+func (repo *PendingSaleRepository) GetByChargeID(chargeID string) (*models.PendingSale, error) {
+	repo.logger.Info("Fetching pending sale by charge ID", "charge_id", chargeID)
+	return repo.get(repo.db.QueryRow(pendingSaleSelect+" WHERE charge_id = $1", chargeID))
+}
+
+func (repo *PendingSaleRepository) get(row *sql.Row) (*models.PendingSale, error) {
+	var sale models.PendingSale
+	err := scanPendingSale(row, &sale)
+	if err == sql.ErrNoRows {
+		return nil, ErrPendingSaleNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to fetch pending sale", "error", err)
+		return nil, err
+	}
+
+	sale.Items, err = pendingSaleItems(repo.db, sale.ID)
+	if err != nil {
+		repo.logger.Error("Failed to fetch pending sale items", "error", err, "id", sale.ID)
+		return nil, err
+	}
+	return &sale, nil
+}
+
+// ListOverdue - sale pending yang udah lewat expires_at, buat disapu sweeper
+func (repo *PendingSaleRepository) ListOverdue() ([]models.PendingSale, error) {
+	rows, err := repo.db.Query(pendingSaleSelect + " WHERE status = 'pending' AND expires_at < NOW() ORDER BY id")
+	if err != nil {
+		repo.logger.Error("Failed to fetch overdue pending sales", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sales := make([]models.PendingSale, 0)
+	for rows.Next() {
+		var sale models.PendingSale
+		if err := scanPendingSale(rows, &sale); err != nil {
+			repo.logger.Error("Failed to scan pending sale", "error", err)
+			return nil, err
+		}
+		sales = append(sales, sale)
+	}
+	return sales, rows.Err()
+}
+
+// MarkPaid - pending -> paid. Reservasi (kalau ada) dilepas, lalu transaksi beneran dibuat
+// pake harga & diskon snapshot dan pembayaran qris (reference = charge_id), semua di SQL transaction yang sama.
+// Kalau sale udah paid, return apa adanya (webhook bisa dikirim berkali-kali).
+// Kalau transaksinya gagal dibuat (stok habis, produk di-lock opname), sale tetap paid karena uangnya
+// udah masuk, tapi transaction_id kosong dan alasannya dicatat di note buat ditangani manual
+func (repo *PendingSaleRepository) MarkPaid(id int) (*models.PendingSale, error) {
+	repo.logger.Info("Marking pending sale paid", "id", id)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	sale, err := lockPendingSale(tx, id)
+	if err != nil {
+		repo.logger.Warn("Failed to lock pending sale", "error", err, "id", id)
+		return nil, err
+	}
+	if sale.Status == models.SaleStatusPaid {
+		return sale, nil
+	}
+	if sale.Status != models.SaleStatusPending {
+		return nil, fmt.Errorf("%w: status %s", ErrSaleNotPending, sale.Status)
+	}
+
+	if err := repo.releaseReservation(tx, sale); err != nil {
+		return nil, err
+	}
+
+	req := &models.CheckoutRequest{
+		UserID:        sale.UserID,
+		Cashier:       sale.Cashier,
+		PaymentMethod: models.PaymentQRIS,
+		Payments:      []models.PaymentInput{{Method: models.PaymentQRIS, Amount: sale.TotalAmount, Reference: sale.ChargeID}},
+	}
+	snapshot := make(map[int]models.PendingSaleItem, len(sale.Items))
+	for _, item := range sale.Items {
+		req.Items = append(req.Items, models.CheckoutItem{ProductID: item.ProductID, Quantity: item.Quantity})
+		snapshot[item.ProductID] = item
+	}
+
+	// savepoint biar kalau insert transaksinya gagal, status paid tetap bisa disimpan
+	if _, err := tx.Exec("SAVEPOINT pending_sale_checkout"); err != nil {
+		repo.logger.Error("Failed to create savepoint", "error", err)
+		return nil, err
+	}
+	transaction, err := repo.sales.insertSale(tx, req, sale.ShiftID, snapshot)
+	if err != nil {
+		repo.logger.Error("Paid QRIS sale could not be converted to a transaction, needs manual handling",
+			"error", err, "id", sale.ID, "charge_id", sale.ChargeID)
+		if _, rbErr := tx.Exec("ROLLBACK TO SAVEPOINT pending_sale_checkout"); rbErr != nil {
+			repo.logger.Error("Failed to roll back to savepoint", "error", rbErr)
+			return nil, rbErr
+		}
+		sale.Note = "sudah dibayar tapi transaksi gagal dibuat: " + err.Error()
+	} else {
+		sale.Transaction = transaction
+		sale.TransactionID = transaction.ID
+	}
+
+	var transactionID interface{}
+	if sale.TransactionID != 0 {
+		transactionID = sale.TransactionID
+	}
+	sale.Status = models.SaleStatusPaid
+	sale.StockReserved = false
+	err = tx.QueryRow(
+		`UPDATE pending_sales SET status = $1, stock_reserved = FALSE, transaction_id = $2, note = $3, settled_at = NOW()
+		 WHERE id = $4 RETURNING settled_at`,
+		sale.Status, transactionID, sale.Note, sale.ID,
+	).Scan(&sale.SettledAt)
+	if err != nil {
+		repo.logger.Error("Failed to mark pending sale paid", "error", err, "id", sale.ID)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit pending sale payment", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Pending sale paid", "id", sale.ID, "transaction_id", sale.TransactionID)
+	return sale, nil
+}
+
+// Close - pending -> expired / failed, reservasi stok dilepas. Idempotent kayak MarkPaid.
+// Perubahan status dicatat di audit log atas nama actor (user yang batalin, atau system dari gateway/sweeper)
+func (repo *PendingSaleRepository) Close(id int, status, note string, actor models.AuditActor) (*models.PendingSale, error) {
+	repo.logger.Info("Closing pending sale", "id", id, "status", status)
+	if status != models.SaleStatusExpired && status != models.SaleStatusFailed {
+		return nil, fmt.Errorf("status penutup harus expired atau failed, bukan %s", status)
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	sale, err := lockPendingSale(tx, id)
+	if err != nil {
+		repo.logger.Warn("Failed to lock pending sale", "error", err, "id", id)
+		return nil, err
+	}
+	if sale.Status == status {
+		return sale, nil
+	}
+	if sale.Status != models.SaleStatusPending {
+		return nil, fmt.Errorf("%w: status %s", ErrSaleNotPending, sale.Status)
+	}
+
+	if err := repo.releaseReservation(tx, sale); err != nil {
+		return nil, err
+	}
+
+	before := *sale
+	sale.Status = status
+	sale.StockReserved = false
+	sale.Note = note
+	err = tx.QueryRow(
+		"UPDATE pending_sales SET status = $1, stock_reserved = FALSE, note = $2, settled_at = NOW() WHERE id = $3 RETURNING settled_at",
+		status, note, sale.ID,
+	).Scan(&sale.SettledAt)
+	if err != nil {
+		repo.logger.Error("Failed to close pending sale", "error", err, "id", sale.ID)
+		return nil, apperror.FromDB(err)
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionUpdate, "pending_sale", sale.ID, before, sale); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", sale.ID)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit pending sale close", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Pending sale closed", "id", sale.ID, "status", status)
+	return sale, nil
+}
+
+// releaseReservation - balikin stok yang dipesan pas sale dibuat, kalau ada
+func (repo *PendingSaleRepository) releaseReservation(tx *sql.Tx, sale *models.PendingSale) error {
+	if !sale.StockReserved {
+		return nil
+	}
+	for _, item := range sale.Items {
+		_, err := applyStockMovement(tx, &models.StockMovement{
+			ProductID:     item.ProductID,
+			Reason:        models.StockReasonRelease,
+			Quantity:      item.Quantity,
+			ReferenceType: "pending_sale",
+			ReferenceID:   sale.ID,
+			CreatedBy:     sale.Cashier,
+		})
+		if err != nil {
+			repo.logger.Error("Failed to release stock reservation", "error", err, "product_id", item.ProductID)
+			return err
+		}
+	}
+	return nil
+}
+
+// lockPendingSale - lock baris sale FOR UPDATE biar webhook & sweeper yang barengan ga dobel proses
+func lockPendingSale(tx *sql.Tx, id int) (*models.PendingSale, error) {
+	var sale models.PendingSale
+	err := scanPendingSale(tx.QueryRow(pendingSaleSelect+" WHERE id = $1 FOR UPDATE", id), &sale)
+	if err == sql.ErrNoRows {
+		return nil, ErrPendingSaleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	sale.Items, err = pendingSaleItems(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &sale, nil
+}
+
+func pendingSaleItems(q interface {
+	Query(string, ...interface{}) (*sql.Rows, error)
+}, saleID int) ([]models.PendingSaleItem, error) {
+	rows, err := q.Query(
+		`SELECT id, pending_sale_id, product_id, product_name, price, quantity, subtotal, discount_amount, cart_discount
+		 FROM pending_sale_items WHERE pending_sale_id = $1 ORDER BY id`,
+		saleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]models.PendingSaleItem, 0)
+	for rows.Next() {
+		var item models.PendingSaleItem
+		if err := rows.Scan(&item.ID, &item.PendingSaleID, &item.ProductID, &item.ProductName, &item.Price, &item.Quantity, &item.Subtotal,
+			&item.Discount, &item.CartDiscount); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
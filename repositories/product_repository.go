@@ -2,11 +2,15 @@ package repositories
 
 import (
 	"database/sql"
-	"errors"
+	"fmt"
+	"kasir-api/internal/apperror"
 	"kasir-api/models"
 	"log/slog"
 )
 
+// ErrProductInUse - produk yang udah pernah dijual / dipesan ga bisa dihapus, histori-nya nunjuk ke sini
+var ErrProductInUse = apperror.New(apperror.ErrForeignKey, "produk masih dipake di transaksi atau purchase order")
+
 type ProductRepository struct {
 	db     *sql.DB
 	logger *slog.Logger
@@ -16,28 +20,145 @@ func NewProductRepository(db *sql.DB, logger *slog.Logger) *ProductRepository {
 	return &ProductRepository{db: db, logger: logger}
 }
 
-func (repo *ProductRepository) Create(product *models.Product) error {
+// Create - produk disimpan dengan stok 0 dulu, stok awal masuk lewat ledger (atas nama actor)
+// di SQL transaction yang sama, begitu juga audit log-nya
+func (repo *ProductRepository) Create(product *models.Product, actor models.AuditActor) error {
+	repo.logger.Info("Creating product", "name", product.Name, "price", product.Price, "stock", product.Stock, "category_id", product.CategoryID)
 
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
 
-	repo.logger.Info("Creating product", "name", product.Name, "price", product.Price, "stock", product.Stock, "category_id", product.CategoryID)
-	query := "INSERT INTO products (name, price, stock, category_id) VALUES ($1, $2, $3, $4) RETURNING id"
-	err := repo.db.QueryRow(query, product.Name, product.Price, product.Stock, product.CategoryID).Scan(&product.ID)
+	query := "INSERT INTO products (name, price, cost_price, stock, min_stock, reorder_qty, category_id, tax_exempt) VALUES ($1, $2, $3, 0, $4, $5, $6, $7) RETURNING id"
+	err = tx.QueryRow(query, product.Name, product.Price, product.CostPrice, product.MinStock, product.ReorderQty, product.CategoryID, product.TaxExempt).Scan(&product.ID)
+	if apperror.IsForeignKeyViolation(err) {
+		repo.logger.Warn("Category not found for product", "category_id", product.CategoryID)
+		return unknownCategory(product.CategoryID)
+	}
 	if err != nil {
 		repo.logger.Error("Failed to create product", "error", err, "name", product.Name)
+		return apperror.FromDB(err)
+	}
+
+	if product.Stock != 0 {
+		_, err = applyStockMovement(tx, &models.StockMovement{
+			ProductID:     product.ID,
+			Reason:        models.StockReasonInitial,
+			Quantity:      product.Stock,
+			ReferenceType: "product",
+			ReferenceID:   product.ID,
+			CreatedBy:     actor.Username,
+		})
+		if err != nil {
+			repo.logger.Error("Failed to record initial stock", "error", err, "id", product.ID)
+			return err
+		}
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "product", product.ID, nil, product); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", product.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit product", "error", err)
 		return err
 	}
 	repo.logger.Info("Product created successfully", "id", product.ID, "name", product.Name)
 	return nil
 }
 
-func (repo *ProductRepository) GetAll() ([]models.Product, error) {
-	repo.logger.Info("Fetching all products")
-	query := `
-		SELECT p.id, p.name, p.price, p.stock, p.category_id, c.name as category_name
-		FROM products p 
+// productSortColumns - whitelist sort GET /api/produk. Nama di-sort COLLATE "C"
+// biar urutannya sama di semua server (dan sama kayak MemoryProductStore)
+var productSortColumns = map[string]listColumn{
+	"id":    {expr: "p.id"},
+	"name":  {expr: `p.name COLLATE "C"`, text: true},
+	"price": {expr: "p.price"},
+	"stock": {expr: "p.stock"},
+}
+
+// productKeyValues - nilai kolom sort satu produk, urut sesuai keys (buat cursor)
+func productKeyValues(p models.Product, keys []sortKey) []any {
+	values := make([]any, len(keys))
+	for i, k := range keys {
+		switch k.field {
+		case "id":
+			values[i] = p.ID
+		case "name":
+			values[i] = p.Name
+		case "price":
+			values[i] = p.Price
+		case "stock":
+			values[i] = p.Stock
+		}
+	}
+	return values
+}
+
+// GetAll - list produk sesuai filter pake keyset pagination (ambil Limit+1 buat tau ada halaman berikutnya).
+// Where clause pake placeholder $n, ORDER BY cuma dari productSortColumns, input user ga pernah di-concat
+func (repo *ProductRepository) GetAll(filter models.ProductFilter) (*models.ProductList, error) {
+	repo.logger.Info("Fetching products", "sort", filter.Sort, "limit", filter.Limit, "category_id", filter.CategoryID)
+	keys, after, err := parseListParams(filter.ListParams, productSortColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	where := "WHERE 1=1"
+	args := make([]interface{}, 0, 8)
+	if filter.CategoryID != 0 {
+		args = append(args, filter.CategoryID)
+		where += fmt.Sprintf(" AND p.category_id = $%d", len(args))
+	}
+	if filter.MinPrice != nil {
+		args = append(args, *filter.MinPrice)
+		where += fmt.Sprintf(" AND p.price >= $%d", len(args))
+	}
+	if filter.MaxPrice != nil {
+		args = append(args, *filter.MaxPrice)
+		where += fmt.Sprintf(" AND p.price <= $%d", len(args))
+	}
+	if filter.InStock != nil {
+		if *filter.InStock {
+			where += " AND p.stock > 0"
+		} else {
+			where += " AND p.stock <= 0"
+		}
+	}
+
+	list := &models.ProductList{Data: make([]models.Product, 0), Limit: filter.Limit}
+	if filter.IncludeTotal {
+		var total int
+		err := repo.db.QueryRow("SELECT COUNT(*) FROM products p "+where, args...).Scan(&total)
+		if err != nil {
+			repo.logger.Error("Failed to count products", "error", err)
+			return nil, err
+		}
+		list.Total = &total
+	}
+
+	if after != nil {
+		where += " AND " + keysetCondition(keys, len(args)+1)
+		args = append(args, after...)
+	}
+	limit := ""
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit+1)
+		limit = fmt.Sprintf("LIMIT $%d", len(args))
+	}
+	query := fmt.Sprintf(`
+		SELECT p.id, p.name, p.price, p.cost_price, p.stock, p.min_stock, p.reorder_qty, p.category_id, p.tax_exempt, c.name as category_name
+		FROM products p
 		LEFT JOIN categories c ON p.category_id = c.id
-	`
-	rows, err := repo.db.Query(query)
+		%s
+		ORDER BY %s
+		%s
+	`, where, orderBy(keys), limit)
+
+	rows, err := repo.db.Query(query, args...)
 	if err != nil {
 		repo.logger.Error("Failed to fetch products", "error", err)
 		return nil, err
@@ -46,41 +167,45 @@ func (repo *ProductRepository) GetAll() ([]models.Product, error) {
 	// jalan di akhir close connect db always
 
 	//kode di bawah buat ubah hasil query mentah jadi bentuk struct product
-	products := make([]models.Product, 0)
 	for rows.Next() {
 		var p models.Product
 
-		err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID, &p.CategoryName)
+		err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.CostPrice, &p.Stock, &p.MinStock, &p.ReorderQty, &p.CategoryID, &p.TaxExempt, &p.CategoryName)
 		if err != nil {
 			repo.logger.Error("Failed to scan product", "error", err)
 			return nil, err
 		}
-		//bentuk mentahnya: 1, "produk A", 1000, 10
+		list.Data = append(list.Data, p)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate products", "error", err)
+		return nil, err
+	}
 
-		//assign hasil slicing
-		products = append(products, p)
-		//bentuknya {{id:1, name:"produk A", price:1000, stock:10}, {id:2, name:"produk B", price:2000, stock:20}  }
+	if filter.Limit > 0 && len(list.Data) > filter.Limit {
+		list.Data = list.Data[:filter.Limit]
+		list.NextCursor = encodeCursor(keys, productKeyValues(list.Data[filter.Limit-1], keys))
 	}
 
-	repo.logger.Info("Successfully fetched products", "count", len(products))
-	return products, nil
+	repo.logger.Info("Successfully fetched products", "count", len(list.Data), "has_next", list.NextCursor != "")
+	return list, nil
 }
 
 // GetByID - ambil produk by ID
 func (repo *ProductRepository) GetByID(id int) (*models.Product, error) {
 	repo.logger.Info("Fetching product by ID", "id", id)
 	query := `
-		SELECT p.id, p.name, p.price, p.stock, p.category_id, c.name as category_name 
+		SELECT p.id, p.name, p.price, p.cost_price, p.stock, p.min_stock, p.reorder_qty, p.category_id, p.tax_exempt, c.name as category_name 
 		FROM products p
 		LEFT JOIN categories c ON p.category_id = c.id
 		WHERE p.id = $1
 	`
 
 	var p models.Product
-	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID, &p.CategoryName)
+	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Price, &p.CostPrice, &p.Stock, &p.MinStock, &p.ReorderQty, &p.CategoryID, &p.TaxExempt, &p.CategoryName)
 	if err == sql.ErrNoRows {
 		repo.logger.Warn("Product not found", "id", id)
-		return nil, errors.New("produk tidak ditemukan")
+		return nil, ErrProductNotFound
 	}
 	if err != nil {
 		repo.logger.Error("Failed to fetch product by ID", "error", err, "id", id)
@@ -91,52 +216,149 @@ func (repo *ProductRepository) GetByID(id int) (*models.Product, error) {
 	return &p, nil
 }
 
-func (repo *ProductRepository) Update(product *models.Product) error {
+// Update - stok ga ikut diubah sama sekali: stok di body diabaikan dan diisi stok sekarang.
+// Perubahan stok wajib lewat ledger (POST /api/produk/{id}/stock), biar client yang
+// kirim stok basi ga diam-diam ngebatalin penjualan yang terjadi di antaranya.
+// cost_price cuma diubah kalau dikirim (update.CostPrice != nil)
+func (repo *ProductRepository) Update(update *models.ProductUpdate, actor models.AuditActor) error {
+	product := &update.Product
 	repo.logger.Info("Updating product", "id", product.ID, "name", product.Name)
-	query := "UPDATE products SET name = $1, price = $2, stock = $3 WHERE id = $4"
-	//masi HARDCODE
 
-	result, err := repo.db.Exec(query, product.Name, product.Price, product.Stock, product.ID)
+	tx, err := repo.db.Begin()
 	if err != nil {
-		repo.logger.Error("Failed to update product", "error", err, "id", product.ID)
+		repo.logger.Error("Failed to begin transaction", "error", err)
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	before, err := lockProduct(tx, product.ID)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Product not found for update", "id", product.ID)
+		return ErrProductNotFound
+	}
 	if err != nil {
-		repo.logger.Error("Failed to get rows affected", "error", err, "id", product.ID)
+		repo.logger.Error("Failed to lock product", "error", err, "id", product.ID)
 		return err
 	}
 
-	if rows == 0 {
-		repo.logger.Warn("Product not found for update", "id", product.ID)
-		return errors.New("produk tidak ditemukan")
+	query := `UPDATE products SET name = $1, price = $2, min_stock = $3, reorder_qty = $4,
+		cost_price = COALESCE($5, cost_price), tax_exempt = $6
+		WHERE id = $7 RETURNING cost_price, stock`
+	//masi HARDCODE
+
+	err = tx.QueryRow(query, product.Name, product.Price, product.MinStock, product.ReorderQty, update.CostPrice, product.TaxExempt, product.ID).
+		Scan(&product.CostPrice, &product.Stock)
+	if err != nil {
+		repo.logger.Error("Failed to update product", "error", err, "id", product.ID)
+		return apperror.FromDB(err)
+	}
+
+	// category_id belum ikut di-update, jadi snapshot after pake category lama
+	after := *product
+	after.CategoryID = before.CategoryID
+	if err := insertAudit(tx, actor, models.AuditActionUpdate, "product", product.ID, before, after); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", product.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit product update", "error", err)
+		return err
 	}
 
 	repo.logger.Info("Product updated successfully", "id", product.ID, "name", product.Name)
 	return nil
 }
 
-func (repo *ProductRepository) Delete(id int) error {
+func (repo *ProductRepository) Delete(id int, actor models.AuditActor) error {
 	repo.logger.Info("Deleting product", "id", id)
-	query := "DELETE FROM products WHERE id = $1"
-	result, err := repo.db.Exec(query, id)
+
+	tx, err := repo.db.Begin()
 	if err != nil {
-		repo.logger.Error("Failed to delete product", "error", err, "id", id)
+		repo.logger.Error("Failed to begin transaction", "error", err)
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	before, err := lockProduct(tx, id)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Product not found for deletion", "id", id)
+		return ErrProductNotFound
+	}
 	if err != nil {
-		repo.logger.Error("Failed to get rows affected", "error", err, "id", id)
+		repo.logger.Error("Failed to lock product", "error", err, "id", id)
 		return err
 	}
 
-	if rows == 0 {
-		repo.logger.Warn("Product not found for deletion", "id", id)
-		return errors.New("produk tidak ditemukan")
+	if _, err := tx.Exec("DELETE FROM products WHERE id = $1", id); err != nil {
+		repo.logger.Error("Failed to delete product", "error", err, "id", id)
+		if apperror.IsForeignKeyViolation(err) {
+			return ErrProductInUse
+		}
+		return apperror.FromDB(err)
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionDelete, "product", id, before, nil); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", id)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit product delete", "error", err)
+		return err
 	}
 
 	repo.logger.Info("Product deleted successfully", "id", id)
-	return err
+	return nil
+}
+
+// lockProduct - ambil & lock baris produk, sekalian jadi snapshot "before" buat audit
+func lockProduct(tx *sql.Tx, id int) (*models.Product, error) {
+	var p models.Product
+	err := tx.QueryRow(
+		"SELECT id, name, price, cost_price, stock, min_stock, reorder_qty, category_id, tax_exempt FROM products WHERE id = $1 FOR UPDATE",
+		id,
+	).Scan(&p.ID, &p.Name, &p.Price, &p.CostPrice, &p.Stock, &p.MinStock, &p.ReorderQty, &p.CategoryID, &p.TaxExempt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetLowStock - produk dengan stok <= min_stock, urut per kategori
+// biar gampang dikelompokin di service. Produk lama tanpa kategori (category_id NULL)
+// ikut ke-list, di paling belakang
+func (repo *ProductRepository) GetLowStock() ([]models.Product, error) {
+	repo.logger.Info("Fetching low stock products")
+	query := `
+		SELECT p.id, p.name, p.price, p.cost_price, p.stock, p.min_stock, p.reorder_qty, COALESCE(p.category_id, 0), p.tax_exempt, COALESCE(c.name, '') as category_name
+		FROM products p
+		LEFT JOIN categories c ON p.category_id = c.id
+		WHERE p.stock <= p.min_stock
+		ORDER BY c.name, p.category_id, p.stock - p.min_stock, p.name
+	`
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		repo.logger.Error("Failed to fetch low stock products", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	for rows.Next() {
+		var p models.Product
+		err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.CostPrice, &p.Stock, &p.MinStock, &p.ReorderQty, &p.CategoryID, &p.TaxExempt, &p.CategoryName)
+		if err != nil {
+			repo.logger.Error("Failed to scan product", "error", err)
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate low stock products", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched low stock products", "count", len(products))
+	return products, nil
 }
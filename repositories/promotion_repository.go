@@ -0,0 +1,317 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"log/slog"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrPromotionNotFound       = apperror.New(apperror.ErrNotFound, "promo tidak ditemukan")
+	ErrPromotionTargetNotFound = apperror.New(apperror.ErrValidation, "produk atau kategori target promo tidak ditemukan")
+)
+
+type PromotionRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewPromotionRepository(db *sql.DB, logger *slog.Logger) *PromotionRepository {
+	return &PromotionRepository{db: db, logger: logger}
+}
+
+const promotionSelect = `
+	SELECT id, name, type, priority, stackable, active, starts_at, ends_at, days, product_ids, category_ids,
+		buy_qty, free_qty, percent, amount, min_subtotal, bundle_qty, bundle_price, created_at
+	FROM promotions`
+
+func scanPromotion(row interface{ Scan(...interface{}) error }, p *models.Promotion) error {
+	var days, productIDs, categoryIDs pq.Int64Array
+	err := row.Scan(&p.ID, &p.Name, &p.Type, &p.Priority, &p.Stackable, &p.Active, &p.StartsAt, &p.EndsAt,
+		&days, &productIDs, &categoryIDs, &p.BuyQty, &p.FreeQty, &p.Percent, &p.Amount, &p.MinSubtotal,
+		&p.BundleQty, &p.BundlePrice, &p.CreatedAt)
+	if err != nil {
+		return err
+	}
+	p.Days = toInts(days)
+	p.ProductIDs = toInts(productIDs)
+	p.CategoryIDs = toInts(categoryIDs)
+	return nil
+}
+
+func toInts(a pq.Int64Array) []int {
+	out := make([]int, len(a))
+	for i, v := range a {
+		out[i] = int(v)
+	}
+	return out
+}
+
+func toInt64s(a []int) pq.Int64Array {
+	out := make(pq.Int64Array, len(a))
+	for i, v := range a {
+		out[i] = int64(v)
+	}
+	return out
+}
+
+func (repo *PromotionRepository) Create(p *models.Promotion, actor models.AuditActor) error {
+	repo.logger.Info("Creating promotion", "name", p.Name, "type", p.Type)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkPromotionTargets(tx, p); err != nil {
+		repo.logger.Warn("Invalid promotion target", "error", err)
+		return err
+	}
+
+	err = tx.QueryRow(
+		`INSERT INTO promotions (name, type, priority, stackable, active, starts_at, ends_at, days, product_ids, category_ids,
+			buy_qty, free_qty, percent, amount, min_subtotal, bundle_qty, bundle_price)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		 RETURNING id, created_at`,
+		p.Name, p.Type, p.Priority, p.Stackable, p.Active, p.StartsAt, p.EndsAt,
+		toInt64s(p.Days), toInt64s(p.ProductIDs), toInt64s(p.CategoryIDs),
+		p.BuyQty, p.FreeQty, p.Percent, p.Amount, p.MinSubtotal, p.BundleQty, p.BundlePrice,
+	).Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		repo.logger.Error("Failed to create promotion", "error", err, "name", p.Name)
+		return apperror.FromDB(err)
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "promotion", p.ID, nil, p); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", p.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit promotion", "error", err)
+		return err
+	}
+	repo.logger.Info("Promotion created successfully", "id", p.ID)
+	return nil
+}
+
+// GetAll - activeOnly = cuma yang flag active, jendela waktu & hari dicek di evaluator
+func (repo *PromotionRepository) GetAll(activeOnly bool) ([]models.Promotion, error) {
+	repo.logger.Info("Fetching promotions", "active_only", activeOnly)
+	promotions, err := queryPromotions(repo.db, activeOnly)
+	if err != nil {
+		repo.logger.Error("Failed to fetch promotions", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched promotions", "count", len(promotions))
+	return promotions, nil
+}
+
+// queryPromotions - dipake GetAll dan checkout (di dalam tx-nya), jadi promo yang dihitung
+// pas checkout sama persis kayak yang dipake POST /api/cart/price
+func queryPromotions(q interface {
+	Query(string, ...interface{}) (*sql.Rows, error)
+}, activeOnly bool) ([]models.Promotion, error) {
+	query := promotionSelect
+	if activeOnly {
+		query += " WHERE active AND (ends_at IS NULL OR ends_at > NOW())"
+	}
+	rows, err := q.Query(query + " ORDER BY priority DESC, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := make([]models.Promotion, 0)
+	for rows.Next() {
+		var p models.Promotion
+		if err := scanPromotion(rows, &p); err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+	return promotions, rows.Err()
+}
+
+func (repo *PromotionRepository) GetByID(id int) (*models.Promotion, error) {
+	repo.logger.Info("Fetching promotion by ID", "id", id)
+	var p models.Promotion
+	err := scanPromotion(repo.db.QueryRow(promotionSelect+" WHERE id = $1", id), &p)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Promotion not found", "id", id)
+		return nil, ErrPromotionNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to fetch promotion by ID", "error", err, "id", id)
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (repo *PromotionRepository) Update(p *models.Promotion, actor models.AuditActor) error {
+	repo.logger.Info("Updating promotion", "id", p.ID)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var before models.Promotion
+	err = scanPromotion(tx.QueryRow(promotionSelect+" WHERE id = $1 FOR UPDATE", p.ID), &before)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Promotion not found for update", "id", p.ID)
+		return ErrPromotionNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to lock promotion", "error", err, "id", p.ID)
+		return err
+	}
+
+	if err := checkPromotionTargets(tx, p); err != nil {
+		repo.logger.Warn("Invalid promotion target", "error", err)
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE promotions SET name = $1, type = $2, priority = $3, stackable = $4, active = $5, starts_at = $6, ends_at = $7,
+			days = $8, product_ids = $9, category_ids = $10, buy_qty = $11, free_qty = $12, percent = $13, amount = $14,
+			min_subtotal = $15, bundle_qty = $16, bundle_price = $17
+		 WHERE id = $18`,
+		p.Name, p.Type, p.Priority, p.Stackable, p.Active, p.StartsAt, p.EndsAt,
+		toInt64s(p.Days), toInt64s(p.ProductIDs), toInt64s(p.CategoryIDs),
+		p.BuyQty, p.FreeQty, p.Percent, p.Amount, p.MinSubtotal, p.BundleQty, p.BundlePrice, p.ID,
+	)
+	if err != nil {
+		repo.logger.Error("Failed to update promotion", "error", err, "id", p.ID)
+		return apperror.FromDB(err)
+	}
+	p.CreatedAt = before.CreatedAt
+
+	if err := insertAudit(tx, actor, models.AuditActionUpdate, "promotion", p.ID, before, p); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", p.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit promotion update", "error", err)
+		return err
+	}
+	repo.logger.Info("Promotion updated successfully", "id", p.ID)
+	return nil
+}
+
+func (repo *PromotionRepository) Delete(id int, actor models.AuditActor) error {
+	repo.logger.Info("Deleting promotion", "id", id)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var before models.Promotion
+	err = scanPromotion(tx.QueryRow(promotionSelect+" WHERE id = $1 FOR UPDATE", id), &before)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Promotion not found for deletion", "id", id)
+		return ErrPromotionNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to lock promotion", "error", err, "id", id)
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM promotions WHERE id = $1", id); err != nil {
+		repo.logger.Error("Failed to delete promotion", "error", err, "id", id)
+		return apperror.FromDB(err)
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionDelete, "promotion", id, before, nil); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", id)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit promotion delete", "error", err)
+		return err
+	}
+	repo.logger.Info("Promotion deleted successfully", "id", id)
+	return nil
+}
+
+// CartProducts - data produk buat baris keranjang, urut sesuai items.
+// Produk yang ga ada bikin ErrProductNotFound
+func (repo *PromotionRepository) CartProducts(items []models.CheckoutItem) ([]models.CartLine, error) {
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductID
+	}
+	rows, err := repo.db.Query(
+		"SELECT p.id, p.name, p.price, COALESCE(p.category_id, 0), "+taxExemptColumn+" FROM products p WHERE p.id = ANY($1)",
+		toInt64s(ids),
+	)
+	if err != nil {
+		repo.logger.Error("Failed to fetch cart products", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make(map[int]models.CartLine, len(ids))
+	for rows.Next() {
+		var l models.CartLine
+		if err := rows.Scan(&l.ProductID, &l.ProductName, &l.Price, &l.CategoryID, &l.TaxExempt); err != nil {
+			repo.logger.Error("Failed to scan cart product", "error", err)
+			return nil, err
+		}
+		products[l.ProductID] = l
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate cart products", "error", err)
+		return nil, err
+	}
+
+	lines := make([]models.CartLine, 0, len(items))
+	for _, item := range items {
+		l, ok := products[item.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("%w: id %d", ErrProductNotFound, item.ProductID)
+		}
+		l.Quantity = item.Quantity
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
+
+// checkPromotionTargets - semua product_ids & category_ids harus beneran ada
+func checkPromotionTargets(tx *sql.Tx, p *models.Promotion) error {
+	var products, categories int
+	err := tx.QueryRow(
+		`SELECT (SELECT COUNT(DISTINCT id) FROM products WHERE id = ANY($1)),
+			(SELECT COUNT(DISTINCT id) FROM categories WHERE id = ANY($2))`,
+		toInt64s(p.ProductIDs), toInt64s(p.CategoryIDs),
+	).Scan(&products, &categories)
+	if err != nil {
+		return err
+	}
+	if products != countDistinct(p.ProductIDs) || categories != countDistinct(p.CategoryIDs) {
+		return ErrPromotionTargetNotFound
+	}
+	return nil
+}
+
+func countDistinct(ids []int) int {
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	return len(seen)
+}
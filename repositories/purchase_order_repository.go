@@ -0,0 +1,457 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"log/slog"
+	"sort"
+)
+
+var (
+	ErrPurchaseOrderNotFound = apperror.New(apperror.ErrNotFound, "purchase order tidak ditemukan")
+	ErrPurchaseOrderStatus   = apperror.New(apperror.ErrConflict, "status purchase order tidak valid untuk aksi ini")
+	ErrProductNotInPO        = apperror.New(apperror.ErrValidation, "produk tidak ada di purchase order ini")
+	ErrReceiveExceedsOrdered = apperror.New(apperror.ErrConflict, "jumlah diterima melebihi sisa yang dipesan")
+)
+
+type PurchaseOrderRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewPurchaseOrderRepository(db *sql.DB, logger *slog.Logger) *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{db: db, logger: logger}
+}
+
+// Create - header + item PO draft dan audit log-nya di satu SQL transaction
+func (repo *PurchaseOrderRepository) Create(req *models.CreatePurchaseOrderRequest, actor models.AuditActor) (int, error) {
+	repo.logger.Info("Creating purchase order", "supplier_id", req.SupplierID, "item_count", len(req.Items))
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM suppliers WHERE id = $1)", req.SupplierID).Scan(&exists)
+	if err != nil {
+		repo.logger.Error("Failed to check supplier", "error", err)
+		return 0, err
+	}
+	if !exists {
+		return 0, ErrSupplierNotFound
+	}
+
+	var id int
+	err = tx.QueryRow(
+		"INSERT INTO purchase_orders (supplier_id, status, note, created_by) VALUES ($1, $2, $3, $4) RETURNING id",
+		req.SupplierID, models.POStatusDraft, req.Note, req.User,
+	).Scan(&id)
+	if err != nil {
+		repo.logger.Error("Failed to insert purchase order", "error", err)
+		return 0, apperror.FromDB(err)
+	}
+
+	for _, item := range req.Items {
+		err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", item.ProductID).Scan(&exists)
+		if err != nil {
+			repo.logger.Error("Failed to check product", "error", err)
+			return 0, err
+		}
+		if !exists {
+			return 0, fmt.Errorf("%w: id %d", ErrProductNotFound, item.ProductID)
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO purchase_order_items (purchase_order_id, product_id, quantity_ordered, unit_cost)
+			 VALUES ($1, $2, $3, $4)`,
+			id, item.ProductID, item.Quantity, item.UnitCost,
+		)
+		if err != nil {
+			repo.logger.Error("Failed to insert purchase order item", "error", err, "product_id", item.ProductID)
+			return 0, apperror.FromDB(err)
+		}
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "purchase_order", id, nil, req); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", id)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit purchase order", "error", err)
+		return 0, err
+	}
+
+	repo.logger.Info("Purchase order created", "id", id)
+	return id, nil
+}
+
+const purchaseOrderSelect = `
+	SELECT po.id, po.supplier_id, s.name, po.status, po.note,
+		COALESCE((SELECT SUM(quantity_ordered * unit_cost) FROM purchase_order_items WHERE purchase_order_id = po.id), 0),
+		po.created_by, po.created_at, po.sent_at
+	FROM purchase_orders po
+	JOIN suppliers s ON s.id = po.supplier_id
+`
+
+func scanPurchaseOrder(row interface{ Scan(...interface{}) error }, po *models.PurchaseOrder) error {
+	var sentAt sql.NullTime
+	err := row.Scan(&po.ID, &po.SupplierID, &po.SupplierName, &po.Status, &po.Note, &po.TotalCost,
+		&po.CreatedBy, &po.CreatedAt, &sentAt)
+	if err != nil {
+		return err
+	}
+	if sentAt.Valid {
+		po.SentAt = &sentAt.Time
+	}
+	return nil
+}
+
+// GetAll - status kosong / supplierID 0 = ga difilter
+func (repo *PurchaseOrderRepository) GetAll(status string, supplierID int) ([]models.PurchaseOrder, error) {
+	repo.logger.Info("Fetching purchase orders", "status", status, "supplier_id", supplierID)
+	rows, err := repo.db.Query(purchaseOrderSelect+`
+		WHERE ($1 = '' OR po.status = $1) AND ($2 = 0 OR po.supplier_id = $2)
+		ORDER BY po.id DESC
+	`, status, supplierID)
+	if err != nil {
+		repo.logger.Error("Failed to fetch purchase orders", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]models.PurchaseOrder, 0)
+	for rows.Next() {
+		var po models.PurchaseOrder
+		if err := scanPurchaseOrder(rows, &po); err != nil {
+			repo.logger.Error("Failed to scan purchase order", "error", err)
+			return nil, err
+		}
+		orders = append(orders, po)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate purchase orders", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched purchase orders", "count", len(orders))
+	return orders, nil
+}
+
+// GetByID - header + item + riwayat penerimaan barang
+func (repo *PurchaseOrderRepository) GetByID(id int) (*models.PurchaseOrder, error) {
+	repo.logger.Info("Fetching purchase order by ID", "id", id)
+
+	var po models.PurchaseOrder
+	err := scanPurchaseOrder(repo.db.QueryRow(purchaseOrderSelect+" WHERE po.id = $1", id), &po)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Purchase order not found", "id", id)
+		return nil, ErrPurchaseOrderNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to fetch purchase order", "error", err, "id", id)
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT poi.id, poi.purchase_order_id, poi.product_id, p.name, poi.quantity_ordered, poi.quantity_received, poi.unit_cost
+		FROM purchase_order_items poi
+		JOIN products p ON p.id = poi.product_id
+		WHERE poi.purchase_order_id = $1
+		ORDER BY poi.id
+	`, id)
+	if err != nil {
+		repo.logger.Error("Failed to fetch purchase order items", "error", err, "id", id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	po.Items = make([]models.PurchaseOrderItem, 0)
+	for rows.Next() {
+		var item models.PurchaseOrderItem
+		err := rows.Scan(&item.ID, &item.PurchaseOrderID, &item.ProductID, &item.ProductName,
+			&item.QuantityOrdered, &item.QuantityReceived, &item.UnitCost)
+		if err != nil {
+			repo.logger.Error("Failed to scan purchase order item", "error", err)
+			return nil, err
+		}
+		po.Items = append(po.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate purchase order items", "error", err)
+		return nil, err
+	}
+
+	receiptRows, err := repo.db.Query(`
+		SELECT gr.id, gr.purchase_order_id, gr.note, gr.received_by, gr.created_at,
+			gri.id, gri.purchase_order_item_id, gri.product_id, gri.quantity, gri.unit_cost
+		FROM goods_receipts gr
+		JOIN goods_receipt_items gri ON gri.goods_receipt_id = gr.id
+		WHERE gr.purchase_order_id = $1
+		ORDER BY gr.id, gri.id
+	`, id)
+	if err != nil {
+		repo.logger.Error("Failed to fetch goods receipts", "error", err, "id", id)
+		return nil, err
+	}
+	defer receiptRows.Close()
+
+	po.Receipts = make([]models.GoodsReceipt, 0)
+	for receiptRows.Next() {
+		var gr models.GoodsReceipt
+		var item models.GoodsReceiptItem
+		err := receiptRows.Scan(&gr.ID, &gr.PurchaseOrderID, &gr.Note, &gr.ReceivedBy, &gr.CreatedAt,
+			&item.ID, &item.PurchaseOrderItemID, &item.ProductID, &item.Quantity, &item.UnitCost)
+		if err != nil {
+			repo.logger.Error("Failed to scan goods receipt", "error", err)
+			return nil, err
+		}
+		item.GoodsReceiptID = gr.ID
+		if n := len(po.Receipts); n == 0 || po.Receipts[n-1].ID != gr.ID {
+			po.Receipts = append(po.Receipts, gr)
+		}
+		last := &po.Receipts[len(po.Receipts)-1]
+		last.Items = append(last.Items, item)
+	}
+	if err := receiptRows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate goods receipts", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched purchase order", "id", id, "items", len(po.Items))
+	return &po, nil
+}
+
+// MarkSent - draft -> sent, dicatat di audit log di tx yang sama
+func (repo *PurchaseOrderRepository) MarkSent(id int, actor models.AuditActor) error {
+	repo.logger.Info("Marking purchase order sent", "id", id)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := lockPurchaseOrder(tx, id)
+	if err != nil {
+		if err != ErrPurchaseOrderNotFound {
+			repo.logger.Error("Failed to lock purchase order", "error", err, "id", id)
+		}
+		return err
+	}
+	if before.Status != models.POStatusDraft {
+		return ErrPurchaseOrderStatus
+	}
+
+	after := *before
+	err = tx.QueryRow(
+		"UPDATE purchase_orders SET status = $1, sent_at = NOW() WHERE id = $2 RETURNING status, sent_at",
+		models.POStatusSent, id,
+	).Scan(&after.Status, &after.SentAt)
+	if err != nil {
+		repo.logger.Error("Failed to mark purchase order sent", "error", err, "id", id)
+		return apperror.FromDB(err)
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionUpdate, "purchase_order", id, before, &after); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", id)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit purchase order sent", "error", err)
+		return err
+	}
+
+	repo.logger.Info("Purchase order marked sent", "id", id)
+	return nil
+}
+
+// lockPurchaseOrder - header PO di-lock FOR UPDATE, sekalian jadi snapshot "before" audit log
+func lockPurchaseOrder(tx *sql.Tx, id int) (*models.PurchaseOrder, error) {
+	var po models.PurchaseOrder
+	err := scanPurchaseOrder(tx.QueryRow(purchaseOrderSelect+" WHERE po.id = $1 FOR UPDATE OF po", id), &po)
+	if err == sql.ErrNoRows {
+		return nil, ErrPurchaseOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &po, nil
+}
+
+// Receive - penerimaan barang (boleh sebagian). Stok naik lewat ledger (reason restock)
+// di SQL transaction yang sama dengan dokumen penerimaan
+func (repo *PurchaseOrderRepository) Receive(id int, req *models.ReceiveGoodsRequest, actor models.AuditActor) (*models.GoodsReceipt, error) {
+	repo.logger.Info("Receiving goods", "purchase_order_id", id, "item_count", len(req.Items))
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := lockPurchaseOrder(tx, id)
+	if err != nil {
+		if err != ErrPurchaseOrderNotFound {
+			repo.logger.Error("Failed to lock purchase order", "error", err, "id", id)
+		}
+		return nil, err
+	}
+	if before.Status != models.POStatusSent && before.Status != models.POStatusPartial {
+		return nil, ErrPurchaseOrderStatus
+	}
+
+	receipt := models.GoodsReceipt{PurchaseOrderID: id, Note: req.Note, ReceivedBy: req.User}
+	err = tx.QueryRow(
+		"INSERT INTO goods_receipts (purchase_order_id, note, received_by) VALUES ($1, $2, $3) RETURNING id, created_at",
+		id, req.Note, req.User,
+	).Scan(&receipt.ID, &receipt.CreatedAt)
+	if err != nil {
+		repo.logger.Error("Failed to insert goods receipt", "error", err)
+		return nil, apperror.FromDB(err)
+	}
+
+	// urut product_id biar urutan lock products konsisten sama checkout
+	lines := make([]models.PurchaseOrderLine, len(req.Items))
+	copy(lines, req.Items)
+	sort.Slice(lines, func(i, j int) bool { return lines[i].ProductID < lines[j].ProductID })
+
+	for _, line := range lines {
+		item := models.GoodsReceiptItem{GoodsReceiptID: receipt.ID, ProductID: line.ProductID, Quantity: line.Quantity}
+		var ordered, received, poUnitCost int
+		err := tx.QueryRow(`
+			SELECT id, quantity_ordered, quantity_received, unit_cost
+			FROM purchase_order_items
+			WHERE purchase_order_id = $1 AND product_id = $2
+		`, id, line.ProductID).Scan(&item.PurchaseOrderItemID, &ordered, &received, &poUnitCost)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: product_id %d", ErrProductNotInPO, line.ProductID)
+		}
+		if err != nil {
+			repo.logger.Error("Failed to fetch purchase order item", "error", err, "product_id", line.ProductID)
+			return nil, err
+		}
+		if line.Quantity > ordered-received {
+			return nil, fmt.Errorf("%w: product_id %d (sisa %d, diterima %d)",
+				ErrReceiveExceedsOrdered, line.ProductID, ordered-received, line.Quantity)
+		}
+
+		item.UnitCost = line.UnitCost
+		if item.UnitCost == 0 {
+			item.UnitCost = poUnitCost
+		}
+
+		_, err = tx.Exec(
+			"UPDATE purchase_order_items SET quantity_received = quantity_received + $1 WHERE id = $2",
+			line.Quantity, item.PurchaseOrderItemID,
+		)
+		if err != nil {
+			repo.logger.Error("Failed to update received quantity", "error", err, "product_id", line.ProductID)
+			return nil, apperror.FromDB(err)
+		}
+
+		err = tx.QueryRow(
+			`INSERT INTO goods_receipt_items (goods_receipt_id, purchase_order_item_id, product_id, quantity, unit_cost)
+			 VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+			receipt.ID, item.PurchaseOrderItemID, item.ProductID, item.Quantity, item.UnitCost,
+		).Scan(&item.ID)
+		if err != nil {
+			repo.logger.Error("Failed to insert goods receipt item", "error", err, "product_id", line.ProductID)
+			return nil, apperror.FromDB(err)
+		}
+
+		if err := updateMovingAverageCost(tx, line.ProductID, line.Quantity, item.UnitCost); err != nil {
+			repo.logger.Error("Failed to update cost price", "error", err, "product_id", line.ProductID)
+			return nil, apperror.FromDB(err)
+		}
+
+		_, err = applyStockMovement(tx, &models.StockMovement{
+			ProductID:     line.ProductID,
+			Reason:        models.StockReasonRestock,
+			Quantity:      line.Quantity,
+			ReferenceType: "goods_receipt",
+			ReferenceID:   receipt.ID,
+			Note:          fmt.Sprintf("PO #%d", id),
+			CreatedBy:     req.User,
+		})
+		if err != nil {
+			repo.logger.Error("Failed to restock product", "error", err, "product_id", line.ProductID)
+			return nil, err
+		}
+
+		receipt.Items = append(receipt.Items, item)
+	}
+
+	// kalau semua item udah diterima penuh -> received, kalau belum -> partial
+	var outstanding int
+	err = tx.QueryRow(
+		"SELECT COUNT(*) FROM purchase_order_items WHERE purchase_order_id = $1 AND quantity_received < quantity_ordered", id,
+	).Scan(&outstanding)
+	if err != nil {
+		repo.logger.Error("Failed to check outstanding items", "error", err, "id", id)
+		return nil, err
+	}
+	newStatus := models.POStatusPartial
+	if outstanding == 0 {
+		newStatus = models.POStatusReceived
+	}
+	if _, err := tx.Exec("UPDATE purchase_orders SET status = $1 WHERE id = $2", newStatus, id); err != nil {
+		repo.logger.Error("Failed to update purchase order status", "error", err, "id", id)
+		return nil, apperror.FromDB(err)
+	}
+
+	// dua baris audit: dokumen penerimaan barunya, dan perubahan status PO-nya
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "goods_receipt", receipt.ID, nil, &receipt); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "receipt_id", receipt.ID)
+		return nil, err
+	}
+	after := *before
+	after.Status = newStatus
+	if err := insertAudit(tx, actor, models.AuditActionUpdate, "purchase_order", id, before, &after); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", id)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit goods receipt", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Goods received", "receipt_id", receipt.ID, "purchase_order_id", id, "status", newStatus)
+	return &receipt, nil
+}
+
+// updateMovingAverageCost - harga pokok baru = rata-rata tertimbang stok lama
+// dan barang yang baru diterima. Harus dipanggil sebelum stok ditambah.
+// Stok lama negatif/0 dianggap 0, jadi harga pokok langsung ikut unit cost baru
+func updateMovingAverageCost(tx *sql.Tx, productID, quantity, unitCost int) error {
+	var stock, costPrice int
+	err := tx.QueryRow("SELECT stock, cost_price FROM products WHERE id = $1 FOR UPDATE", productID).Scan(&stock, &costPrice)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: id %d", ErrProductNotFound, productID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if stock < 0 {
+		stock = 0
+	}
+	totalQty := stock + quantity
+	if totalQty <= 0 {
+		return nil
+	}
+	// dibulatin ke rupiah terdekat (half up)
+	newCost := (stock*costPrice + quantity*unitCost + totalQty/2) / totalQty
+
+	_, err = tx.Exec("UPDATE products SET cost_price = $1 WHERE id = $2", newCost, productID)
+	return err
+}
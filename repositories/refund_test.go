@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"testing"
+)
+
+// Refund cuma motong laci shift sebesar baris cash-nya, jadi yang dicek di sini bagian cash per refund
+func TestSplitRefundCashShare(t *testing.T) {
+	tests := []struct {
+		name           string
+		tenders        []tenderShare
+		refundedBefore int
+		amount         int
+		want           []models.RefundPayment
+	}{
+		{
+			name:    "cash only",
+			tenders: []tenderShare{{method: models.PaymentCash, paid: 50000}},
+			amount:  20000,
+			want:    []models.RefundPayment{{Method: models.PaymentCash, Amount: 20000}},
+		},
+		{
+			name:    "card plus a bit of cash",
+			tenders: []tenderShare{{method: models.PaymentCash, paid: 10000}, {method: models.PaymentDebit, paid: 90000}},
+			amount:  50000,
+			want:    []models.RefundPayment{{Method: models.PaymentCash, Amount: 5000}, {Method: models.PaymentDebit, Amount: 45000}},
+		},
+		{
+			name:    "rounding remainder goes to cash",
+			tenders: []tenderShare{{method: models.PaymentCash, paid: 1}, {method: models.PaymentQRIS, paid: 2}},
+			amount:  1,
+			want:    []models.RefundPayment{{Method: models.PaymentCash, Amount: 1}},
+		},
+		{
+			name: "last refund settles every tender exactly",
+			tenders: []tenderShare{
+				{method: models.PaymentCash, paid: 3333, refunded: 1111},
+				{method: models.PaymentDebit, paid: 6667, refunded: 2222},
+			},
+			refundedBefore: 3333,
+			amount:         6667,
+			want:           []models.RefundPayment{{Method: models.PaymentCash, Amount: 2222}, {Method: models.PaymentDebit, Amount: 4445}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitRefund(tt.tenders, tt.refundedBefore, tt.amount)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("splitRefund = %+v, want %+v", got, tt.want)
+			}
+			sum := 0
+			for _, p := range got {
+				sum += p.Amount
+			}
+			if sum != tt.amount {
+				t.Fatalf("sum = %d, want %d", sum, tt.amount)
+			}
+		})
+	}
+}
+
+// Refund bertahap satu-satu: total cash yang keluar dari laci ga boleh lebih dari cash yang masuk
+func TestSplitRefundInstalments(t *testing.T) {
+	tenders := []tenderShare{{method: models.PaymentCash, paid: 7001}, {method: models.PaymentCredit, paid: 12999}}
+	refunded := 0
+	for _, amount := range []int{3001, 4999, 7000, 5000} {
+		for _, p := range splitRefund(tenders, refunded, amount) {
+			for i := range tenders {
+				if tenders[i].method == p.Method {
+					tenders[i].refunded += p.Amount
+				}
+			}
+		}
+		refunded += amount
+		for _, tender := range tenders {
+			if tender.refunded > tender.paid {
+				t.Fatalf("after %d refunded, %s refunded %d > paid %d", refunded, tender.method, tender.refunded, tender.paid)
+			}
+		}
+	}
+	for _, tender := range tenders {
+		if tender.refunded != tender.paid {
+			t.Fatalf("%s refunded %d, want %d after full refund", tender.method, tender.refunded, tender.paid)
+		}
+	}
+}
+
+// Transaksi Rp30.000 dibayar 100 poin (Rp10.000) + cash Rp20.000, di-refund dua kali
+func TestRefundPointsTender(t *testing.T) {
+	tenders := []tenderShare{{method: models.PaymentCash, paid: 20000}, {method: models.PaymentPoints, paid: 10000}}
+	const pointsUsed = 100
+
+	refunded, restored := 0, 0
+	for _, step := range []struct{ amount, paidOut int }{{10000, 6667}, {20000, 13333}} {
+		payments := splitRefund(tenders, refunded, step.amount)
+		if got := refundPaidOut(payments); got != step.paidOut {
+			t.Fatalf("refund %d: paid out %d, want %d (%+v)", step.amount, got, step.paidOut, payments)
+		}
+		for _, p := range payments {
+			if p.Method == models.PaymentPoints {
+				restored += refundShare(pointsUsed, tenders[1].paid, tenders[1].refunded, p.Amount)
+				tenders[1].refunded += p.Amount
+			} else {
+				tenders[0].refunded += p.Amount
+			}
+		}
+		refunded += step.amount
+	}
+	if restored != pointsUsed || tenders[0].refunded != 20000 {
+		t.Fatalf("restored %d points and %d cash, want %d points and 20000 cash", restored, tenders[0].refunded, pointsUsed)
+	}
+}
@@ -0,0 +1,413 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log/slog"
+	"time"
+)
+
+type ReportRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewReportRepository(db *sql.DB, logger *slog.Logger) *ReportRepository {
+	return &ReportRepository{db: db, logger: logger}
+}
+
+// salesReportQuery - semua agregasi di SQL. ROLLUP bikin satu baris tambahan
+// dengan bucket NULL yang isinya total seluruh periode. Omzet & laba dari net_amount (DPP),
+// PPN yang dipungut bukan omzet toko, jadi cuma muncul di taxReportQuery.
+// $1 = unit date_trunc (day/week/month), $2 = timezone bisnis, $3/$4 = rentang waktu [from, to)
+const salesReportQuery = `
+	WITH sales AS (
+		SELECT date_trunc($1, t.created_at AT TIME ZONE $2) AS bucket,
+			COUNT(*) AS tx_count,
+			SUM(COALESCE(i.net, 0)) AS gross,
+			SUM(COALESCE(i.qty, 0)) AS items,
+			SUM(COALESCE(i.cost, 0)) AS cogs
+		FROM transactions t
+		LEFT JOIN (
+			SELECT transaction_id, SUM(quantity) AS qty, SUM(net_amount) AS net, SUM(quantity * cost_price) AS cost
+			FROM transaction_items
+			GROUP BY transaction_id
+		) i ON i.transaction_id = t.id
+		WHERE t.created_at >= $3 AND t.created_at < $4
+		GROUP BY ROLLUP (1)
+	),
+	refunded AS (
+		SELECT date_trunc($1, rf.created_at AT TIME ZONE $2) AS bucket,
+			COUNT(*) AS refund_count,
+			SUM(COALESCE(rc.net, 0)) AS refund_amount,
+			SUM(COALESCE(rc.cost, 0)) AS refund_cogs
+		FROM refunds rf
+		LEFT JOIN (
+			SELECT ri.refund_id, SUM(ri.net_amount) AS net, SUM(ri.quantity * ti.cost_price) AS cost
+			FROM refund_items ri
+			JOIN transaction_items ti ON ti.id = ri.transaction_item_id
+			GROUP BY ri.refund_id
+		) rc ON rc.refund_id = rf.id
+		WHERE rf.created_at >= $3 AND rf.created_at < $4
+		GROUP BY ROLLUP (1)
+	)
+	SELECT bucket, tx_count, gross, items, avg_basket_value, avg_basket_items, -refund_amount, refund_count,
+		net, cogs, net - cogs,
+		COALESCE(ROUND((net - cogs) * 100.0 / NULLIF(net, 0), 2), 0)::float8
+	FROM (
+		SELECT COALESCE(s.bucket, rf.bucket) AS bucket,
+			COALESCE(s.tx_count, 0) AS tx_count,
+			COALESCE(s.gross, 0) AS gross,
+			COALESCE(s.items, 0) AS items,
+			COALESCE(ROUND(s.gross::numeric / NULLIF(s.tx_count, 0)), 0)::bigint AS avg_basket_value,
+			COALESCE(ROUND(s.items::numeric / NULLIF(s.tx_count, 0), 2), 0)::float8 AS avg_basket_items,
+			COALESCE(rf.refund_amount, 0) AS refund_amount,
+			COALESCE(rf.refund_count, 0) AS refund_count,
+			COALESCE(s.gross, 0) - COALESCE(rf.refund_amount, 0) AS net,
+			COALESCE(s.cogs, 0) - COALESCE(rf.refund_cogs, 0) AS cogs
+		FROM sales s
+		FULL OUTER JOIN refunded rf ON s.bucket IS NOT DISTINCT FROM rf.bucket
+	) report
+	ORDER BY 1 NULLS LAST
+`
+
+// SalesReport - return bucket per periode + baris total.
+// groupBy harus udah divalidasi di service (day/week/month)
+func (repo *ReportRepository) SalesReport(from, to time.Time, groupBy, timezone string) ([]models.SalesReportRow, *models.SalesReportRow, error) {
+	repo.logger.Info("Fetching sales report", "from", from, "to", to, "group_by", groupBy, "timezone", timezone)
+
+	rows, err := repo.db.Query(salesReportQuery, groupBy, timezone, from, to)
+	if err != nil {
+		repo.logger.Error("Failed to fetch sales report", "error", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	buckets := make([]models.SalesReportRow, 0)
+	totals := &models.SalesReportRow{Period: "total"}
+	for rows.Next() {
+		var bucket sql.NullTime
+		var row models.SalesReportRow
+		err := rows.Scan(&bucket, &row.Transactions, &row.GrossRevenue, &row.ItemsSold, &row.AvgBasketValue,
+			&row.AvgBasketItems, &row.Refunds, &row.RefundCount, &row.NetRevenue,
+			&row.COGS, &row.GrossProfit, &row.GrossMarginPct)
+		if err != nil {
+			repo.logger.Error("Failed to scan sales report row", "error", err)
+			return nil, nil, err
+		}
+
+		// bucket NULL = baris ROLLUP (total)
+		if !bucket.Valid {
+			row.Period = "total"
+			totals = &row
+			continue
+		}
+		row.Period = bucket.Time.Format("2006-01-02")
+		buckets = append(buckets, row)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate sales report", "error", err)
+		return nil, nil, err
+	}
+
+	repo.logger.Info("Successfully fetched sales report", "buckets", len(buckets))
+	return buckets, totals, nil
+}
+
+// taxReportQuery - rekap PPN per bucket, bentuknya sama kayak salesReportQuery (ROLLUP + FULL OUTER JOIN).
+// Refund ikut flag bebas PPN dari baris transaksi asalnya.
+// $1 = unit date_trunc, $2 = timezone bisnis, $3/$4 = rentang waktu [from, to)
+const taxReportQuery = `
+	WITH sales AS (
+		SELECT date_trunc($1, t.created_at AT TIME ZONE $2) AS bucket,
+			COUNT(DISTINCT t.id) AS tx_count,
+			COALESCE(SUM(ti.net_amount) FILTER (WHERE NOT ti.tax_exempt), 0) AS taxable,
+			COALESCE(SUM(ti.tax_amount), 0) AS tax,
+			COALESCE(SUM(ti.net_amount) FILTER (WHERE ti.tax_exempt), 0) AS exempt
+		FROM transactions t
+		JOIN transaction_items ti ON ti.transaction_id = t.id
+		WHERE t.created_at >= $3 AND t.created_at < $4
+		GROUP BY ROLLUP (1)
+	),
+	refunded AS (
+		SELECT date_trunc($1, rf.created_at AT TIME ZONE $2) AS bucket,
+			COUNT(DISTINCT rf.id) AS refund_count,
+			COALESCE(SUM(ri.net_amount) FILTER (WHERE NOT ti.tax_exempt), 0) AS taxable,
+			COALESCE(SUM(ri.tax_amount), 0) AS tax,
+			COALESCE(SUM(ri.net_amount) FILTER (WHERE ti.tax_exempt), 0) AS exempt
+		FROM refunds rf
+		JOIN refund_items ri ON ri.refund_id = rf.id
+		JOIN transaction_items ti ON ti.id = ri.transaction_item_id
+		WHERE rf.created_at >= $3 AND rf.created_at < $4
+		GROUP BY ROLLUP (1)
+	)
+	SELECT COALESCE(s.bucket, rf.bucket),
+		COALESCE(s.tx_count, 0), COALESCE(s.taxable, 0), COALESCE(s.tax, 0), COALESCE(s.exempt, 0),
+		COALESCE(rf.refund_count, 0), -COALESCE(rf.taxable, 0), -COALESCE(rf.tax, 0), -COALESCE(rf.exempt, 0),
+		COALESCE(s.taxable, 0) - COALESCE(rf.taxable, 0),
+		COALESCE(s.tax, 0) - COALESCE(rf.tax, 0),
+		COALESCE(s.exempt, 0) - COALESCE(rf.exempt, 0)
+	FROM sales s
+	FULL OUTER JOIN refunded rf ON s.bucket IS NOT DISTINCT FROM rf.bucket
+	ORDER BY 1 NULLS LAST
+`
+
+// TaxReport - return bucket per periode + baris total.
+// groupBy harus udah divalidasi di service (day/week/month)
+func (repo *ReportRepository) TaxReport(from, to time.Time, groupBy, timezone string) ([]models.TaxReportRow, *models.TaxReportRow, error) {
+	repo.logger.Info("Fetching tax report", "from", from, "to", to, "group_by", groupBy, "timezone", timezone)
+
+	rows, err := repo.db.Query(taxReportQuery, groupBy, timezone, from, to)
+	if err != nil {
+		repo.logger.Error("Failed to fetch tax report", "error", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	buckets := make([]models.TaxReportRow, 0)
+	totals := &models.TaxReportRow{Period: "total"}
+	for rows.Next() {
+		var bucket sql.NullTime
+		var row models.TaxReportRow
+		err := rows.Scan(&bucket, &row.Transactions, &row.TaxableBase, &row.TaxCollected, &row.ExemptSales,
+			&row.RefundCount, &row.RefundedBase, &row.RefundedTax, &row.RefundedExempt,
+			&row.NetTaxableBase, &row.NetTax, &row.NetExemptSales)
+		if err != nil {
+			repo.logger.Error("Failed to scan tax report row", "error", err)
+			return nil, nil, err
+		}
+
+		// bucket NULL = baris ROLLUP (total)
+		if !bucket.Valid {
+			row.Period = "total"
+			totals = &row
+			continue
+		}
+		row.Period = bucket.Time.Format("2006-01-02")
+		buckets = append(buckets, row)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate tax report", "error", err)
+		return nil, nil, err
+	}
+
+	repo.logger.Info("Successfully fetched tax report", "buckets", len(buckets))
+	return buckets, totals, nil
+}
+
+// productSoldCTE - qty & revenue (DPP, tanpa PPN) bersih per produk di window [$1, $2)
+const productSoldCTE = `
+	WITH sold AS (
+		SELECT ti.product_id,
+			SUM(ti.quantity - COALESCE(r.qty, 0)) AS qty,
+			SUM(ti.net_amount - COALESCE(r.net, 0)) AS revenue
+		FROM transaction_items ti
+		JOIN transactions t ON t.id = ti.transaction_id
+		LEFT JOIN (
+			SELECT transaction_item_id, SUM(quantity) AS qty, SUM(net_amount) AS net
+			FROM refund_items
+			GROUP BY transaction_item_id
+		) r ON r.transaction_item_id = ti.id
+		WHERE t.created_at >= $1 AND t.created_at < $2
+		GROUP BY ti.product_id
+	),
+	last_sale AS (
+		SELECT ti.product_id, MAX(t.created_at) AS last_sold_at
+		FROM transaction_items ti
+		JOIN transactions t ON t.id = ti.transaction_id
+		GROUP BY ti.product_id
+	)
+`
+
+// productRankOrders - whitelist urutan ranking, jangan pernah isi dari input user langsung
+var productRankOrders = map[string]string{
+	"top_quantity":  "COALESCE(s.qty, 0) DESC, COALESCE(s.revenue, 0) DESC, p.id",
+	"top_revenue":   "COALESCE(s.revenue, 0) DESC, COALESCE(s.qty, 0) DESC, p.id",
+	"slow_quantity": "COALESCE(s.qty, 0) ASC, p.stock DESC, p.id",
+	"slow_revenue":  "COALESCE(s.revenue, 0) ASC, p.stock DESC, p.id",
+}
+
+// TopProducts - produk paling laku. Cuma produk yang ada penjualan di window
+func (repo *ReportRepository) TopProducts(filter models.ProductSalesFilter) ([]models.ProductSales, error) {
+	repo.logger.Info("Fetching top products", "from", filter.From, "to", filter.To, "sort", filter.Sort)
+	return repo.rankProducts("top_"+filter.Sort, "s.qty > 0", filter)
+}
+
+// SlowProducts - produk yang masih ada stok tapi penjualannya <= MaxQuantity di window
+func (repo *ReportRepository) SlowProducts(filter models.ProductSalesFilter) ([]models.ProductSales, error) {
+	repo.logger.Info("Fetching slow products", "from", filter.From, "to", filter.To, "max_quantity", filter.MaxQuantity)
+	return repo.rankProducts("slow_"+filter.Sort, "p.stock > 0 AND COALESCE(s.qty, 0) <= $6", filter, filter.MaxQuantity)
+}
+
+// rankProducts - orderKey & condition cuma dari konstanta di atas, nilai filter lewat placeholder.
+// $3 = ranking per kategori atau ngga, $4 = filter category_id (0 = semua), $5 = limit per grup,
+// extraArgs mulai dari $6
+func (repo *ReportRepository) rankProducts(orderKey, condition string, filter models.ProductSalesFilter, extraArgs ...interface{}) ([]models.ProductSales, error) {
+	order, ok := productRankOrders[orderKey]
+	if !ok {
+		return nil, fmt.Errorf("unknown product ranking %q", orderKey)
+	}
+
+	query := productSoldCTE + fmt.Sprintf(`
+		SELECT rank, id, name, category_id, category_name, stock, qty, revenue, last_sold_at
+		FROM (
+			SELECT p.id, p.name, p.category_id, COALESCE(c.name, '') AS category_name, p.stock,
+				COALESCE(s.qty, 0) AS qty, COALESCE(s.revenue, 0) AS revenue, ls.last_sold_at,
+				ROW_NUMBER() OVER (PARTITION BY CASE WHEN $3 THEN p.category_id END ORDER BY %s) AS rank
+			FROM products p
+			LEFT JOIN sold s ON s.product_id = p.id
+			LEFT JOIN last_sale ls ON ls.product_id = p.id
+			LEFT JOIN categories c ON c.id = p.category_id
+			WHERE %s AND ($4 = 0 OR p.category_id = $4)
+		) ranked
+		WHERE rank <= $5
+		ORDER BY CASE WHEN $3 THEN category_id END, rank
+	`, order, condition)
+
+	args := []interface{}{filter.From, filter.To, filter.ByCategory, filter.CategoryID, filter.Limit}
+	args = append(args, extraArgs...)
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		repo.logger.Error("Failed to rank products", "error", err, "ranking", orderKey)
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]models.ProductSales, 0)
+	for rows.Next() {
+		var p models.ProductSales
+		var lastSold sql.NullTime
+		err := rows.Scan(&p.Rank, &p.ProductID, &p.ProductName, &p.CategoryID, &p.CategoryName,
+			&p.Stock, &p.QuantitySold, &p.Revenue, &lastSold)
+		if err != nil {
+			repo.logger.Error("Failed to scan product ranking", "error", err)
+			return nil, err
+		}
+		if lastSold.Valid {
+			p.LastSoldAt = &lastSold.Time
+		}
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate product ranking", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully ranked products", "ranking", orderKey, "count", len(products))
+	return products, nil
+}
+
+// marginGroups - whitelist pengelompokan laporan margin: key & label SQL per group_by
+var marginGroups = map[string][2]string{
+	"product":  {"product_id::text", "MAX(product_name)"},
+	"category": {"category_id::text", "MAX(category_name)"},
+	"day":      {"to_char(day, 'YYYY-MM-DD')", "to_char(day, 'YYYY-MM-DD')"},
+}
+
+// MarginReport - omzet, HPP (COGS) dan laba kotor per produk/kategori/hari.
+// Beda sama SalesReport, refund di sini dihitung ke tanggal transaksi asal
+// (qty bersih per baris), biar margin per produk ga jadi aneh. Omzet = DPP (net_amount),
+// jadi margin barang yang sama ga beda antara mode PPN inclusive dan exclusive.
+func (repo *ReportRepository) MarginReport(from, to time.Time, groupBy, timezone string) ([]models.MarginReportRow, error) {
+	repo.logger.Info("Fetching margin report", "from", from, "to", to, "group_by", groupBy)
+
+	group, ok := marginGroups[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown margin group %q", groupBy)
+	}
+	order := "gross_profit DESC, 1"
+	if groupBy == "day" {
+		order = "1"
+	}
+
+	query := fmt.Sprintf(`
+		WITH lines AS (
+			SELECT ti.product_id, ti.product_name,
+				COALESCE(p.category_id, 0) AS category_id, COALESCE(c.name, '') AS category_name,
+				date_trunc('day', t.created_at AT TIME ZONE $3) AS day,
+				ti.quantity - COALESCE(r.qty, 0) AS qty,
+				ti.net_amount - COALESCE(r.net, 0) AS revenue,
+				(ti.quantity - COALESCE(r.qty, 0)) * ti.cost_price AS cogs
+			FROM transaction_items ti
+			JOIN transactions t ON t.id = ti.transaction_id
+			LEFT JOIN products p ON p.id = ti.product_id
+			LEFT JOIN categories c ON c.id = p.category_id
+			LEFT JOIN (
+				SELECT transaction_item_id, SUM(quantity) AS qty, SUM(net_amount) AS net
+				FROM refund_items
+				GROUP BY transaction_item_id
+			) r ON r.transaction_item_id = ti.id
+			WHERE t.created_at >= $1 AND t.created_at < $2
+		)
+		SELECT %s AS key, %s AS label, SUM(qty), SUM(revenue), SUM(cogs),
+			SUM(revenue) - SUM(cogs) AS gross_profit,
+			COALESCE(ROUND((SUM(revenue) - SUM(cogs)) * 100.0 / NULLIF(SUM(revenue), 0), 2), 0)::float8
+		FROM lines
+		GROUP BY 1
+		ORDER BY %s
+	`, group[0], group[1], order)
+
+	rows, err := repo.db.Query(query, from, to, timezone)
+	if err != nil {
+		repo.logger.Error("Failed to fetch margin report", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]models.MarginReportRow, 0)
+	for rows.Next() {
+		var row models.MarginReportRow
+		err := rows.Scan(&row.Key, &row.Label, &row.QuantitySold, &row.Revenue, &row.COGS, &row.GrossProfit, &row.GrossMarginPct)
+		if err != nil {
+			repo.logger.Error("Failed to scan margin report row", "error", err)
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate margin report", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched margin report", "rows", len(result))
+	return result, nil
+}
+
+// PaymentReport - penjualan per metode bayar di rentang [from, to).
+// Amount dari transaction_payments jadi cash udah bersih dari kembalian
+func (repo *ReportRepository) PaymentReport(from, to time.Time) ([]models.PaymentMethodTotal, error) {
+	repo.logger.Info("Fetching payment report", "from", from, "to", to)
+
+	rows, err := repo.db.Query(`
+		SELECT tp.method, COUNT(DISTINCT tp.transaction_id), SUM(tp.amount)
+		FROM transaction_payments tp
+		JOIN transactions t ON t.id = tp.transaction_id
+		WHERE t.created_at >= $1 AND t.created_at < $2
+		GROUP BY tp.method
+		ORDER BY 3 DESC, tp.method
+	`, from, to)
+	if err != nil {
+		repo.logger.Error("Failed to fetch payment report", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]models.PaymentMethodTotal, 0)
+	for rows.Next() {
+		var row models.PaymentMethodTotal
+		if err := rows.Scan(&row.Method, &row.Transactions, &row.Amount); err != nil {
+			repo.logger.Error("Failed to scan payment report row", "error", err)
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate payment report", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched payment report", "methods", len(result))
+	return result, nil
+}
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log/slog"
+)
+
+// seedActor - dicatat di created_by stock movement hasil seed
+const seedActor = "seed"
+
+// seedResetQuery - kosongin katalog plus semua data yang nunjuk ke produk/kategori
+// (item penjualan, refund, PO, opname, pending QRIS, ledger stok & poin), ID mulai dari 1 lagi.
+// User, shift, supplier dan audit log ga disentuh
+const seedResetQuery = `TRUNCATE products, categories, transactions, refunds, purchase_orders, goods_receipts,
+	stock_opnames, pending_sales, promotions, customers RESTART IDENTITY CASCADE`
+
+type SeedRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewSeedRepository(db *sql.DB, logger *slog.Logger) *SeedRepository {
+	return &SeedRepository{db: db, logger: logger}
+}
+
+// Seed - upsert kategori & produk berdasarkan nama dalam satu SQL transaction.
+// Produk nyari kategorinya lewat CategoryName. Stok produk yang udah ada cuma dikoreksi
+// kalau opts.ResetStock, lewat ledger (adjustment), biar seed ulang di database yang udah
+// jualan ga "benerin" stok asli balik ke angka dummy
+func (repo *SeedRepository) Seed(categories []models.Category, products []models.Product, opts models.SeedOptions) (*models.SeedResult, error) {
+	repo.logger.Info("Seeding catalog", "categories", len(categories), "products", len(products),
+		"reset", opts.Reset, "reset_stock", opts.ResetStock)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if opts.Reset {
+		if _, err := tx.Exec(seedResetQuery); err != nil {
+			repo.logger.Error("Failed to reset catalog", "error", err)
+			return nil, err
+		}
+	}
+
+	result := &models.SeedResult{StockChanges: make([]models.SeedStockChange, 0)}
+	categoryIDs := make(map[string]int, len(categories))
+	for _, category := range categories {
+		id, created, err := upsertSeedCategory(tx, category)
+		if err != nil {
+			repo.logger.Error("Failed to seed category", "error", err, "name", category.Name)
+			return nil, err
+		}
+		categoryIDs[category.Name] = id
+		if created {
+			result.CategoriesCreated++
+		} else {
+			result.CategoriesUpdated++
+		}
+	}
+
+	for _, product := range products {
+		categoryID, ok := categoryIDs[product.CategoryName]
+		if !ok {
+			return nil, fmt.Errorf("kategori %q produk %q ga ada di data seed", product.CategoryName, product.Name)
+		}
+		product.CategoryID = categoryID
+
+		created, change, err := upsertSeedProduct(tx, &product, opts.ResetStock)
+		if err != nil {
+			repo.logger.Error("Failed to seed product", "error", err, "name", product.Name)
+			return nil, err
+		}
+		if created {
+			result.ProductsCreated++
+		} else {
+			result.ProductsUpdated++
+		}
+		if change != nil {
+			result.StockChanges = append(result.StockChanges, *change)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit seed", "error", err)
+		return nil, err
+	}
+	repo.logger.Info("Successfully seeded catalog",
+		"categories_created", result.CategoriesCreated, "categories_updated", result.CategoriesUpdated,
+		"products_created", result.ProductsCreated, "products_updated", result.ProductsUpdated,
+		"stock_changes", len(result.StockChanges))
+	return result, nil
+}
+
+func upsertSeedCategory(tx *sql.Tx, category models.Category) (int, bool, error) {
+	var id int
+	err := tx.QueryRow("SELECT id FROM categories WHERE name = $1 ORDER BY id LIMIT 1 FOR UPDATE", category.Name).Scan(&id)
+	if err == sql.ErrNoRows {
+		err = tx.QueryRow(
+			"INSERT INTO categories (name, description, tax_exempt) VALUES ($1, $2, $3) RETURNING id",
+			category.Name, category.Description, category.TaxExempt,
+		).Scan(&id)
+		return id, true, err
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	_, err = tx.Exec("UPDATE categories SET description = $1 WHERE id = $2", category.Description, id)
+	return id, false, err
+}
+
+// upsertSeedProduct - produk baru stoknya masuk sebagai initial movement kayak Create.
+// Produk lama cuma dapet movement adjustment kalau resetStock dan stoknya beda dari data seed,
+// perubahannya di-return biar bisa ditampilin CLI
+func upsertSeedProduct(tx *sql.Tx, product *models.Product, resetStock bool) (bool, *models.SeedStockChange, error) {
+	var stock int
+	err := tx.QueryRow("SELECT id, stock FROM products WHERE name = $1 ORDER BY id LIMIT 1 FOR UPDATE", product.Name).Scan(&product.ID, &stock)
+	created := err == sql.ErrNoRows
+	reason := models.StockReasonAdjustment
+	switch {
+	case created:
+		reason = models.StockReasonInitial
+		err = tx.QueryRow(
+			"INSERT INTO products (name, price, cost_price, stock, min_stock, reorder_qty, category_id, tax_exempt) VALUES ($1, $2, $3, 0, $4, $5, $6, $7) RETURNING id",
+			product.Name, product.Price, product.CostPrice, product.MinStock, product.ReorderQty, product.CategoryID, product.TaxExempt,
+		).Scan(&product.ID)
+	case err == nil:
+		_, err = tx.Exec("UPDATE products SET price = $1, category_id = $2 WHERE id = $3", product.Price, product.CategoryID, product.ID)
+	}
+	if err != nil {
+		return false, nil, err
+	}
+
+	delta := product.Stock - stock
+	if delta == 0 || (!created && !resetStock) {
+		return created, nil, nil
+	}
+	_, err = applyStockMovement(tx, &models.StockMovement{
+		ProductID:     product.ID,
+		Reason:        reason,
+		Quantity:      delta,
+		ReferenceType: "product",
+		ReferenceID:   product.ID,
+		Note:          "seed",
+		CreatedBy:     seedActor,
+	})
+	if err != nil || created {
+		return created, nil, err
+	}
+	return false, &models.SeedStockChange{ProductID: product.ID, Name: product.Name, From: stock, To: product.Stock}, nil
+}
@@ -0,0 +1,342 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"log/slog"
+)
+
+var (
+	ErrShiftNotFound    = apperror.New(apperror.ErrNotFound, "shift tidak ditemukan")
+	ErrShiftNotOpen     = apperror.New(apperror.ErrConflict, "shift sudah ditutup")
+	ErrShiftAlreadyOpen = apperror.New(apperror.ErrConflict, "user ini masih punya shift yang belum ditutup")
+	ErrNoOpenShift      = apperror.New(apperror.ErrConflict, "belum buka shift, buka shift dulu sebelum transaksi")
+)
+
+type ShiftRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewShiftRepository(db *sql.DB, logger *slog.Logger) *ShiftRepository {
+	return &ShiftRepository{db: db, logger: logger}
+}
+
+// shiftSelect - header shift + ringkasan kas dihitung dari pembayaran cash (udah dipotong kembalian),
+// refund dan cash movement. Refund yang dihitung cuma bagian cash-nya (refund_payments), jadi
+// refund transaksi split (kartu + cash) ga motong laci sebesar total refund
+const shiftSelect = `
+	SELECT s.id, s.user_id, s.cashier, s.status, s.opening_float,
+		COALESCE((SELECT SUM(tp.amount) FROM transaction_payments tp JOIN transactions t ON t.id = tp.transaction_id
+			WHERE t.shift_id = s.id AND tp.method = 'cash'), 0),
+		COALESCE((SELECT SUM(rp.amount) FROM refund_payments rp JOIN refunds rf ON rf.id = rp.refund_id
+			WHERE rf.shift_id = s.id AND rp.method = 'cash'), 0),
+		COALESCE((SELECT SUM(cm.amount) FROM cash_movements cm WHERE cm.shift_id = s.id AND cm.type = 'in'), 0),
+		COALESCE((SELECT SUM(cm.amount) FROM cash_movements cm WHERE cm.shift_id = s.id AND cm.type = 'out'), 0),
+		s.counted_cash, s.difference, s.note, s.closing_note, s.opened_at, s.closed_at
+	FROM shifts s`
+
+func scanShift(row interface{ Scan(...interface{}) error }, s *models.Shift) error {
+	var counted, difference sql.NullInt64
+	var closedAt sql.NullTime
+	err := row.Scan(&s.ID, &s.UserID, &s.Cashier, &s.Status, &s.OpeningFloat,
+		&s.CashSales, &s.CashRefunds, &s.CashIn, &s.CashOut,
+		&counted, &difference, &s.Note, &s.ClosingNote, &s.OpenedAt, &closedAt)
+	if err != nil {
+		return err
+	}
+	s.ExpectedCash = s.OpeningFloat + s.CashSales - s.CashRefunds + s.CashIn - s.CashOut
+	if counted.Valid {
+		v := int(counted.Int64)
+		s.CountedCash = &v
+	}
+	if difference.Valid {
+		v := int(difference.Int64)
+		s.Difference = &v
+	}
+	if closedAt.Valid {
+		s.ClosedAt = &closedAt.Time
+	}
+	return nil
+}
+
+func (repo *ShiftRepository) Open(shift *models.Shift, actor models.AuditActor) error {
+	repo.logger.Info("Opening shift", "user_id", shift.UserID, "opening_float", shift.OpeningFloat)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"INSERT INTO shifts (user_id, cashier, opening_float, note) VALUES ($1, $2, $3, $4) RETURNING id, status, opened_at",
+		shift.UserID, shift.Cashier, shift.OpeningFloat, shift.Note,
+	).Scan(&shift.ID, &shift.Status, &shift.OpenedAt)
+	if err != nil {
+		if apperror.IsUniqueViolation(err) {
+			repo.logger.Warn("User already has an open shift", "user_id", shift.UserID)
+			return ErrShiftAlreadyOpen
+		}
+		repo.logger.Error("Failed to open shift", "error", err, "user_id", shift.UserID)
+		return apperror.FromDB(err)
+	}
+	shift.ExpectedCash = shift.OpeningFloat
+
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "shift", shift.ID, nil, shift); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", shift.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit shift open", "error", err)
+		return err
+	}
+	repo.logger.Info("Shift opened successfully", "id", shift.ID, "user_id", shift.UserID)
+	return nil
+}
+
+func (repo *ShiftRepository) GetByID(id int) (*models.Shift, error) {
+	repo.logger.Info("Fetching shift by ID", "id", id)
+	var s models.Shift
+	err := scanShift(repo.db.QueryRow(shiftSelect+" WHERE s.id = $1", id), &s)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Shift not found", "id", id)
+		return nil, ErrShiftNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to fetch shift", "error", err, "id", id)
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(
+		"SELECT id, shift_id, type, amount, reason, created_by, created_at FROM cash_movements WHERE shift_id = $1 ORDER BY id",
+		id,
+	)
+	if err != nil {
+		repo.logger.Error("Failed to fetch cash movements", "error", err, "id", id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	s.Movements = make([]models.CashMovement, 0)
+	for rows.Next() {
+		var m models.CashMovement
+		if err := rows.Scan(&m.ID, &m.ShiftID, &m.Type, &m.Amount, &m.Reason, &m.CreatedBy, &m.CreatedAt); err != nil {
+			repo.logger.Error("Failed to scan cash movement", "error", err)
+			return nil, err
+		}
+		s.Movements = append(s.Movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate cash movements", "error", err)
+		return nil, err
+	}
+
+	s.Payments, err = repo.paymentTotals(id)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched shift", "id", id, "status", s.Status)
+	return &s, nil
+}
+
+// paymentTotals - penjualan shift dipecah per metode bayar
+func (repo *ShiftRepository) paymentTotals(shiftID int) ([]models.PaymentMethodTotal, error) {
+	rows, err := repo.db.Query(`
+		SELECT tp.method, COUNT(DISTINCT tp.transaction_id), SUM(tp.amount)
+		FROM transaction_payments tp
+		JOIN transactions t ON t.id = tp.transaction_id
+		WHERE t.shift_id = $1
+		GROUP BY tp.method
+		ORDER BY tp.method
+	`, shiftID)
+	if err != nil {
+		repo.logger.Error("Failed to fetch shift payment totals", "error", err, "id", shiftID)
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make([]models.PaymentMethodTotal, 0)
+	for rows.Next() {
+		var t models.PaymentMethodTotal
+		if err := rows.Scan(&t.Method, &t.Transactions, &t.Amount); err != nil {
+			repo.logger.Error("Failed to scan shift payment total", "error", err)
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
+// GetOpenByUser - shift yang lagi buka punya user ini
+func (repo *ShiftRepository) GetOpenByUser(userID int) (*models.Shift, error) {
+	var id int
+	err := repo.db.QueryRow("SELECT id FROM shifts WHERE user_id = $1 AND status = 'open'", userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoOpenShift
+	}
+	if err != nil {
+		repo.logger.Error("Failed to fetch open shift", "error", err, "user_id", userID)
+		return nil, err
+	}
+	return repo.GetByID(id)
+}
+
+func (repo *ShiftRepository) GetAll(filter models.ShiftFilter) ([]models.Shift, error) {
+	repo.logger.Info("Fetching shifts", "status", filter.Status, "user_id", filter.UserID)
+
+	where := " WHERE 1=1"
+	args := make([]interface{}, 0, 2)
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(" AND s.status = $%d", len(args))
+	}
+	if filter.UserID > 0 {
+		args = append(args, filter.UserID)
+		where += fmt.Sprintf(" AND s.user_id = $%d", len(args))
+	}
+
+	rows, err := repo.db.Query(shiftSelect+where+" ORDER BY s.opened_at DESC, s.id DESC", args...)
+	if err != nil {
+		repo.logger.Error("Failed to fetch shifts", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := make([]models.Shift, 0)
+	for rows.Next() {
+		var s models.Shift
+		if err := scanShift(rows, &s); err != nil {
+			repo.logger.Error("Failed to scan shift", "error", err)
+			return nil, err
+		}
+		shifts = append(shifts, s)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate shifts", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched shifts", "count", len(shifts))
+	return shifts, nil
+}
+
+// AddCashMovement - shift di-lock FOR SHARE biar ga bisa ditutup di tengah jalan
+func (repo *ShiftRepository) AddCashMovement(m *models.CashMovement, actor models.AuditActor) error {
+	repo.logger.Info("Recording cash movement", "shift_id", m.ShiftID, "type", m.Type, "amount", m.Amount)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockShift(tx, m.ShiftID, "FOR SHARE"); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(
+		"INSERT INTO cash_movements (shift_id, type, amount, reason, created_by) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		m.ShiftID, m.Type, m.Amount, m.Reason, m.CreatedBy,
+	).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		repo.logger.Error("Failed to insert cash movement", "error", err, "shift_id", m.ShiftID)
+		return apperror.FromDB(err)
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "cash_movement", m.ID, nil, m); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", m.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit cash movement", "error", err)
+		return err
+	}
+	repo.logger.Info("Cash movement recorded", "id", m.ID, "shift_id", m.ShiftID)
+	return nil
+}
+
+// Close - shift di-lock FOR UPDATE, jadi checkout/refund yang lagi jalan (FOR SHARE)
+// selesai dulu sebelum expected cash dihitung
+func (repo *ShiftRepository) Close(id int, countedCash int, note string, actor models.AuditActor) error {
+	repo.logger.Info("Closing shift", "id", id, "counted_cash", countedCash)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockShift(tx, id, "FOR UPDATE"); err != nil {
+		return err
+	}
+
+	var s models.Shift
+	if err := scanShift(tx.QueryRow(shiftSelect+" WHERE s.id = $1", id), &s); err != nil {
+		repo.logger.Error("Failed to compute shift summary", "error", err, "id", id)
+		return err
+	}
+
+	difference := countedCash - s.ExpectedCash
+	after := s
+	err = tx.QueryRow(`
+		UPDATE shifts SET status = 'closed', expected_cash = $1, counted_cash = $2, difference = $3,
+			closing_note = $4, closed_at = NOW()
+		WHERE id = $5
+		RETURNING status, closed_at
+	`, s.ExpectedCash, countedCash, difference, note, id).Scan(&after.Status, &after.ClosedAt)
+	if err != nil {
+		repo.logger.Error("Failed to close shift", "error", err, "id", id)
+		return apperror.FromDB(err)
+	}
+	after.CountedCash = &countedCash
+	after.Difference = &difference
+	after.ClosingNote = note
+
+	if err := insertAudit(tx, actor, models.AuditActionUpdate, "shift", id, s, after); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", id)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit shift close", "error", err)
+		return err
+	}
+	repo.logger.Info("Shift closed", "id", id, "expected_cash", s.ExpectedCash, "difference", difference)
+	return nil
+}
+
+// lockShift - lock baris shift dan pastiin masih open. mode: "FOR SHARE" / "FOR UPDATE"
+func lockShift(tx *sql.Tx, id int, mode string) error {
+	var status string
+	err := tx.QueryRow("SELECT status FROM shifts WHERE id = $1 "+mode, id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ErrShiftNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status != models.ShiftStatusOpen {
+		return ErrShiftNotOpen
+	}
+	return nil
+}
+
+// lockOpenShift - shift open milik user, di-lock FOR SHARE selama checkout/refund.
+// Return 0 + ErrNoOpenShift kalau user belum buka shift
+func lockOpenShift(tx *sql.Tx, userID int) (int, error) {
+	var id int
+	err := tx.QueryRow("SELECT id FROM shifts WHERE user_id = $1 AND status = 'open' FOR SHARE", userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, ErrNoOpenShift
+	}
+	return id, err
+}
@@ -0,0 +1,412 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"log/slog"
+)
+
+var (
+	ErrStockOpnameNotFound = apperror.New(apperror.ErrNotFound, "sesi stock opname tidak ditemukan")
+	ErrStockOpnameNotOpen  = apperror.New(apperror.ErrConflict, "sesi stock opname sudah tidak open")
+	ErrProductNotInOpname  = apperror.New(apperror.ErrValidation, "produk di luar kategori sesi stock opname")
+	ErrProductLocked       = apperror.New(apperror.ErrConflict, "produk sedang dihitung (stock opname), tidak bisa dijual")
+)
+
+type StockOpnameRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewStockOpnameRepository(db *sql.DB, logger *slog.Logger) *StockOpnameRepository {
+	return &StockOpnameRepository{db: db, logger: logger}
+}
+
+// Create - buka sesi baru, dicatat juga di audit log
+func (repo *StockOpnameRepository) Create(opname *models.StockOpname, actor models.AuditActor) error {
+	repo.logger.Info("Creating stock opname", "category_id", opname.CategoryID, "lock_sales", opname.LockSales)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var categoryID interface{}
+	if opname.CategoryID != 0 {
+		categoryID = opname.CategoryID
+	}
+	err = tx.QueryRow(
+		`INSERT INTO stock_opnames (status, category_id, lock_sales, note, created_by)
+		 VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		models.OpnameStatusOpen, categoryID, opname.LockSales, opname.Note, opname.CreatedBy,
+	).Scan(&opname.ID, &opname.CreatedAt)
+	if err != nil {
+		repo.logger.Error("Failed to create stock opname", "error", err)
+		return apperror.FromDB(err)
+	}
+	opname.Status = models.OpnameStatusOpen
+
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "stock_opname", opname.ID, nil, opname); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", opname.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit stock opname", "error", err)
+		return err
+	}
+
+	repo.logger.Info("Stock opname created", "id", opname.ID)
+	return nil
+}
+
+const stockOpnameColumns = `id, status, COALESCE(category_id, 0), lock_sales, note, created_by, created_at, posted_by, posted_at`
+
+func scanStockOpname(row interface{ Scan(...interface{}) error }, o *models.StockOpname) error {
+	var postedAt sql.NullTime
+	err := row.Scan(&o.ID, &o.Status, &o.CategoryID, &o.LockSales, &o.Note, &o.CreatedBy, &o.CreatedAt, &o.PostedBy, &postedAt)
+	if err != nil {
+		return err
+	}
+	if postedAt.Valid {
+		o.PostedAt = &postedAt.Time
+	}
+	return nil
+}
+
+// GetAll - status kosong = semua sesi
+func (repo *StockOpnameRepository) GetAll(status string) ([]models.StockOpname, error) {
+	repo.logger.Info("Fetching stock opnames", "status", status)
+	rows, err := repo.db.Query(
+		"SELECT "+stockOpnameColumns+" FROM stock_opnames WHERE ($1 = '' OR status = $1) ORDER BY id DESC", status,
+	)
+	if err != nil {
+		repo.logger.Error("Failed to fetch stock opnames", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	opnames := make([]models.StockOpname, 0)
+	for rows.Next() {
+		var o models.StockOpname
+		if err := scanStockOpname(rows, &o); err != nil {
+			repo.logger.Error("Failed to scan stock opname", "error", err)
+			return nil, err
+		}
+		opnames = append(opnames, o)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate stock opnames", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched stock opnames", "count", len(opnames))
+	return opnames, nil
+}
+
+// GetByID - sesi + hasil hitung dan selisihnya
+func (repo *StockOpnameRepository) GetByID(id int) (*models.StockOpname, error) {
+	repo.logger.Info("Fetching stock opname by ID", "id", id)
+
+	var o models.StockOpname
+	err := scanStockOpname(repo.db.QueryRow("SELECT "+stockOpnameColumns+" FROM stock_opnames WHERE id = $1", id), &o)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Stock opname not found", "id", id)
+		return nil, ErrStockOpnameNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to fetch stock opname", "error", err, "id", id)
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT sc.product_id, p.name, sc.system_stock, sc.counted_qty, sc.counted_by, sc.counted_at
+		FROM stock_opname_counts sc
+		JOIN products p ON p.id = sc.product_id
+		WHERE sc.opname_id = $1
+		ORDER BY sc.product_id
+	`, id)
+	if err != nil {
+		repo.logger.Error("Failed to fetch stock opname counts", "error", err, "id", id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	o.Lines = make([]models.StockOpnameLine, 0)
+	for rows.Next() {
+		var line models.StockOpnameLine
+		err := rows.Scan(&line.ProductID, &line.ProductName, &line.SystemStock, &line.CountedQty, &line.CountedBy, &line.CountedAt)
+		if err != nil {
+			repo.logger.Error("Failed to scan stock opname count", "error", err)
+			return nil, err
+		}
+		line.Difference = line.CountedQty - line.SystemStock
+		o.Lines = append(o.Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate stock opname counts", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched stock opname", "id", id, "lines", len(o.Lines))
+	return &o, nil
+}
+
+// SubmitCounts - header di-lock FOR SHARE: beberapa counter bisa submit barengan,
+// tapi Post (FOR UPDATE) nunggu sampai semua submit yang jalan selesai.
+// Audit log nyimpen hitungan lama yang ketimpa (before) dan hitungan baru (after)
+func (repo *StockOpnameRepository) SubmitCounts(id int, req *models.SubmitStockOpnameCountsRequest, actor models.AuditActor) error {
+	repo.logger.Info("Submitting stock opname counts", "id", id, "count", len(req.Items), "user", req.User)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	var categoryID int
+	err = tx.QueryRow("SELECT status, COALESCE(category_id, 0) FROM stock_opnames WHERE id = $1 FOR SHARE", id).
+		Scan(&status, &categoryID)
+	if err == sql.ErrNoRows {
+		return ErrStockOpnameNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to lock stock opname", "error", err, "id", id)
+		return err
+	}
+	if status != models.OpnameStatusOpen {
+		return ErrStockOpnameNotOpen
+	}
+
+	before := make([]models.StockOpnameLine, 0)
+	after := make([]models.StockOpnameLine, 0, len(req.Items))
+	for _, item := range req.Items {
+		var stock, productCategory int
+		err := tx.QueryRow("SELECT stock, category_id FROM products WHERE id = $1", item.ProductID).Scan(&stock, &productCategory)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: id %d", ErrProductNotFound, item.ProductID)
+		}
+		if err != nil {
+			repo.logger.Error("Failed to fetch product stock", "error", err, "product_id", item.ProductID)
+			return err
+		}
+		if categoryID != 0 && productCategory != categoryID {
+			return fmt.Errorf("%w: id %d", ErrProductNotInOpname, item.ProductID)
+		}
+
+		prev := models.StockOpnameLine{ProductID: item.ProductID}
+		err = tx.QueryRow(
+			"SELECT system_stock, counted_qty, counted_by, counted_at FROM stock_opname_counts WHERE opname_id = $1 AND product_id = $2",
+			id, item.ProductID,
+		).Scan(&prev.SystemStock, &prev.CountedQty, &prev.CountedBy, &prev.CountedAt)
+		if err == nil {
+			prev.Difference = prev.CountedQty - prev.SystemStock
+			before = append(before, prev)
+		} else if err != sql.ErrNoRows {
+			repo.logger.Error("Failed to fetch previous stock opname count", "error", err, "product_id", item.ProductID)
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO stock_opname_counts (opname_id, product_id, system_stock, counted_qty, counted_by)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (opname_id, product_id) DO UPDATE
+			SET system_stock = EXCLUDED.system_stock,
+				counted_qty = EXCLUDED.counted_qty,
+				counted_by = EXCLUDED.counted_by,
+				counted_at = NOW()
+		`, id, item.ProductID, stock, item.CountedQty, req.User)
+		if err != nil {
+			repo.logger.Error("Failed to save stock opname count", "error", err, "product_id", item.ProductID)
+			return err
+		}
+		after = append(after, models.StockOpnameLine{
+			ProductID:   item.ProductID,
+			SystemStock: stock,
+			CountedQty:  item.CountedQty,
+			Difference:  item.CountedQty - stock,
+			CountedBy:   req.User,
+		})
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionUpdate, "stock_opname_counts", id, before, after); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", id)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit stock opname counts", "error", err)
+		return err
+	}
+
+	repo.logger.Info("Stock opname counts submitted", "id", id, "count", len(req.Items))
+	return nil
+}
+
+// Post - selisih semua produk yang dihitung masuk ledger sebagai movement "opname",
+// semua di satu SQL transaction. Kalau satu gagal, sesi tetap open
+func (repo *StockOpnameRepository) Post(id int, user string, actor models.AuditActor) ([]models.LowStockAlert, error) {
+	repo.logger.Info("Posting stock opname", "id", id, "user", user)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := lockOpenStockOpname(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT product_id, counted_qty - system_stock
+		FROM stock_opname_counts
+		WHERE opname_id = $1 AND counted_qty <> system_stock
+		ORDER BY product_id
+	`, id)
+	if err != nil {
+		repo.logger.Error("Failed to fetch stock opname differences", "error", err, "id", id)
+		return nil, err
+	}
+
+	movements := make([]models.StockMovement, 0)
+	for rows.Next() {
+		m := models.StockMovement{
+			Reason:        models.StockReasonOpname,
+			ReferenceType: "stock_opname",
+			ReferenceID:   id,
+			Note:          fmt.Sprintf("stock opname #%d", id),
+			CreatedBy:     user,
+		}
+		if err := rows.Scan(&m.ProductID, &m.Quantity); err != nil {
+			rows.Close()
+			repo.logger.Error("Failed to scan stock opname difference", "error", err)
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate stock opname differences", "error", err)
+		return nil, err
+	}
+
+	alerts := make([]models.LowStockAlert, 0)
+	for i := range movements {
+		lowStock, err := applyStockMovement(tx, &movements[i])
+		if err != nil {
+			repo.logger.Error("Failed to apply opname adjustment", "error", err, "product_id", movements[i].ProductID)
+			return nil, err
+		}
+		if lowStock != nil {
+			alerts = append(alerts, *lowStock)
+		}
+	}
+
+	after, err := closeStockOpname(tx, before, models.OpnameStatusPosted, user)
+	if err != nil {
+		repo.logger.Error("Failed to mark stock opname posted", "error", err, "id", id)
+		return nil, err
+	}
+	if err := insertAudit(tx, actor, models.AuditActionUpdate, "stock_opname", id, before, after); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", id)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit stock opname", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Stock opname posted", "id", id, "adjustments", len(movements))
+	return alerts, nil
+}
+
+func (repo *StockOpnameRepository) Cancel(id int, user string, actor models.AuditActor) error {
+	repo.logger.Info("Cancelling stock opname", "id", id, "user", user)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := lockOpenStockOpname(tx, id)
+	if err != nil {
+		return err
+	}
+
+	after, err := closeStockOpname(tx, before, models.OpnameStatusCancelled, user)
+	if err != nil {
+		repo.logger.Error("Failed to cancel stock opname", "error", err, "id", id)
+		return err
+	}
+	if err := insertAudit(tx, actor, models.AuditActionUpdate, "stock_opname", id, before, after); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", id)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit stock opname cancel", "error", err)
+		return err
+	}
+
+	repo.logger.Info("Stock opname cancelled", "id", id)
+	return nil
+}
+
+// lockOpenStockOpname - header sesi di-lock FOR UPDATE dan dipastiin masih open,
+// sekalian jadi snapshot "before" audit log
+func lockOpenStockOpname(tx *sql.Tx, id int) (*models.StockOpname, error) {
+	var o models.StockOpname
+	err := scanStockOpname(tx.QueryRow("SELECT "+stockOpnameColumns+" FROM stock_opnames WHERE id = $1 FOR UPDATE", id), &o)
+	if err == sql.ErrNoRows {
+		return nil, ErrStockOpnameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != models.OpnameStatusOpen {
+		return nil, ErrStockOpnameNotOpen
+	}
+	return &o, nil
+}
+
+// closeStockOpname - open -> posted / cancelled, return header sesudahnya buat audit log
+func closeStockOpname(tx *sql.Tx, before *models.StockOpname, status, user string) (*models.StockOpname, error) {
+	after := *before
+	err := tx.QueryRow(
+		"UPDATE stock_opnames SET status = $1, posted_by = $2, posted_at = NOW() WHERE id = $3 RETURNING status, posted_by, posted_at",
+		status, user, before.ID,
+	).Scan(&after.Status, &after.PostedBy, &after.PostedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &after, nil
+}
+
+// checkSaleLock - dipanggil pas checkout: produk di kategori yang lagi di-opname
+// dengan lock_sales ga boleh dijual
+func checkSaleLock(tx *sql.Tx, categoryID int) error {
+	var locked bool
+	err := tx.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM stock_opnames WHERE status = 'open' AND lock_sales AND category_id = $1)",
+		categoryID,
+	).Scan(&locked)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return ErrProductLocked
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log/slog"
+)
+
+type StockRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewStockRepository(db *sql.DB, logger *slog.Logger) *StockRepository {
+	return &StockRepository{db: db, logger: logger}
+}
+
+// applyStockMovement - satu-satunya jalan buat ngubah products.stock.
+// Harus dipanggil di dalam SQL transaction milik caller, jadi update cache stok
+// dan insert ledger commit/rollback bareng. Stok ga boleh jadi negatif.
+// Return alert kalau movement ini bikin stok nyebrang ke <= min_stock;
+// caller yang kirim ke notifier setelah commit.
+func applyStockMovement(tx *sql.Tx, m *models.StockMovement) (*models.LowStockAlert, error) {
+	var name string
+	var minStock, reorderQty int
+	err := tx.QueryRow(
+		`UPDATE products SET stock = stock + $1 WHERE id = $2 AND stock + $1 >= 0
+		 RETURNING stock, name, min_stock, reorder_qty`,
+		m.Quantity, m.ProductID,
+	).Scan(&m.StockAfter, &name, &minStock, &reorderQty)
+	if err == sql.ErrNoRows {
+		// bisa karena produknya ga ada atau stoknya ga cukup, cek yang mana
+		var stock int
+		err := tx.QueryRow("SELECT stock FROM products WHERE id = $1", m.ProductID).Scan(&stock)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: id %d", ErrProductNotFound, m.ProductID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: id %d (sisa %d, perubahan %d)", ErrInsufficientStock, m.ProductID, stock, m.Quantity)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var referenceID interface{}
+	if m.ReferenceID != 0 {
+		referenceID = m.ReferenceID
+	}
+	err = tx.QueryRow(
+		`INSERT INTO stock_movements (product_id, reason, quantity, stock_after, reference_type, reference_id, note, created_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at`,
+		m.ProductID, m.Reason, m.Quantity, m.StockAfter, m.ReferenceType, referenceID, m.Note, m.CreatedBy,
+	).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	stockBefore := m.StockAfter - m.Quantity
+	if m.Quantity >= 0 || m.StockAfter > minStock || stockBefore <= minStock {
+		return nil, nil
+	}
+	return &models.LowStockAlert{
+		ProductID:   m.ProductID,
+		ProductName: name,
+		Stock:       m.StockAfter,
+		MinStock:    minStock,
+		ReorderQty:  reorderQty,
+		Reason:      m.Reason,
+		OccurredAt:  m.CreatedAt,
+	}, nil
+}
+
+// Adjust - movement manual (restock / adjustment) dalam transaction sendiri, plus audit log-nya
+func (repo *StockRepository) Adjust(m *models.StockMovement, actor models.AuditActor) (*models.LowStockAlert, error) {
+	repo.logger.Info("Recording stock movement", "product_id", m.ProductID, "reason", m.Reason, "quantity", m.Quantity)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	lowStock, err := applyStockMovement(tx, m)
+	if err != nil {
+		repo.logger.Error("Failed to apply stock movement", "error", err, "product_id", m.ProductID)
+		return nil, err
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "stock_movement", m.ID, nil, m); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", m.ID)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit stock movement", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Stock movement recorded", "id", m.ID, "product_id", m.ProductID, "stock_after", m.StockAfter)
+	return lowStock, nil
+}
+
+// GetHistory - ledger satu produk, terbaru duluan
+func (repo *StockRepository) GetHistory(productID, limit, offset int) (*models.StockHistory, error) {
+	repo.logger.Info("Fetching stock history", "product_id", productID)
+
+	history := models.StockHistory{ProductID: productID}
+	err := repo.db.QueryRow("SELECT stock FROM products WHERE id = $1", productID).Scan(&history.Stock)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Product not found", "id", productID)
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to fetch product stock", "error", err, "product_id", productID)
+		return nil, err
+	}
+
+	err = repo.db.QueryRow("SELECT COUNT(*) FROM stock_movements WHERE product_id = $1", productID).Scan(&history.Total)
+	if err != nil {
+		repo.logger.Error("Failed to count stock movements", "error", err, "product_id", productID)
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT id, product_id, reason, quantity, stock_after, reference_type, COALESCE(reference_id, 0),
+			note, created_by, created_at
+		FROM stock_movements
+		WHERE product_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, productID, limit, offset)
+	if err != nil {
+		repo.logger.Error("Failed to fetch stock movements", "error", err, "product_id", productID)
+		return nil, err
+	}
+	defer rows.Close()
+
+	history.Movements = make([]models.StockMovement, 0)
+	for rows.Next() {
+		var m models.StockMovement
+		err := rows.Scan(&m.ID, &m.ProductID, &m.Reason, &m.Quantity, &m.StockAfter, &m.ReferenceType,
+			&m.ReferenceID, &m.Note, &m.CreatedBy, &m.CreatedAt)
+		if err != nil {
+			repo.logger.Error("Failed to scan stock movement", "error", err)
+			return nil, err
+		}
+		history.Movements = append(history.Movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate stock movements", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched stock history", "product_id", productID, "count", len(history.Movements))
+	return &history, nil
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import "kasir-api/models"
+
+// ProductStore - kontrak penyimpanan produk yang dipake ProductService.
+// Implementasinya ProductRepository (Postgres) dan MemoryProductStore (buat test)
+type ProductStore interface {
+	Create(product *models.Product, actor models.AuditActor) error
+	GetAll(filter models.ProductFilter) (*models.ProductList, error)
+	GetByID(id int) (*models.Product, error)
+	Update(update *models.ProductUpdate, actor models.AuditActor) error
+	Delete(id int, actor models.AuditActor) error
+	GetLowStock() ([]models.Product, error)
+}
+
+// CategoryStore - kontrak penyimpanan kategori yang dipake CategoryService
+type CategoryStore interface {
+	Create(category *models.Category, actor models.AuditActor) error
+	GetAll(filter models.CategoryFilter) (*models.CategoryList, error)
+	GetByID(id int) (*models.Category, error)
+	Update(category *models.Category, actor models.AuditActor) error
+	Delete(id int, actor models.AuditActor) error
+}
+
+var (
+	_ ProductStore  = (*ProductRepository)(nil)
+	_ ProductStore  = (*MemoryProductStore)(nil)
+	_ CategoryStore = (*CategoryRepository)(nil)
+	_ CategoryStore = (*MemoryCategoryStore)(nil)
+)
@@ -0,0 +1,543 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"kasir-api/database"
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+)
+
+// Suite ini jalan di dua backend: memory (selalu) dan Postgres kalau TEST_DB_CONN diisi.
+// Database test-nya dikosongin tiap case (TRUNCATE), jangan arahin ke database beneran
+
+type backend struct {
+	name string
+	new  func(t *testing.T) (ProductStore, CategoryStore)
+}
+
+var testActor = models.AuditActor{Username: "test"}
+
+func backends(t *testing.T) []backend {
+	list := []backend{{
+		name: "memory",
+		new: func(t *testing.T) (ProductStore, CategoryStore) {
+			products, categories := NewMemoryStores()
+			return products, categories
+		},
+	}}
+
+	conn := os.Getenv("TEST_DB_CONN")
+	if conn == "" {
+		t.Log("TEST_DB_CONN kosong, backend postgres di-skip")
+		return list
+	}
+	db, err := database.InitDB(conn)
+	if err != nil {
+		t.Fatalf("connect test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if _, err := database.MigrateUp(db, logger); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+
+	return append(list, backend{
+		name: "postgres",
+		new: func(t *testing.T) (ProductStore, CategoryStore) {
+			resetPostgres(t, db)
+			return NewProductRepository(db, logger), NewCategoryRepository(db, logger)
+		},
+	})
+}
+
+func resetPostgres(t *testing.T, db *sql.DB) {
+	t.Helper()
+	if _, err := db.Exec(seedResetQuery); err != nil {
+		t.Fatalf("reset test database: %v", err)
+	}
+}
+
+func TestStoreParity(t *testing.T) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, products ProductStore, categories CategoryStore)
+	}{
+		{"category ids auto increment", testCategoryAutoIncrement},
+		{"category not found", testCategoryNotFound},
+		{"category update", testCategoryUpdate},
+		{"category delete in use", testCategoryDeleteInUse},
+		{"product requires category", testProductRequiresCategory},
+		{"product create and get", testProductCreateAndGet},
+		{"product not found", testProductNotFound},
+		{"product update", testProductUpdate},
+		{"product stock cannot go negative", testProductNegativeStock},
+		{"product delete", testProductDelete},
+		{"low stock order", testLowStockOrder},
+		{"concurrent create", testConcurrentCreate},
+		{"product list filters", testProductListFilters},
+		{"product list keyset paging", testProductListPaging},
+		{"category list keyset paging", testCategoryListPaging},
+		{"list rejects bad sort and cursor", testListInvalidParams},
+	}
+
+	for _, b := range backends(t) {
+		for _, tc := range cases {
+			t.Run(b.name+"/"+tc.name, func(t *testing.T) {
+				products, categories := b.new(t)
+				tc.run(t, products, categories)
+			})
+		}
+	}
+}
+
+func mustCategory(t *testing.T, categories CategoryStore, name string) models.Category {
+	t.Helper()
+	c := models.Category{Name: name, Description: "kategori " + name}
+	if err := categories.Create(&c, testActor); err != nil {
+		t.Fatalf("create category %s: %v", name, err)
+	}
+	return c
+}
+
+func mustProduct(t *testing.T, products ProductStore, p models.Product) models.Product {
+	t.Helper()
+	if err := products.Create(&p, testActor); err != nil {
+		t.Fatalf("create product %s: %v", p.Name, err)
+	}
+	return p
+}
+
+// allProducts / allCategories - buat case yang ga ngetes paging, ambil semuanya tanpa limit
+func allProducts(products ProductStore) ([]models.Product, error) {
+	list, err := products.GetAll(models.ProductFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+func allCategories(categories CategoryStore) ([]models.Category, error) {
+	list, err := categories.GetAll(models.CategoryFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+func productNames(products []models.Product) []string {
+	names := make([]string, len(products))
+	for i, p := range products {
+		names[i] = p.Name
+	}
+	return names
+}
+
+func testCategoryAutoIncrement(t *testing.T, _ ProductStore, categories CategoryStore) {
+	a := mustCategory(t, categories, "Makanan")
+	b := mustCategory(t, categories, "Minuman")
+	if a.ID != 1 || b.ID != 2 {
+		t.Fatalf("ids = %d, %d, want 1, 2", a.ID, b.ID)
+	}
+
+	all, err := allCategories(categories)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0] != a || all[1] != b {
+		t.Fatalf("GetAll = %+v, want [%+v %+v]", all, a, b)
+	}
+}
+
+func testCategoryNotFound(t *testing.T, _ ProductStore, categories CategoryStore) {
+	if _, err := categories.GetByID(99); !errors.Is(err, ErrCategoryNotFound) {
+		t.Fatalf("GetByID err = %v, want ErrCategoryNotFound", err)
+	}
+	missing := models.Category{ID: 99, Name: "x"}
+	if err := categories.Update(&missing, testActor); !errors.Is(err, ErrCategoryNotFound) {
+		t.Fatalf("Update err = %v, want ErrCategoryNotFound", err)
+	}
+	if err := categories.Delete(99, testActor); !errors.Is(err, ErrCategoryNotFound) {
+		t.Fatalf("Delete err = %v, want ErrCategoryNotFound", err)
+	}
+}
+
+func testCategoryUpdate(t *testing.T, _ ProductStore, categories CategoryStore) {
+	c := mustCategory(t, categories, "Sembako")
+	c.Description = "kebutuhan pokok"
+	c.TaxExempt = true
+	if err := categories.Update(&c, testActor); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := categories.GetByID(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != c {
+		t.Fatalf("GetByID = %+v, want %+v", *got, c)
+	}
+}
+
+func testCategoryDeleteInUse(t *testing.T, products ProductStore, categories CategoryStore) {
+	used := mustCategory(t, categories, "Snack")
+	unused := mustCategory(t, categories, "Elektronik")
+	mustProduct(t, products, models.Product{Name: "Chitato", Price: 8000, Stock: 5, CategoryID: used.ID})
+
+	if err := categories.Delete(used.ID, testActor); !errors.Is(err, ErrCategoryInUse) || !errors.Is(err, apperror.ErrForeignKey) {
+		t.Fatalf("Delete used err = %v, want ErrCategoryInUse (foreign key)", err)
+	}
+	if err := categories.Delete(unused.ID, testActor); err != nil {
+		t.Fatalf("Delete unused: %v", err)
+	}
+	if _, err := categories.GetByID(unused.ID); !errors.Is(err, ErrCategoryNotFound) {
+		t.Fatalf("GetByID after delete err = %v, want ErrCategoryNotFound", err)
+	}
+	if _, err := categories.GetByID(used.ID); err != nil {
+		t.Fatalf("category in use should still exist: %v", err)
+	}
+}
+
+func testProductRequiresCategory(t *testing.T, products ProductStore, _ CategoryStore) {
+	for _, categoryID := range []int{0, 42} {
+		p := models.Product{Name: "Yatim", Price: 1000, CategoryID: categoryID}
+		err := products.Create(&p, testActor)
+		if !errors.Is(err, ErrCategoryNotFound) || !errors.Is(err, apperror.ErrValidation) {
+			t.Fatalf("Create category_id %d err = %v, want ErrCategoryNotFound (validation)", categoryID, err)
+		}
+	}
+	all, err := allProducts(products)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 0 {
+		t.Fatalf("GetAll = %+v, want empty", all)
+	}
+}
+
+func testProductCreateAndGet(t *testing.T, products ProductStore, categories CategoryStore) {
+	c := mustCategory(t, categories, "Minuman")
+	a := mustProduct(t, products, models.Product{Name: "Aqua 600ml", Price: 3000, CostPrice: 2000, Stock: 100, MinStock: 10, ReorderQty: 50, CategoryID: c.ID})
+	b := mustProduct(t, products, models.Product{Name: "Teh Pucuk", Price: 4000, Stock: 45, CategoryID: c.ID, TaxExempt: true})
+	if a.ID != 1 || b.ID != 2 {
+		t.Fatalf("ids = %d, %d, want 1, 2", a.ID, b.ID)
+	}
+
+	a.CategoryName, b.CategoryName = c.Name, c.Name
+	got, err := products.GetByID(a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != a {
+		t.Fatalf("GetByID = %+v, want %+v", *got, a)
+	}
+
+	all, err := allProducts(products)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0] != a || all[1] != b {
+		t.Fatalf("GetAll = %+v, want [%+v %+v]", all, a, b)
+	}
+}
+
+func testProductNotFound(t *testing.T, products ProductStore, _ CategoryStore) {
+	if _, err := products.GetByID(7); !errors.Is(err, ErrProductNotFound) {
+		t.Fatalf("GetByID err = %v, want ErrProductNotFound", err)
+	}
+	missing := models.ProductUpdate{Product: models.Product{ID: 7, Name: "x"}}
+	if err := products.Update(&missing, testActor); !errors.Is(err, ErrProductNotFound) {
+		t.Fatalf("Update err = %v, want ErrProductNotFound", err)
+	}
+	if err := products.Delete(7, testActor); !errors.Is(err, ErrProductNotFound) {
+		t.Fatalf("Delete err = %v, want ErrProductNotFound", err)
+	}
+}
+
+func testProductUpdate(t *testing.T, products ProductStore, categories CategoryStore) {
+	c := mustCategory(t, categories, "Bumbu Dapur")
+	other := mustCategory(t, categories, "Sembako")
+	p := mustProduct(t, products, models.Product{Name: "Kecap ABC", Price: 9000, CostPrice: 7000, Stock: 50, CategoryID: c.ID})
+
+	// cost_price ga dikirim = ga diubah, category_id belum ikut di-update, stok di body diabaikan
+	update := models.ProductUpdate{Product: models.Product{ID: p.ID, Name: "Kecap ABC 600ml", Price: 9500, Stock: 60, MinStock: 5, ReorderQty: 20, CategoryID: other.ID, TaxExempt: true}}
+	if err := products.Update(&update, testActor); err != nil {
+		t.Fatal(err)
+	}
+	if update.Product.CostPrice != 7000 || update.Stock != 50 {
+		t.Fatalf("Update cost_price = %d, stock = %d, want 7000 and 50 (unchanged)", update.Product.CostPrice, update.Stock)
+	}
+
+	got, err := products.GetByID(p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.Product{ID: p.ID, Name: "Kecap ABC 600ml", Price: 9500, CostPrice: 7000, Stock: 50, MinStock: 5, ReorderQty: 20, CategoryID: c.ID, CategoryName: c.Name, TaxExempt: true}
+	if *got != want {
+		t.Fatalf("GetByID = %+v, want %+v", *got, want)
+	}
+
+	// cost_price dikirim 0 = beneran di-set 0
+	zero := 0
+	update = models.ProductUpdate{Product: want, CostPrice: &zero}
+	if err := products.Update(&update, testActor); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = products.GetByID(p.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got.CostPrice != 0 || update.Product.CostPrice != 0 {
+		t.Fatalf("cost_price after reset = %d, want 0", got.CostPrice)
+	}
+}
+
+func testProductNegativeStock(t *testing.T, products ProductStore, categories CategoryStore) {
+	c := mustCategory(t, categories, "Snack")
+	p := models.Product{Name: "Qtela", Price: 7000, Stock: -1, CategoryID: c.ID}
+	if err := products.Create(&p, testActor); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("Create err = %v, want ErrInsufficientStock", err)
+	}
+
+}
+
+func testProductDelete(t *testing.T, products ProductStore, categories CategoryStore) {
+	c := mustCategory(t, categories, "Makanan")
+	p := mustProduct(t, products, models.Product{Name: "Roti Sobek", Price: 12000, Stock: 25, CategoryID: c.ID})
+
+	if err := products.Delete(p.ID, testActor); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := products.GetByID(p.ID); !errors.Is(err, ErrProductNotFound) {
+		t.Fatalf("GetByID after delete err = %v, want ErrProductNotFound", err)
+	}
+	if err := products.Delete(p.ID, testActor); !errors.Is(err, ErrProductNotFound) {
+		t.Fatalf("second Delete err = %v, want ErrProductNotFound", err)
+	}
+	// kategorinya jadi bebas dihapus
+	if err := categories.Delete(c.ID, testActor); err != nil {
+		t.Fatalf("Delete category: %v", err)
+	}
+}
+
+func testLowStockOrder(t *testing.T, products ProductStore, categories CategoryStore) {
+	snack := mustCategory(t, categories, "Snack")
+	drink := mustCategory(t, categories, "Minuman")
+	mustProduct(t, products, models.Product{Name: "Chitato", Price: 8000, Stock: 2, MinStock: 5, CategoryID: snack.ID})
+	mustProduct(t, products, models.Product{Name: "Aqua", Price: 3000, Stock: 50, MinStock: 5, CategoryID: drink.ID})
+	mustProduct(t, products, models.Product{Name: "Vit", Price: 2500, Stock: 5, MinStock: 5, CategoryID: drink.ID})
+	mustProduct(t, products, models.Product{Name: "Frestea", Price: 4000, Stock: 1, MinStock: 5, CategoryID: drink.ID})
+	mustProduct(t, products, models.Product{Name: "Delfi", Price: 10000, Stock: 2, MinStock: 5, CategoryID: snack.ID})
+	mustUncategorizedProduct(t, products, models.Product{Name: "Korek Api", Price: 2000, Stock: 0, MinStock: 5})
+
+	low, err := products.GetLowStock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(low))
+	for _, p := range low {
+		names = append(names, p.CategoryName+"/"+p.Name)
+	}
+	want := []string{"Minuman/Frestea", "Minuman/Vit", "Snack/Chitato", "Snack/Delfi", "/Korek Api"}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Fatalf("GetLowStock = %v, want %v", names, want)
+	}
+}
+
+// mustUncategorizedProduct - produk lama dari sebelum kategori wajib (category_id NULL).
+// Create nolak produk tanpa kategori, jadi dimasukin langsung ke backend-nya
+func mustUncategorizedProduct(t *testing.T, products ProductStore, p models.Product) {
+	t.Helper()
+	switch store := products.(type) {
+	case *MemoryProductStore:
+		c := store.catalog
+		c.mu.Lock()
+		p.ID = c.nextProductID
+		c.nextProductID++
+		c.products[p.ID] = p
+		c.mu.Unlock()
+	case *ProductRepository:
+		_, err := store.db.Exec("INSERT INTO products (name, price, stock, min_stock) VALUES ($1, $2, $3, $4)",
+			p.Name, p.Price, p.Stock, p.MinStock)
+		if err != nil {
+			t.Fatalf("insert uncategorized product %s: %v", p.Name, err)
+		}
+	default:
+		t.Fatalf("unknown product store %T", products)
+	}
+}
+
+func testConcurrentCreate(t *testing.T, products ProductStore, categories CategoryStore) {
+	c := mustCategory(t, categories, "Sembako")
+
+	const n = 20
+	ids := make(chan int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p := models.Product{Name: fmt.Sprintf("Beras %d", i), Price: 65000, Stock: i, CategoryID: c.ID}
+			if err := products.Create(&p, testActor); err != nil {
+				t.Errorf("create %d: %v", i, err)
+				return
+			}
+			ids <- p.ID
+		}(i)
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int]bool, n)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("duplicate id %d", id)
+		}
+		seen[id] = true
+	}
+	all, err := allProducts(products)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != n || len(all) != n {
+		t.Fatalf("created %d ids, GetAll %d rows, want %d", len(seen), len(all), n)
+	}
+}
+
+func testProductListFilters(t *testing.T, products ProductStore, categories CategoryStore) {
+	drink := mustCategory(t, categories, "Minuman")
+	snack := mustCategory(t, categories, "Snack")
+	mustProduct(t, products, models.Product{Name: "Aqua", Price: 3000, Stock: 10, CategoryID: drink.ID})
+	mustProduct(t, products, models.Product{Name: "Teh Pucuk", Price: 4000, Stock: 0, CategoryID: drink.ID})
+	mustProduct(t, products, models.Product{Name: "Chitato", Price: 8000, Stock: 5, CategoryID: snack.ID})
+	mustProduct(t, products, models.Product{Name: "Delfi", Price: 12000, Stock: 0, CategoryID: snack.ID})
+
+	yes, no := true, false
+	minPrice, maxPrice := 4000, 8000
+	tests := []struct {
+		name   string
+		filter models.ProductFilter
+		want   []string
+	}{
+		{"category", models.ProductFilter{CategoryID: snack.ID}, []string{"Chitato", "Delfi"}},
+		{"price range", models.ProductFilter{MinPrice: &minPrice, MaxPrice: &maxPrice}, []string{"Teh Pucuk", "Chitato"}},
+		{"in stock", models.ProductFilter{InStock: &yes}, []string{"Aqua", "Chitato"}},
+		{"out of stock", models.ProductFilter{InStock: &no, CategoryID: drink.ID}, []string{"Teh Pucuk"}},
+	}
+	for _, tt := range tests {
+		tt.filter.IncludeTotal = true
+		list, err := products.GetAll(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if fmt.Sprint(productNames(list.Data)) != fmt.Sprint(tt.want) {
+			t.Fatalf("%s: names = %v, want %v", tt.name, productNames(list.Data), tt.want)
+		}
+		if list.Total == nil || *list.Total != len(tt.want) || list.NextCursor != "" {
+			t.Fatalf("%s: total = %v, next = %q, want %d and no next page", tt.name, list.Total, list.NextCursor, len(tt.want))
+		}
+	}
+}
+
+func testProductListPaging(t *testing.T, products ProductStore, categories CategoryStore) {
+	c := mustCategory(t, categories, "Sembako")
+	for _, p := range []models.Product{
+		{Name: "Gula", Price: 15000},
+		{Name: "Beras", Price: 65000},
+		{Name: "Minyak", Price: 15000},
+		{Name: "Telur", Price: 28000},
+		{Name: "Garam", Price: 15000},
+	} {
+		p.CategoryID = c.ID
+		mustProduct(t, products, p)
+	}
+
+	// harga turun, harga sama diurutin nama naik
+	want := []string{"Beras", "Telur", "Garam", "Gula", "Minyak"}
+	filter := models.ProductFilter{ListParams: models.ListParams{Sort: "-price,name", Limit: 2, IncludeTotal: true}}
+	var got []string
+	for page := 0; ; page++ {
+		if page > len(want) {
+			t.Fatalf("paging ga berhenti, got %v", got)
+		}
+		list, err := products.GetAll(filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Data) > 2 || list.Total == nil || *list.Total != len(want) {
+			t.Fatalf("page %d: %d rows, total %v", page, len(list.Data), list.Total)
+		}
+		got = append(got, productNames(list.Data)...)
+		if list.NextCursor == "" {
+			break
+		}
+		filter.Cursor = list.NextCursor
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("paged names = %v, want %v", got, want)
+	}
+}
+
+func testCategoryListPaging(t *testing.T, _ ProductStore, categories CategoryStore) {
+	for _, name := range []string{"Minuman", "Sembako", "Bumbu", "Snack"} {
+		c := models.Category{Name: name, TaxExempt: name == "Sembako" || name == "Bumbu"}
+		if err := categories.Create(&c, testActor); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exempt := true
+	filter := models.CategoryFilter{ListParams: models.ListParams{Sort: "-name", Limit: 1}, TaxExempt: &exempt}
+	var got []string
+	for {
+		list, err := categories.GetAll(filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range list.Data {
+			got = append(got, c.Name)
+		}
+		if list.NextCursor == "" || len(got) > 4 {
+			break
+		}
+		filter.Cursor = list.NextCursor
+	}
+	if fmt.Sprint(got) != fmt.Sprint([]string{"Sembako", "Bumbu"}) {
+		t.Fatalf("paged names = %v, want [Sembako Bumbu]", got)
+	}
+}
+
+func testListInvalidParams(t *testing.T, products ProductStore, categories CategoryStore) {
+	c := mustCategory(t, categories, "Snack")
+	mustProduct(t, products, models.Product{Name: "Chitato", Price: 8000, CategoryID: c.ID})
+	mustProduct(t, products, models.Product{Name: "Delfi", Price: 12000, CategoryID: c.ID})
+
+	first, err := products.GetAll(models.ProductFilter{ListParams: models.ListParams{Sort: "price", Limit: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.NextCursor == "" {
+		t.Fatal("want next cursor after first page")
+	}
+
+	for _, params := range []models.ListParams{
+		{Sort: "cost_price"},
+		{Sort: "price; DROP TABLE products"},
+		{Sort: "price,-price"},
+		{Sort: "price", Cursor: "bukan-cursor"},
+		{Sort: "-price", Cursor: first.NextCursor},
+	} {
+		_, err := products.GetAll(models.ProductFilter{ListParams: params})
+		if !errors.Is(err, apperror.ErrValidation) {
+			t.Fatalf("GetAll(%+v) err = %v, want validation error", params, err)
+		}
+	}
+	if _, err := categories.GetAll(models.CategoryFilter{ListParams: models.ListParams{Sort: "price"}}); !errors.Is(err, ErrInvalidSortField) {
+		t.Fatalf("category sort price err = %v, want ErrInvalidSortField", err)
+	}
+}
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"log/slog"
+)
+
+var ErrSupplierNotFound = apperror.New(apperror.ErrNotFound, "supplier tidak ditemukan")
+
+type SupplierRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewSupplierRepository(db *sql.DB, logger *slog.Logger) *SupplierRepository {
+	return &SupplierRepository{db: db, logger: logger}
+}
+
+func (repo *SupplierRepository) Create(supplier *models.Supplier, actor models.AuditActor) error {
+	repo.logger.Info("Creating supplier", "name", supplier.Name)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO suppliers (name, phone, address) VALUES ($1, $2, $3) RETURNING id, created_at"
+	err = tx.QueryRow(query, supplier.Name, supplier.Phone, supplier.Address).Scan(&supplier.ID, &supplier.CreatedAt)
+	if err != nil {
+		repo.logger.Error("Failed to create supplier", "error", err, "name", supplier.Name)
+		return apperror.FromDB(err)
+	}
+
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "supplier", supplier.ID, nil, supplier); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", supplier.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit supplier", "error", err)
+		return err
+	}
+	repo.logger.Info("Supplier created successfully", "id", supplier.ID, "name", supplier.Name)
+	return nil
+}
+
+func (repo *SupplierRepository) GetAll() ([]models.Supplier, error) {
+	repo.logger.Info("Fetching all suppliers")
+	rows, err := repo.db.Query("SELECT id, name, phone, address, created_at FROM suppliers ORDER BY name")
+	if err != nil {
+		repo.logger.Error("Failed to fetch suppliers", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppliers := make([]models.Supplier, 0)
+	for rows.Next() {
+		var s models.Supplier
+		if err := rows.Scan(&s.ID, &s.Name, &s.Phone, &s.Address, &s.CreatedAt); err != nil {
+			repo.logger.Error("Failed to scan supplier", "error", err)
+			return nil, err
+		}
+		suppliers = append(suppliers, s)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate suppliers", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched suppliers", "count", len(suppliers))
+	return suppliers, nil
+}
+
+func (repo *SupplierRepository) GetByID(id int) (*models.Supplier, error) {
+	repo.logger.Info("Fetching supplier by ID", "id", id)
+	var s models.Supplier
+	err := repo.db.QueryRow("SELECT id, name, phone, address, created_at FROM suppliers WHERE id = $1", id).
+		Scan(&s.ID, &s.Name, &s.Phone, &s.Address, &s.CreatedAt)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Supplier not found", "id", id)
+		return nil, ErrSupplierNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to fetch supplier by ID", "error", err, "id", id)
+		return nil, err
+	}
+	repo.logger.Info("Successfully fetched supplier", "id", id, "name", s.Name)
+	return &s, nil
+}
+
+func (repo *SupplierRepository) Update(supplier *models.Supplier, actor models.AuditActor) error {
+	repo.logger.Info("Updating supplier", "id", supplier.ID, "name", supplier.Name)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var before models.Supplier
+	err = tx.QueryRow("SELECT id, name, phone, address, created_at FROM suppliers WHERE id = $1 FOR UPDATE", supplier.ID).
+		Scan(&before.ID, &before.Name, &before.Phone, &before.Address, &before.CreatedAt)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Supplier not found for update", "id", supplier.ID)
+		return ErrSupplierNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to lock supplier", "error", err, "id", supplier.ID)
+		return err
+	}
+
+	_, err = tx.Exec(
+		"UPDATE suppliers SET name = $1, phone = $2, address = $3 WHERE id = $4",
+		supplier.Name, supplier.Phone, supplier.Address, supplier.ID,
+	)
+	if err != nil {
+		repo.logger.Error("Failed to update supplier", "error", err, "id", supplier.ID)
+		return apperror.FromDB(err)
+	}
+	supplier.CreatedAt = before.CreatedAt
+
+	if err := insertAudit(tx, actor, models.AuditActionUpdate, "supplier", supplier.ID, before, supplier); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", supplier.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit supplier update", "error", err)
+		return err
+	}
+	repo.logger.Info("Supplier updated successfully", "id", supplier.ID)
+	return nil
+}
@@ -0,0 +1,902 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kasir-api/internal/apperror"
+	"kasir-api/internal/loyalty"
+	"kasir-api/internal/promotion"
+	"kasir-api/internal/tax"
+	"kasir-api/models"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+var (
+	ErrProductNotFound   = apperror.New(apperror.ErrNotFound, "produk tidak ditemukan")
+	ErrInsufficientStock = apperror.New(apperror.ErrConflict, "stok produk tidak mencukupi")
+
+	ErrPaymentInsufficient = apperror.New(apperror.ErrValidation, "total pembayaran kurang dari total belanja")
+	ErrNonCashOverpayment  = apperror.New(apperror.ErrValidation, "pembayaran non-tunai melebihi total belanja, kembalian cuma bisa dari cash")
+	ErrInvalidPointsAmount = apperror.New(apperror.ErrValidation, "nominal pembayaran poin harus kelipatan nilai poin")
+
+	ErrTransactionNotFound = apperror.New(apperror.ErrNotFound, "transaksi tidak ditemukan")
+	ErrRefundItemNotInSale = apperror.New(apperror.ErrValidation, "produk tidak ada di transaksi ini")
+	ErrRefundExceedsSold   = apperror.New(apperror.ErrConflict, "jumlah refund melebihi jumlah yang terjual")
+	ErrNothingToRefund     = apperror.New(apperror.ErrConflict, "tidak ada item yang bisa di-refund")
+)
+
+type TransactionRepository struct {
+	db       *sql.DB
+	tax      tax.Config
+	loyalty  loyalty.Config
+	location *time.Location
+	logger   *slog.Logger
+}
+
+// NewTransactionRepository - location itu timezone bisnis, dipake ngecek jadwal promo pas checkout
+func NewTransactionRepository(db *sql.DB, taxConfig tax.Config, loyaltyConfig loyalty.Config, location *time.Location, logger *slog.Logger) *TransactionRepository {
+	return &TransactionRepository{db: db, tax: taxConfig, loyalty: loyaltyConfig, location: location, logger: logger}
+}
+
+// taxExemptColumn - produk bebas PPN kalau flag produknya atau flag kategorinya nyala.
+// Query yang pake ini wajib alias products jadi p
+const taxExemptColumn = "(p.tax_exempt OR COALESCE((SELECT c.tax_exempt FROM categories c WHERE c.id = p.category_id), FALSE))"
+
+// CreateTransaction - semua jalan di satu SQL transaction:
+// lock shift kasir (FOR SHARE), lock row products (FOR UPDATE), cek stok, hitung promo & PPN,
+// simpan header + item, kurangin stok lewat ledger.
+// Row di-lock urut berdasarkan product_id biar dua checkout barengan ga deadlock.
+func (repo *TransactionRepository) CreateTransaction(req *models.CheckoutRequest) (*models.Transaction, error) {
+	repo.logger.Info("Creating transaction", "item_count", len(req.Items), "cashier", req.Cashier)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	// kalau udah commit, rollback ini no-op
+	defer tx.Rollback()
+
+	// penjualan wajib masuk ke shift yang lagi buka biar laci kas bisa direkonsiliasi
+	shiftID, err := lockOpenShift(tx, req.UserID)
+	if err != nil {
+		repo.logger.Warn("Checkout without open shift", "user_id", req.UserID, "error", err)
+		return nil, err
+	}
+
+	transaction, err := repo.insertSale(tx, req, shiftID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Transaction created successfully", "id", transaction.ID, "total_amount", transaction.TotalAmount)
+	return transaction, nil
+}
+
+// insertSale - isi checkout di dalam tx yang udah dibuka caller: lock produk, cek stok,
+// hitung promo & PPN per baris, simpan header + pembayaran + item, kurangin stok lewat ledger.
+// Kalau ada CustomerPhone, poin yang dipake bayar dipotong dan poin belanja ditambahin
+// ke member itu (dihitung dari total di luar bagian yang dibayar pake poin).
+// snapshot != nil berarti harga & diskon pakai snapshot (product_id -> item), bukan harga produk
+// dan promo yang berlaku sekarang; dipake sale QRIS yang nominalnya udah dikunci pas QR dibuat
+func (repo *TransactionRepository) insertSale(tx *sql.Tx, req *models.CheckoutRequest, shiftID int, snapshot map[int]models.PendingSaleItem) (*models.Transaction, error) {
+	items := req.Items
+	sorted := make([]models.CheckoutItem, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ProductID < sorted[j].ProductID })
+
+	details := make([]models.TransactionItem, 0, len(sorted))
+	for _, item := range sorted {
+		var name string
+		var price, costPrice, stock, categoryID int
+		var exempt bool
+		err := tx.QueryRow(
+			"SELECT p.name, p.price, p.cost_price, p.stock, p.category_id, "+taxExemptColumn+" FROM products p WHERE p.id = $1 FOR UPDATE",
+			item.ProductID,
+		).Scan(&name, &price, &costPrice, &stock, &categoryID, &exempt)
+		if err == sql.ErrNoRows {
+			repo.logger.Warn("Product not found for checkout", "product_id", item.ProductID)
+			return nil, fmt.Errorf("%w: id %d", ErrProductNotFound, item.ProductID)
+		}
+		if err != nil {
+			repo.logger.Error("Failed to lock product", "error", err, "product_id", item.ProductID)
+			return nil, err
+		}
+
+		if err := checkSaleLock(tx, categoryID); err != nil {
+			repo.logger.Warn("Product locked by stock opname", "product_id", item.ProductID, "error", err)
+			return nil, fmt.Errorf("%w: %s", err, name)
+		}
+
+		if stock < item.Quantity {
+			repo.logger.Warn("Insufficient stock", "product_id", item.ProductID, "stock", stock, "requested", item.Quantity)
+			return nil, fmt.Errorf("%w: %s (sisa %d, diminta %d)", ErrInsufficientStock, name, stock, item.Quantity)
+		}
+
+		detail := models.TransactionItem{
+			ProductID:   item.ProductID,
+			ProductName: name,
+			CategoryID:  categoryID,
+			Price:       price,
+			CostPrice:   costPrice,
+			Quantity:    item.Quantity,
+			TaxExempt:   exempt,
+		}
+		if snap, ok := snapshot[item.ProductID]; ok {
+			detail.Price = snap.Price
+			detail.Discount = snap.Discount
+			detail.CartDiscount = snap.CartDiscount
+		}
+		detail.Subtotal = detail.Price * detail.Quantity
+		details = append(details, detail)
+	}
+
+	if snapshot == nil {
+		if err := repo.applyPromotions(tx, items, details); err != nil {
+			repo.logger.Error("Failed to apply promotions", "error", err)
+			return nil, err
+		}
+	}
+
+	// PPN dihitung dari harga setelah diskon, per baris persis kayak tax.ApplyCart
+	totalAmount, taxAmount := 0, 0
+	for i := range details {
+		d := &details[i]
+		net, lineTax, gross := repo.tax.Split(d.Subtotal-d.Discount-d.CartDiscount, d.TaxExempt)
+		d.NetAmount, d.TaxAmount = net, lineTax
+		totalAmount += gross
+		taxAmount += lineTax
+	}
+
+	payments, change, err := settlePayments(totalAmount, req)
+	if err != nil {
+		repo.logger.Warn("Payment does not settle the sale", "total_amount", totalAmount, "error", err)
+		return nil, err
+	}
+
+	var customer *models.Customer
+	if req.CustomerPhone != "" {
+		customer, err = lockCustomerByPhone(tx, req.CustomerPhone)
+		if err != nil {
+			repo.logger.Error("Failed to lock customer", "error", err, "phone", req.CustomerPhone)
+			return nil, err
+		}
+	}
+	redeemed, pointsUsed, pointsEarned := 0, 0, 0
+	for _, p := range payments {
+		if p.Method == models.PaymentPoints {
+			redeemed += p.Amount
+		}
+	}
+	if redeemed > 0 {
+		var ok bool
+		if pointsUsed, ok = repo.loyalty.Points(redeemed); !ok {
+			return nil, fmt.Errorf("%w: Rp%d per poin", ErrInvalidPointsAmount, repo.loyalty.PointValue)
+		}
+	}
+	if customer != nil {
+		pointsEarned = repo.loyalty.Earn(totalAmount - redeemed)
+	}
+
+	transaction := models.Transaction{
+		Cashier:       req.Cashier,
+		ShiftID:       shiftID,
+		PaymentMethod: req.PaymentMethod,
+		TotalAmount:   totalAmount,
+		TaxAmount:     taxAmount,
+		TaxRate:       repo.tax.RatePercent(),
+		TaxMode:       repo.tax.Mode,
+		PaidAmount:    totalAmount + change,
+		ChangeAmount:  change,
+		PointsEarned:  pointsEarned,
+		PointsUsed:    pointsUsed,
+	}
+	var customerID interface{}
+	if customer != nil {
+		transaction.CustomerID = customer.ID
+		customerID = customer.ID
+	}
+	err = tx.QueryRow(
+		`INSERT INTO transactions (cashier, shift_id, customer_id, payment_method, total_amount, tax_amount, tax_rate_bp, tax_mode,
+			paid_amount, change_amount, points_earned, points_used)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at`,
+		req.Cashier, shiftID, customerID, req.PaymentMethod, totalAmount, taxAmount, repo.tax.RateBP, repo.tax.Mode,
+		transaction.PaidAmount, change, pointsEarned, pointsUsed,
+	).Scan(&transaction.ID, &transaction.CreatedAt)
+	if err != nil {
+		repo.logger.Error("Failed to insert transaction", "error", err)
+		return nil, apperror.FromDB(err)
+	}
+
+	// poin dipotong duluan, jadi poin yang baru didapet ga bisa langsung dipake di transaksi yang sama
+	for _, entry := range []models.PointEntry{
+		{Reason: models.PointReasonRedeem, Points: -pointsUsed},
+		{Reason: models.PointReasonEarn, Points: pointsEarned},
+	} {
+		if customer == nil || entry.Points == 0 {
+			continue
+		}
+		entry.CustomerID = customer.ID
+		entry.TransactionID = transaction.ID
+		if err := applyPoints(tx, &entry); err != nil {
+			repo.logger.Warn("Failed to apply customer points", "error", err, "customer_id", customer.ID, "reason", entry.Reason)
+			return nil, err
+		}
+	}
+
+	for i := range payments {
+		payments[i].TransactionID = transaction.ID
+		err := tx.QueryRow(
+			"INSERT INTO transaction_payments (transaction_id, method, amount, tendered, reference) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+			transaction.ID, payments[i].Method, payments[i].Amount, payments[i].Tendered, payments[i].Reference,
+		).Scan(&payments[i].ID)
+		if err != nil {
+			repo.logger.Error("Failed to insert payment", "error", err, "transaction_id", transaction.ID)
+			return nil, apperror.FromDB(err)
+		}
+	}
+	transaction.Payments = payments
+
+	for i := range details {
+		details[i].TransactionID = transaction.ID
+		transaction.ItemCount += details[i].Quantity
+		err := tx.QueryRow(
+			`INSERT INTO transaction_items (transaction_id, product_id, product_name, price, cost_price, quantity, subtotal,
+				discount_amount, cart_discount, tax_exempt, net_amount, tax_amount)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`,
+			transaction.ID, details[i].ProductID, details[i].ProductName, details[i].Price, details[i].CostPrice,
+			details[i].Quantity, details[i].Subtotal, details[i].Discount, details[i].CartDiscount,
+			details[i].TaxExempt, details[i].NetAmount, details[i].TaxAmount,
+		).Scan(&details[i].ID)
+		if err != nil {
+			repo.logger.Error("Failed to insert transaction item", "error", err, "transaction_id", transaction.ID)
+			return nil, apperror.FromDB(err)
+		}
+
+		lowStock, err := applyStockMovement(tx, &models.StockMovement{
+			ProductID:     details[i].ProductID,
+			Reason:        models.StockReasonSale,
+			Quantity:      -details[i].Quantity,
+			ReferenceType: "transaction",
+			ReferenceID:   transaction.ID,
+			CreatedBy:     req.Cashier,
+		})
+		if err != nil {
+			repo.logger.Error("Failed to decrement stock", "error", err, "product_id", details[i].ProductID)
+			return nil, err
+		}
+		if lowStock != nil {
+			transaction.LowStockAlerts = append(transaction.LowStockAlerts, *lowStock)
+		}
+	}
+
+	transaction.Items = details
+	return &transaction, nil
+}
+
+// priceCart - promo aktif (dibaca di tx yang sama) dievaluasi di timezone bisnis, lalu PPN-nya.
+// Sama persis kayak POST /api/cart/price, jadi harga quote = yang ditagih pas checkout
+func (repo *TransactionRepository) priceCart(tx *sql.Tx, lines []models.CartLine) (models.CartPrice, error) {
+	promotions, err := queryPromotions(tx, true)
+	if err != nil {
+		return models.CartPrice{}, err
+	}
+	price := promotion.Evaluate(lines, promotions, time.Now().In(repo.location))
+	repo.tax.ApplyCart(&price)
+	return price, nil
+}
+
+// applyPromotions - isi Discount & CartDiscount tiap baris details dari priceCart.
+// Baris keranjang disusun sesuai urutan items (bukan urutan lock), sama kayak quote,
+// biar alokasi & pembulatan diskonnya identik
+func (repo *TransactionRepository) applyPromotions(tx *sql.Tx, items []models.CheckoutItem, details []models.TransactionItem) error {
+	index := make(map[int]int, len(details))
+	for i, d := range details {
+		index[d.ProductID] = i
+	}
+	lines := make([]models.CartLine, 0, len(items))
+	for _, item := range items {
+		d := details[index[item.ProductID]]
+		lines = append(lines, models.CartLine{
+			ProductID:   d.ProductID,
+			ProductName: d.ProductName,
+			CategoryID:  d.CategoryID,
+			Price:       d.Price,
+			Quantity:    d.Quantity,
+			TaxExempt:   d.TaxExempt,
+		})
+	}
+
+	price, err := repo.priceCart(tx, lines)
+	if err != nil {
+		return err
+	}
+	for _, l := range price.Lines {
+		d := &details[index[l.ProductID]]
+		d.Discount = l.Discount
+		d.CartDiscount = l.CartDiscount
+	}
+	return nil
+}
+
+// settlePayments - cocokin pembayaran dengan total belanja. Payments kosong = satu kali bayar pas.
+// Kembalian dipotong dari pembayaran cash (mulai dari yang terakhir), jadi Amount
+// tiap baris = yang bener-bener masuk ke penjualan dan totalnya selalu = total belanja
+func settlePayments(total int, req *models.CheckoutRequest) ([]models.Payment, int, error) {
+	if len(req.Payments) == 0 {
+		return []models.Payment{{Method: req.PaymentMethod, Amount: total, Tendered: total}}, 0, nil
+	}
+
+	payments := make([]models.Payment, len(req.Payments))
+	paid, nonCash := 0, 0
+	for i, p := range req.Payments {
+		payments[i] = models.Payment{Method: p.Method, Amount: p.Amount, Tendered: p.Amount, Reference: p.Reference}
+		paid += p.Amount
+		if p.Method != models.PaymentCash {
+			nonCash += p.Amount
+		}
+	}
+	if paid < total {
+		return nil, 0, fmt.Errorf("%w: kurang %d", ErrPaymentInsufficient, total-paid)
+	}
+	if nonCash > total {
+		return nil, 0, ErrNonCashOverpayment
+	}
+
+	// nonCash <= total, jadi kembalian pasti <= total cash yang diserahin
+	change := paid - total
+	remaining := change
+	for i := len(payments) - 1; i >= 0 && remaining > 0; i-- {
+		if payments[i].Method != models.PaymentCash {
+			continue
+		}
+		take := min(remaining, payments[i].Amount)
+		payments[i].Amount -= take
+		remaining -= take
+	}
+	return payments, change, nil
+}
+
+// GetAll - list header transaksi sesuai filter, terbaru duluan.
+// Where clause disusun pake placeholder $n, nilai filter ga pernah di-concat ke query
+func (repo *TransactionRepository) GetAll(filter models.TransactionFilter) ([]models.Transaction, int, error) {
+	repo.logger.Info("Fetching transactions", "page", filter.Page, "limit", filter.Limit)
+
+	where := "WHERE 1=1"
+	args := make([]interface{}, 0, 6)
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		where += fmt.Sprintf(" AND t.created_at >= $%d", len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		where += fmt.Sprintf(" AND t.created_at < $%d", len(args))
+	}
+	if filter.Cashier != "" {
+		args = append(args, filter.Cashier)
+		where += fmt.Sprintf(" AND t.cashier = $%d", len(args))
+	}
+	if filter.CustomerID != 0 {
+		args = append(args, filter.CustomerID)
+		where += fmt.Sprintf(" AND t.customer_id = $%d", len(args))
+	}
+	if filter.PaymentMethod != "" {
+		// transaksi split ikut kalau salah satu pembayarannya pake metode ini
+		args = append(args, filter.PaymentMethod)
+		where += fmt.Sprintf(" AND (t.payment_method = $%d OR EXISTS (SELECT 1 FROM transaction_payments tp WHERE tp.transaction_id = t.id AND tp.method = $%d))", len(args), len(args))
+	}
+
+	var total int
+	err := repo.db.QueryRow("SELECT COUNT(*) FROM transactions t "+where, args...).Scan(&total)
+	if err != nil {
+		repo.logger.Error("Failed to count transactions", "error", err)
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query := fmt.Sprintf(`
+		SELECT t.id, t.cashier, COALESCE(t.shift_id, 0), COALESCE(t.customer_id, 0), t.payment_method, t.total_amount, t.tax_amount,
+			t.paid_amount, t.change_amount, t.points_earned, t.points_used,
+			COALESCE((SELECT SUM(rf.total_amount) FROM refunds rf WHERE rf.transaction_id = t.id), 0),
+			COALESCE((SELECT SUM(ti.quantity) FROM transaction_items ti WHERE ti.transaction_id = t.id), 0),
+			t.created_at
+		FROM transactions t
+		%s
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		repo.logger.Error("Failed to fetch transactions", "error", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	transactions := make([]models.Transaction, 0)
+	for rows.Next() {
+		var t models.Transaction
+		err := rows.Scan(&t.ID, &t.Cashier, &t.ShiftID, &t.CustomerID, &t.PaymentMethod, &t.TotalAmount, &t.TaxAmount,
+			&t.PaidAmount, &t.ChangeAmount, &t.PointsEarned, &t.PointsUsed, &t.RefundAmount, &t.ItemCount, &t.CreatedAt)
+		if err != nil {
+			repo.logger.Error("Failed to scan transaction", "error", err)
+			return nil, 0, err
+		}
+		transactions = append(transactions, t)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate transactions", "error", err)
+		return nil, 0, err
+	}
+
+	repo.logger.Info("Successfully fetched transactions", "count", len(transactions), "total", total)
+	return transactions, total, nil
+}
+
+// GetByID - header + item, item di-join ke products & categories buat nama kategori
+func (repo *TransactionRepository) GetByID(id int) (*models.Transaction, error) {
+	repo.logger.Info("Fetching transaction by ID", "id", id)
+
+	var t models.Transaction
+	var rateBP int
+	err := repo.db.QueryRow(`
+		SELECT t.id, t.cashier, COALESCE(t.shift_id, 0), COALESCE(t.customer_id, 0), t.payment_method, t.total_amount,
+			t.tax_amount, t.tax_rate_bp, t.tax_mode, t.paid_amount, t.change_amount, t.points_earned, t.points_used,
+			COALESCE((SELECT SUM(rf.total_amount) FROM refunds rf WHERE rf.transaction_id = t.id), 0),
+			t.created_at
+		FROM transactions t
+		WHERE t.id = $1
+	`, id).Scan(&t.ID, &t.Cashier, &t.ShiftID, &t.CustomerID, &t.PaymentMethod, &t.TotalAmount,
+		&t.TaxAmount, &rateBP, &t.TaxMode, &t.PaidAmount, &t.ChangeAmount, &t.PointsEarned, &t.PointsUsed,
+		&t.RefundAmount, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Transaction not found", "id", id)
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to fetch transaction by ID", "error", err, "id", id)
+		return nil, err
+	}
+	t.TaxRate = tax.Config{RateBP: rateBP}.RatePercent()
+
+	rows, err := repo.db.Query(`
+		SELECT ti.id, ti.transaction_id, ti.product_id, ti.product_name,
+			COALESCE(p.category_id, 0), COALESCE(c.name, ''),
+			ti.price, ti.cost_price, ti.quantity, ti.subtotal, ti.discount_amount, ti.cart_discount,
+			ti.tax_exempt, ti.net_amount, ti.tax_amount,
+			COALESCE((SELECT SUM(ri.quantity) FROM refund_items ri WHERE ri.transaction_item_id = ti.id), 0)
+		FROM transaction_items ti
+		LEFT JOIN products p ON ti.product_id = p.id
+		LEFT JOIN categories c ON p.category_id = c.id
+		WHERE ti.transaction_id = $1
+		ORDER BY ti.id
+	`, id)
+	if err != nil {
+		repo.logger.Error("Failed to fetch transaction items", "error", err, "id", id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	t.Items = make([]models.TransactionItem, 0)
+	for rows.Next() {
+		var item models.TransactionItem
+		err := rows.Scan(&item.ID, &item.TransactionID, &item.ProductID, &item.ProductName,
+			&item.CategoryID, &item.CategoryName, &item.Price, &item.CostPrice, &item.Quantity, &item.Subtotal,
+			&item.Discount, &item.CartDiscount, &item.TaxExempt, &item.NetAmount, &item.TaxAmount, &item.RefundedQty)
+		if err != nil {
+			repo.logger.Error("Failed to scan transaction item", "error", err)
+			return nil, err
+		}
+		t.ItemCount += item.Quantity
+		t.Items = append(t.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate transaction items", "error", err)
+		return nil, err
+	}
+
+	payRows, err := repo.db.Query(
+		"SELECT id, transaction_id, method, amount, tendered, reference FROM transaction_payments WHERE transaction_id = $1 ORDER BY id",
+		id,
+	)
+	if err != nil {
+		repo.logger.Error("Failed to fetch transaction payments", "error", err, "id", id)
+		return nil, err
+	}
+	defer payRows.Close()
+
+	t.Payments = make([]models.Payment, 0)
+	for payRows.Next() {
+		var p models.Payment
+		if err := payRows.Scan(&p.ID, &p.TransactionID, &p.Method, &p.Amount, &p.Tendered, &p.Reference); err != nil {
+			repo.logger.Error("Failed to scan payment", "error", err)
+			return nil, err
+		}
+		t.Payments = append(t.Payments, p)
+	}
+	if err := payRows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate payments", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched transaction", "id", id, "items", len(t.Items))
+	return &t, nil
+}
+
+// CreateRefund - retur full/sebagian. Header transaksi di-lock duluan biar dua refund
+// barengan ke transaksi yang sama ga bisa lolos ngelebihin qty terjual.
+// Stok dibalikin di SQL transaction yang sama.
+// Nominal refund (termasuk PPN) dibagi proporsional dari baris asal, lihat refundShare.
+// Poin member hasil transaksi ini ikut ditarik sebanding nominal yang di-refund
+func (repo *TransactionRepository) CreateRefund(transactionID int, req *models.RefundRequest, actor models.AuditActor) (*models.Refund, error) {
+	repo.logger.Info("Creating refund", "transaction_id", transactionID, "item_count", len(req.Items))
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var saleTotal, customerID, pointsEarned, pointsUsed, refundedBefore int
+	err = tx.QueryRow(
+		`SELECT total_amount, COALESCE(customer_id, 0), points_earned, points_used,
+			COALESCE((SELECT SUM(rf.total_amount) FROM refunds rf WHERE rf.transaction_id = t.id), 0)
+		 FROM transactions t WHERE t.id = $1 FOR UPDATE`,
+		transactionID,
+	).Scan(&saleTotal, &customerID, &pointsEarned, &pointsUsed, &refundedBefore)
+	if err == sql.ErrNoRows {
+		repo.logger.Warn("Transaction not found for refund", "transaction_id", transactionID)
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to lock transaction", "error", err, "transaction_id", transactionID)
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT ti.id, ti.product_id, ti.product_name, ti.price, ti.quantity, ti.net_amount, ti.tax_amount,
+			COALESCE((SELECT SUM(ri.quantity) FROM refund_items ri WHERE ri.transaction_item_id = ti.id), 0)
+		FROM transaction_items ti
+		WHERE ti.transaction_id = $1
+		ORDER BY ti.product_id
+	`, transactionID)
+	if err != nil {
+		repo.logger.Error("Failed to fetch transaction items for refund", "error", err)
+		return nil, err
+	}
+
+	// sisa qty yang masih bisa di-refund per product_id
+	type soldLine struct {
+		item      models.RefundItem
+		quantity  int
+		refunded  int
+		net       int
+		lineTax   int
+		remaining int
+	}
+	sold := make([]soldLine, 0)
+	byProduct := make(map[int]int)
+	for rows.Next() {
+		var line soldLine
+		err := rows.Scan(&line.item.TransactionItemID, &line.item.ProductID, &line.item.ProductName,
+			&line.item.Price, &line.quantity, &line.net, &line.lineTax, &line.refunded)
+		if err != nil {
+			rows.Close()
+			repo.logger.Error("Failed to scan transaction item", "error", err)
+			return nil, err
+		}
+		line.remaining = line.quantity - line.refunded
+		byProduct[line.item.ProductID] = len(sold)
+		sold = append(sold, line)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate transaction items", "error", err)
+		return nil, err
+	}
+
+	lines := make([]models.RefundItem, 0)
+	if len(req.Items) == 0 {
+		for _, line := range sold {
+			if line.remaining > 0 {
+				item := line.item
+				item.Quantity = line.remaining
+				lines = append(lines, item)
+			}
+		}
+	} else {
+		for _, reqItem := range req.Items {
+			i, ok := byProduct[reqItem.ProductID]
+			if !ok {
+				return nil, fmt.Errorf("%w: product_id %d", ErrRefundItemNotInSale, reqItem.ProductID)
+			}
+			if reqItem.Quantity > sold[i].remaining {
+				return nil, fmt.Errorf("%w: %s (sisa %d, diminta %d)",
+					ErrRefundExceedsSold, sold[i].item.ProductName, sold[i].remaining, reqItem.Quantity)
+			}
+			item := sold[i].item
+			item.Quantity = reqItem.Quantity
+			lines = append(lines, item)
+		}
+		sort.Slice(lines, func(i, j int) bool { return lines[i].ProductID < lines[j].ProductID })
+	}
+	if len(lines) == 0 {
+		return nil, ErrNothingToRefund
+	}
+
+	refund := models.Refund{TransactionID: transactionID, Reason: req.Reason}
+	for i := range lines {
+		line := sold[byProduct[lines[i].ProductID]]
+		gross := refundShare(line.net+line.lineTax, line.quantity, line.refunded, lines[i].Quantity)
+		lines[i].TaxAmount = refundShare(line.lineTax, line.quantity, line.refunded, lines[i].Quantity)
+		lines[i].NetAmount = gross - lines[i].TaxAmount
+		lines[i].Subtotal = gross
+		refund.TotalAmount += gross
+		refund.TaxAmount += lines[i].TaxAmount
+	}
+
+	tenders, err := refundTenders(tx, transactionID)
+	if err != nil {
+		repo.logger.Error("Failed to fetch payments for refund", "error", err, "transaction_id", transactionID)
+		return nil, err
+	}
+	refund.Payments = splitRefund(tenders, refundedBefore, refund.TotalAmount)
+	refund.PaidOut = refundPaidOut(refund.Payments)
+
+	// uang refund keluar dari laci shift yang lagi dibuka user ini (kalau ada)
+	var shiftID *int
+	id, err := lockOpenShift(tx, req.UserID)
+	switch {
+	case err == nil:
+		shiftID = &id
+		refund.ShiftID = id
+	case !errors.Is(err, ErrNoOpenShift):
+		repo.logger.Error("Failed to lock shift for refund", "error", err, "user_id", req.UserID)
+		return nil, err
+	}
+
+	err = tx.QueryRow(
+		"INSERT INTO refunds (transaction_id, shift_id, reason, total_amount, tax_amount) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		transactionID, shiftID, req.Reason, refund.TotalAmount, refund.TaxAmount,
+	).Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil {
+		repo.logger.Error("Failed to insert refund", "error", err)
+		return nil, apperror.FromDB(err)
+	}
+
+	for i := range refund.Payments {
+		refund.Payments[i].RefundID = refund.ID
+		err := tx.QueryRow(
+			"INSERT INTO refund_payments (refund_id, method, amount) VALUES ($1, $2, $3) RETURNING id",
+			refund.ID, refund.Payments[i].Method, refund.Payments[i].Amount,
+		).Scan(&refund.Payments[i].ID)
+		if err != nil {
+			repo.logger.Error("Failed to insert refund payment", "error", err, "refund_id", refund.ID)
+			return nil, apperror.FromDB(err)
+		}
+	}
+
+	for i := range lines {
+		_, err := applyStockMovement(tx, &models.StockMovement{
+			ProductID:     lines[i].ProductID,
+			Reason:        models.StockReasonRefund,
+			Quantity:      lines[i].Quantity,
+			ReferenceType: "refund",
+			ReferenceID:   refund.ID,
+			Note:          req.Reason,
+			CreatedBy:     req.User,
+		})
+		if err != nil {
+			repo.logger.Error("Failed to restore stock", "error", err, "product_id", lines[i].ProductID)
+			return nil, err
+		}
+
+		lines[i].RefundID = refund.ID
+		err = tx.QueryRow(
+			`INSERT INTO refund_items (refund_id, transaction_item_id, product_id, product_name, price, quantity, subtotal,
+				net_amount, tax_amount)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+			refund.ID, lines[i].TransactionItemID, lines[i].ProductID, lines[i].ProductName,
+			lines[i].Price, lines[i].Quantity, lines[i].Subtotal, lines[i].NetAmount, lines[i].TaxAmount,
+		).Scan(&lines[i].ID)
+		if err != nil {
+			repo.logger.Error("Failed to insert refund item", "error", err, "refund_id", refund.ID)
+			return nil, apperror.FromDB(err)
+		}
+	}
+
+	// poin yang dipake bayar dibalikin duluan, baru poin hasil belanja ditarik dari saldo
+	if customerID != 0 && pointsUsed > 0 {
+		if err := repo.restoreRedeemedPoints(tx, &refund, customerID, pointsUsed, tenders); err != nil {
+			repo.logger.Error("Failed to restore redeemed points", "error", err, "customer_id", customerID)
+			return nil, err
+		}
+	}
+	if customerID != 0 && pointsEarned > 0 {
+		if err := repo.reversePoints(tx, &refund, customerID, pointsEarned, saleTotal, refundedBefore); err != nil {
+			repo.logger.Error("Failed to reverse customer points", "error", err, "customer_id", customerID)
+			return nil, err
+		}
+	}
+
+	refund.Items = lines
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "refund", refund.ID, nil, refund); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", refund.ID)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit refund", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Refund created successfully", "id", refund.ID, "transaction_id", transactionID, "total_amount", refund.TotalAmount)
+	return &refund, nil
+}
+
+// reversePoints - tarik poin yang didapet dari transaksi sebanding nominal refund.
+// Kalau poinnya udah keburu dipake, yang ditarik cuma sisa saldo biar ga minus
+func (repo *TransactionRepository) reversePoints(tx *sql.Tx, refund *models.Refund, customerID, earned, saleTotal, refundedBefore int) error {
+	var balance int
+	err := tx.QueryRow("SELECT points FROM customers WHERE id = $1 FOR UPDATE", customerID).Scan(&balance)
+	if err != nil {
+		return err
+	}
+	points := min(refundShare(earned, saleTotal, refundedBefore, refund.TotalAmount), balance)
+	if points <= 0 {
+		return nil
+	}
+	err = applyPoints(tx, &models.PointEntry{
+		CustomerID:    customerID,
+		Reason:        models.PointReasonRefund,
+		Points:        -points,
+		TransactionID: refund.TransactionID,
+		RefundID:      refund.ID,
+	})
+	if err != nil {
+		return err
+	}
+	refund.PointsReversed = points
+	return nil
+}
+
+// tenderShare - satu metode bayar di transaksi asal: paid = yang masuk ke penjualan,
+// refunded = yang udah balik lewat refund sebelumnya
+type tenderShare struct {
+	method   string
+	paid     int
+	refunded int
+}
+
+func refundTenders(tx *sql.Tx, transactionID int) ([]tenderShare, error) {
+	rows, err := tx.Query(`
+		SELECT tp.method, SUM(tp.amount),
+			COALESCE((SELECT SUM(rp.amount) FROM refund_payments rp JOIN refunds rf ON rf.id = rp.refund_id
+				WHERE rf.transaction_id = tp.transaction_id AND rp.method = tp.method), 0)
+		FROM transaction_payments tp
+		WHERE tp.transaction_id = $1
+		GROUP BY tp.transaction_id, tp.method
+		ORDER BY tp.method
+	`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenders := make([]tenderShare, 0)
+	for rows.Next() {
+		var t tenderShare
+		if err := rows.Scan(&t.method, &t.paid, &t.refunded); err != nil {
+			return nil, err
+		}
+		tenders = append(tenders, t)
+	}
+	return tenders, rows.Err()
+}
+
+// splitRefund - bagi nominal refund ke metode bayar transaksi asal, proporsional sama porsi bayarnya.
+// Kumulatif kayak refundShare: target tiap metode = paid × (refundedBefore + amount) / total bayar,
+// dikurangi yang udah balik sebelumnya, jadi pas semua barang akhirnya di-refund tiap metode balik pas sebesar paid-nya.
+// Sisa pembulatan dikasih ke cash duluan, baru metode lain yang masih ada sisa
+func splitRefund(tenders []tenderShare, refundedBefore, amount int) []models.RefundPayment {
+	total := 0
+	for _, t := range tenders {
+		total += t.paid
+	}
+	payments := make([]models.RefundPayment, 0, len(tenders))
+	if total <= 0 || amount <= 0 {
+		return payments
+	}
+
+	shares := make([]int, len(tenders))
+	left := amount
+	for i, t := range tenders {
+		target := min(t.paid*(refundedBefore+amount)/total, t.paid)
+		shares[i] = max(0, min(target-t.refunded, left))
+		left -= shares[i]
+	}
+	for _, cashOnly := range []bool{true, false} {
+		for i, t := range tenders {
+			if left == 0 || cashOnly != (t.method == models.PaymentCash) {
+				continue
+			}
+			take := max(0, min(t.paid-t.refunded-shares[i], left))
+			shares[i] += take
+			left -= take
+		}
+	}
+	// cuma kejadian kalau data refund lama ga konsisten; tetep dicatat biar totalnya sama
+	if left > 0 {
+		shares[len(shares)-1] += left
+	}
+
+	for i, t := range tenders {
+		if shares[i] > 0 {
+			payments = append(payments, models.RefundPayment{Method: t.method, Amount: shares[i]})
+		}
+	}
+	return payments
+}
+
+// refundPaidOut - uang yang balik ke customer. Tender points ga ikut, itu balik jadi saldo poin
+func refundPaidOut(payments []models.RefundPayment) int {
+	total := 0
+	for _, p := range payments {
+		if p.Method != models.PaymentPoints {
+			total += p.Amount
+		}
+	}
+	return total
+}
+
+// restoreRedeemedPoints - bagian refund yang jatuh ke tender points dibalikin jadi poin, bukan uang.
+// Konversinya pake rasio points_used / nominal tender poin di transaksi asal (bukan config nilai poin sekarang),
+// kumulatif biar pas semua barang di-refund, poin yang balik pas sama points_used
+func (repo *TransactionRepository) restoreRedeemedPoints(tx *sql.Tx, refund *models.Refund, customerID, pointsUsed int, tenders []tenderShare) error {
+	amount := 0
+	for _, p := range refund.Payments {
+		if p.Method == models.PaymentPoints {
+			amount += p.Amount
+		}
+	}
+	for _, t := range tenders {
+		if t.method != models.PaymentPoints || t.paid <= 0 || amount == 0 {
+			continue
+		}
+		points := refundShare(pointsUsed, t.paid, t.refunded, amount)
+		if points <= 0 {
+			return nil
+		}
+		err := applyPoints(tx, &models.PointEntry{
+			CustomerID:    customerID,
+			Reason:        models.PointReasonRefund,
+			Points:        points,
+			TransactionID: refund.TransactionID,
+			RefundID:      refund.ID,
+		})
+		if err != nil {
+			return err
+		}
+		refund.PointsRestored = points
+	}
+	return nil
+}
+
+// refundShare - bagian amount buat refund qty unit, kalau sebelumnya udah ke-refund
+// refunded unit dari total quantity. Dihitung kumulatif (dibulatkan ke bawah) jadi kalau
+// semua unit akhirnya di-refund, jumlah semua bagiannya pas sama amount, ga ada selisih pembulatan
+func refundShare(amount, quantity, refunded, qty int) int {
+	if quantity <= 0 {
+		return 0
+	}
+	return amount*(refunded+qty)/quantity - amount*refunded/quantity
+}
@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"log/slog"
+	"time"
+)
+
+var (
+	ErrUserNotFound        = apperror.New(apperror.ErrNotFound, "user tidak ditemukan")
+	ErrUsernameTaken       = apperror.New(apperror.ErrConflict, "username sudah dipakai")
+	ErrRefreshTokenInvalid = apperror.New(apperror.ErrUnauthorized, "refresh token tidak valid atau sudah kadaluarsa")
+)
+
+type UserRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewUserRepository(db *sql.DB, logger *slog.Logger) *UserRepository {
+	return &UserRepository{db: db, logger: logger}
+}
+
+func (repo *UserRepository) Create(user *models.User, actor models.AuditActor) error {
+	repo.logger.Info("Creating user", "username", user.Username)
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		repo.logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"INSERT INTO users (username, name, password_hash, role, active) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		user.Username, user.Name, user.PasswordHash, user.Role, user.Active,
+	).Scan(&user.ID, &user.CreatedAt)
+	if err != nil {
+		if apperror.IsUniqueViolation(err) {
+			repo.logger.Warn("Username already taken", "username", user.Username)
+			return ErrUsernameTaken
+		}
+		repo.logger.Error("Failed to create user", "error", err, "username", user.Username)
+		return apperror.FromDB(err)
+	}
+
+	// password_hash ga ikut ke snapshot karena tag json:"-"
+	if err := insertAudit(tx, actor, models.AuditActionCreate, "user", user.ID, nil, user); err != nil {
+		repo.logger.Error("Failed to write audit log", "error", err, "id", user.ID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		repo.logger.Error("Failed to commit user", "error", err)
+		return err
+	}
+	repo.logger.Info("User created successfully", "id", user.ID, "username", user.Username)
+	return nil
+}
+
+const userColumns = "id, username, name, password_hash, role, active, created_at"
+
+func scanUser(row interface{ Scan(...interface{}) error }, u *models.User) error {
+	return row.Scan(&u.ID, &u.Username, &u.Name, &u.PasswordHash, &u.Role, &u.Active, &u.CreatedAt)
+}
+
+func (repo *UserRepository) GetByUsername(username string) (*models.User, error) {
+	repo.logger.Info("Fetching user by username", "username", username)
+	var u models.User
+	err := scanUser(repo.db.QueryRow("SELECT "+userColumns+" FROM users WHERE username = $1", username), &u)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to fetch user", "error", err, "username", username)
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (repo *UserRepository) GetByID(id int) (*models.User, error) {
+	repo.logger.Info("Fetching user by ID", "id", id)
+	var u models.User
+	err := scanUser(repo.db.QueryRow("SELECT "+userColumns+" FROM users WHERE id = $1", id), &u)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		repo.logger.Error("Failed to fetch user", "error", err, "id", id)
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (repo *UserRepository) GetAll() ([]models.User, error) {
+	repo.logger.Info("Fetching all users")
+	rows, err := repo.db.Query("SELECT " + userColumns + " FROM users ORDER BY username")
+	if err != nil {
+		repo.logger.Error("Failed to fetch users", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0)
+	for rows.Next() {
+		var u models.User
+		if err := scanUser(rows, &u); err != nil {
+			repo.logger.Error("Failed to scan user", "error", err)
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		repo.logger.Error("Failed to iterate users", "error", err)
+		return nil, err
+	}
+
+	repo.logger.Info("Successfully fetched users", "count", len(users))
+	return users, nil
+}
+
+func (repo *UserRepository) Count() (int, error) {
+	var count int
+	err := repo.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+	return count, err
+}
+
+func (repo *UserRepository) SaveRefreshToken(userID int, tokenHash string, expiresAt time.Time) error {
+	_, err := repo.db.Exec(
+		"INSERT INTO refresh_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3)",
+		userID, tokenHash, expiresAt,
+	)
+	if err != nil {
+		repo.logger.Error("Failed to save refresh token", "error", err, "user_id", userID)
+	}
+	return err
+}
+
+// ConsumeRefreshToken - revoke token dan return user_id-nya dalam satu statement,
+// jadi refresh token yang sama ga bisa dipake dua kali (rotation)
+func (repo *UserRepository) ConsumeRefreshToken(tokenHash string) (int, error) {
+	var userID int
+	err := repo.db.QueryRow(`
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`, tokenHash).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		repo.logger.Error("Failed to consume refresh token", "error", err)
+		return 0, err
+	}
+	return userID, nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+)
+
+type AuditService struct {
+	repo   *repositories.AuditRepository
+	logger *slog.Logger
+}
+
+func NewAuditService(repo *repositories.AuditRepository, logger *slog.Logger) *AuditService {
+	return &AuditService{repo: repo, logger: logger}
+}
+
+func (s *AuditService) GetAll(filter models.AuditFilter) (*models.AuditLogList, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if filter.Limit > 200 {
+		filter.Limit = 200
+	}
+
+	s.logger.Info("Service: Getting audit logs", "page", filter.Page, "limit", filter.Limit)
+	logs, total, err := s.repo.GetAll(filter)
+	if err != nil {
+		s.logger.Error("Service: Failed to get audit logs", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved audit logs", "count", len(logs), "total", total)
+	return &models.AuditLogList{Data: logs, Page: filter.Page, Limit: filter.Limit, Total: total}, nil
+}
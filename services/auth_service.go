@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"kasir-api/internal/apperror"
+	"kasir-api/internal/auth"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var ErrInvalidCredentials = apperror.New(apperror.ErrUnauthorized, "username atau password salah")
+
+// dummyPasswordHash - dicek kalau username ga ketemu, biar waktu responnya sama kayak password salah
+// dan username yang terdaftar ga bisa ditebak dari timing
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := auth.HashPassword("dummy-password")
+	return hash
+})
+
+type AuthService struct {
+	users      *repositories.UserRepository
+	tokens     *auth.TokenManager
+	refreshTTL time.Duration
+	logger     *slog.Logger
+}
+
+func NewAuthService(users *repositories.UserRepository, tokens *auth.TokenManager, refreshTTL time.Duration, logger *slog.Logger) *AuthService {
+	dummyPasswordHash() // dihitung di awal biar login pertama ga lebih lama
+	return &AuthService{users: users, tokens: tokens, refreshTTL: refreshTTL, logger: logger}
+}
+
+func (s *AuthService) Login(req *models.LoginRequest) (*models.TokenResponse, error) {
+	s.logger.Info("Service: Login attempt", "username", req.Username)
+
+	user, err := s.users.GetByUsername(req.Username)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		auth.CheckPassword(dummyPasswordHash(), req.Password)
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		s.logger.Error("Service: Failed to load user", "error", err)
+		return nil, err
+	}
+
+	ok, err := auth.CheckPassword(user.PasswordHash, req.Password)
+	if err != nil {
+		s.logger.Error("Service: Failed to check password", "error", err, "user_id", user.ID)
+		return nil, err
+	}
+	if !ok || !user.Active {
+		s.logger.Warn("Service: Login rejected", "username", req.Username, "active", user.Active)
+		return nil, ErrInvalidCredentials
+	}
+
+	s.logger.Info("Service: Login successful", "user_id", user.ID)
+	return s.issueTokens(user)
+}
+
+// Refresh - refresh token lama langsung di-revoke, diganti pasangan token baru
+func (s *AuthService) Refresh(refreshToken string) (*models.TokenResponse, error) {
+	userID, err := s.users.ConsumeRefreshToken(auth.HashRefreshToken(refreshToken))
+	if err != nil {
+		s.logger.Warn("Service: Refresh rejected", "error", err)
+		return nil, err
+	}
+
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		s.logger.Error("Service: Failed to load user for refresh", "error", err, "user_id", userID)
+		return nil, err
+	}
+	if !user.Active {
+		return nil, repositories.ErrRefreshTokenInvalid
+	}
+
+	s.logger.Info("Service: Token refreshed", "user_id", user.ID)
+	return s.issueTokens(user)
+}
+
+// Logout - revoke refresh token. Access token tetap valid sampai expired (umurnya pendek)
+func (s *AuthService) Logout(refreshToken string) error {
+	_, err := s.users.ConsumeRefreshToken(auth.HashRefreshToken(refreshToken))
+	if err != nil && !errors.Is(err, repositories.ErrRefreshTokenInvalid) {
+		s.logger.Error("Service: Failed to revoke refresh token", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *AuthService) issueTokens(user *models.User) (*models.TokenResponse, error) {
+	accessToken, err := s.tokens.Sign(auth.Claims{UserID: user.ID, Username: user.Username, Role: user.Role})
+	if err != nil {
+		s.logger.Error("Service: Failed to sign access token", "error", err)
+		return nil, err
+	}
+
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
+	if err != nil {
+		s.logger.Error("Service: Failed to generate refresh token", "error", err)
+		return nil, err
+	}
+	if err := s.users.SaveRefreshToken(user.ID, refreshHash, time.Now().Add(s.refreshTTL)); err != nil {
+		return nil, err
+	}
+
+	return &models.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.tokens.AccessTTL().Seconds()),
+		User:         *user,
+	}, nil
+}
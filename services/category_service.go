@@ -7,28 +7,29 @@ import (
 )
 
 type CategoryService struct {
-	repo   *repositories.CategoryRepository
+	repo   repositories.CategoryStore
 	logger *slog.Logger
 }
 
-func NewCategoryService(repo *repositories.CategoryRepository, logger *slog.Logger) *CategoryService {
+func NewCategoryService(repo repositories.CategoryStore, logger *slog.Logger) *CategoryService {
 	return &CategoryService{repo: repo, logger: logger}
 }
 
-func (s *CategoryService) GetAll() ([]models.Category, error) {
-	s.logger.Info("Service: Getting all categories")
-	categories, err := s.repo.GetAll()
+func (s *CategoryService) GetAll(filter models.CategoryFilter) (*models.CategoryList, error) {
+	listDefaults(&filter.ListParams)
+	s.logger.Info("Service: Getting categories", "sort", filter.Sort, "limit", filter.Limit)
+	list, err := s.repo.GetAll(filter)
 	if err != nil {
-		s.logger.Error("Service: Failed to get all categories", "error", err)
+		s.logger.Error("Service: Failed to get categories", "error", err)
 		return nil, err
 	}
-	s.logger.Info("Service: Successfully retrieved categories", "count", len(categories))
-	return categories, nil
+	s.logger.Info("Service: Successfully retrieved categories", "count", len(list.Data))
+	return list, nil
 }
 
-func (s *CategoryService) Create(data *models.Category) error {
+func (s *CategoryService) Create(data *models.Category, actor models.AuditActor) error {
 	s.logger.Info("Service: Creating category", "name", data.Name)
-	err := s.repo.Create(data)
+	err := s.repo.Create(data, actor)
 	if err != nil {
 		s.logger.Error("Service: Failed to create category", "error", err, "name", data.Name)
 		return err
@@ -37,9 +38,9 @@ func (s *CategoryService) Create(data *models.Category) error {
 	return nil
 }
 
-func (s *CategoryService) Delete(id int) error {
+func (s *CategoryService) Delete(id int, actor models.AuditActor) error {
 	s.logger.Info("Service: Deleting category", "id", id)
-	err := s.repo.Delete(id)
+	err := s.repo.Delete(id, actor)
 	if err != nil {
 		s.logger.Error("Service: Failed to delete category (may have foreign key constraint)", "error", err, "id", id)
 		return err
@@ -59,9 +60,9 @@ func (s *CategoryService) GetByID(id int) (*models.Category, error) {
 	return category, nil
 }
 
-func (s *CategoryService) Update(data *models.Category) error {
+func (s *CategoryService) Update(data *models.Category, actor models.AuditActor) error {
 	s.logger.Info("Service: Updating category", "id", data.ID)
-	err := s.repo.Update(data)
+	err := s.repo.Update(data, actor)
 	if err != nil {
 		s.logger.Error("Service: Failed to update category", "error", err, "id", data.ID)
 		return err
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"kasir-api/internal/apperror"
+	"kasir-api/internal/loyalty"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"strings"
+)
+
+var ErrInvalidPhone = apperror.New(apperror.ErrValidation, "nomor HP tidak valid, pakai format 08xxx atau +628xxx")
+
+type CustomerService struct {
+	repo         *repositories.CustomerRepository
+	transactions *repositories.TransactionRepository
+	loyalty      loyalty.Config
+	logger       *slog.Logger
+}
+
+func NewCustomerService(repo *repositories.CustomerRepository, transactions *repositories.TransactionRepository, loyaltyConfig loyalty.Config, logger *slog.Logger) *CustomerService {
+	return &CustomerService{repo: repo, transactions: transactions, loyalty: loyaltyConfig, logger: logger}
+}
+
+// GetAll - search nomor HP ikut dinormalisasi, jadi "+6281..." tetap ketemu
+func (s *CustomerService) GetAll(search string) ([]models.Customer, error) {
+	search = strings.TrimSpace(search)
+	if phone, err := normalizePhone(search); err == nil {
+		search = phone
+	}
+	s.logger.Info("Service: Getting customers", "search", search)
+	customers, err := s.repo.GetAll(search)
+	if err != nil {
+		s.logger.Error("Service: Failed to get customers", "error", err)
+		return nil, err
+	}
+	return customers, nil
+}
+
+func (s *CustomerService) GetByID(id int) (*models.Customer, error) {
+	s.logger.Info("Service: Getting customer by ID", "id", id)
+	return s.repo.GetByID(id)
+}
+
+func (s *CustomerService) Create(customer *models.Customer, actor models.AuditActor) error {
+	s.logger.Info("Service: Creating customer", "phone", customer.Phone)
+	if err := normalizeCustomer(customer); err != nil {
+		return err
+	}
+	if err := s.repo.Create(customer, actor); err != nil {
+		s.logger.Error("Service: Failed to create customer", "error", err)
+		return err
+	}
+	s.logger.Info("Service: Customer created successfully", "id", customer.ID)
+	return nil
+}
+
+func (s *CustomerService) Update(customer *models.Customer, actor models.AuditActor) error {
+	s.logger.Info("Service: Updating customer", "id", customer.ID)
+	if err := normalizeCustomer(customer); err != nil {
+		return err
+	}
+	if err := s.repo.Update(customer, actor); err != nil {
+		s.logger.Error("Service: Failed to update customer", "error", err, "id", customer.ID)
+		return err
+	}
+	s.logger.Info("Service: Customer updated successfully", "id", customer.ID)
+	return nil
+}
+
+// Points - saldo + nilai rupiahnya + mutasi terbaru (default 50, max 200)
+func (s *CustomerService) Points(id, limit int) (*models.CustomerPoints, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	s.logger.Info("Service: Getting customer points", "id", id, "limit", limit)
+	customer, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.PointEntries(id, limit)
+	if err != nil {
+		s.logger.Error("Service: Failed to get point entries", "error", err, "id", id)
+		return nil, err
+	}
+	return &models.CustomerPoints{
+		Customer:   *customer,
+		Balance:    customer.Points,
+		PointValue: s.loyalty.PointValue,
+		Value:      customer.Points * s.loyalty.PointValue,
+		Entries:    entries,
+	}, nil
+}
+
+// Transactions - riwayat belanja member, terbaru duluan
+func (s *CustomerService) Transactions(id int, filter models.TransactionFilter) (*models.TransactionList, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	filter.CustomerID = id
+	pageDefaults(&filter)
+
+	s.logger.Info("Service: Getting customer transactions", "id", id, "page", filter.Page, "limit", filter.Limit)
+	transactions, total, err := s.transactions.GetAll(filter)
+	if err != nil {
+		s.logger.Error("Service: Failed to get customer transactions", "error", err, "id", id)
+		return nil, err
+	}
+	return &models.TransactionList{Data: transactions, Page: filter.Page, Limit: filter.Limit, Total: total}, nil
+}
+
+func normalizeCustomer(customer *models.Customer) error {
+	phone, err := normalizePhone(customer.Phone)
+	if err != nil {
+		return err
+	}
+	customer.Phone = phone
+	customer.Name = strings.TrimSpace(customer.Name)
+	return nil
+}
+
+// normalizePhone - buang spasi/strip/titik, +62/62 di depan diganti 0.
+// Hasilnya wajib 08xxx, 10-15 digit
+func normalizePhone(phone string) (string, error) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0, r == ' ', r == '-', r == '.', r == '(', r == ')':
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	digits := b.String()
+	if strings.HasPrefix(digits, "62") {
+		digits = "0" + digits[2:]
+	}
+	if !strings.HasPrefix(digits, "08") || len(digits) < 10 || len(digits) > 15 {
+		return "", ErrInvalidPhone
+	}
+	return digits, nil
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kasir-api/internal/alert"
+	"kasir-api/internal/apperror"
+	"kasir-api/internal/auth"
+	"kasir-api/internal/payment"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrInvalidStockPolicy    = errors.New("QRIS_STOCK_POLICY harus none atau reserve")
+	ErrPendingSaleForbidden  = apperror.New(apperror.ErrForbidden, "sale QRIS ini bukan milik kamu")
+	ErrWebhookAmountMismatch = apperror.New(apperror.ErrValidation, "nominal webhook tidak sama dengan total sale")
+)
+
+// QRISChargeTTL - lama QR dinamis berlaku sebelum dianggap expired
+const QRISChargeTTL = 15 * time.Minute
+
+// systemActor - actor audit buat perubahan status yang datang dari gateway atau sweeper, bukan dari user
+var systemActor = models.AuditActor{Username: "system"}
+
+type PendingSaleService struct {
+	repo        *repositories.PendingSaleRepository
+	gateway     payment.PaymentGateway
+	stockPolicy string
+	notifier    alert.Notifier
+	logger      *slog.Logger
+}
+
+func NewPendingSaleService(repo *repositories.PendingSaleRepository, gateway payment.PaymentGateway, stockPolicy string, notifier alert.Notifier, logger *slog.Logger) (*PendingSaleService, error) {
+	if stockPolicy != models.StockPolicyNone && stockPolicy != models.StockPolicyReserve {
+		return nil, ErrInvalidStockPolicy
+	}
+	return &PendingSaleService{repo: repo, gateway: gateway, stockPolicy: stockPolicy, notifier: notifier, logger: logger}, nil
+}
+
+// Create - simpan sale pending, lalu minta QR dinamis ke gateway.
+// Kalau gateway gagal, sale langsung ditutup failed biar reservasi stoknya (kalau ada) lepas
+func (s *PendingSaleService) Create(ctx context.Context, req *models.PendingSaleRequest, actor models.AuditActor) (*models.PendingSale, error) {
+	s.logger.Info("Service: Creating QRIS sale", "item_count", len(req.Items))
+	if len(req.Items) == 0 {
+		return nil, ErrEmptyCart
+	}
+	items, err := mergeItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	sale := models.PendingSale{
+		UserID:    req.UserID,
+		Cashier:   req.Cashier,
+		Gateway:   s.gateway.Name(),
+		ExpiresAt: time.Now().Add(QRISChargeTTL),
+	}
+	alerts, err := s.repo.Create(&sale, items, s.stockPolicy == models.StockPolicyReserve, actor)
+	if err != nil {
+		s.logger.Error("Service: Failed to create QRIS sale", "error", err)
+		return nil, err
+	}
+	for _, lowStock := range alerts {
+		s.notifier.NotifyLowStock(lowStock)
+	}
+
+	charge, err := s.gateway.CreateCharge(ctx, payment.ChargeRequest{
+		OrderID:   fmt.Sprintf("SALE-%d", sale.ID),
+		Amount:    sale.TotalAmount,
+		ExpiresIn: QRISChargeTTL,
+	})
+	if err != nil {
+		s.logger.Error("Service: Failed to create QRIS charge", "error", err, "id", sale.ID)
+		if _, closeErr := s.repo.Close(sale.ID, models.SaleStatusFailed, "gagal membuat QR: "+err.Error(), actor); closeErr != nil {
+			s.logger.Error("Service: Failed to close QRIS sale after charge error", "error", closeErr, "id", sale.ID)
+		}
+		return nil, err
+	}
+
+	if err := s.repo.SetCharge(sale.ID, charge.ID, charge.QRString, charge.ExpiresAt); err != nil {
+		s.logger.Error("Service: Failed to save QRIS charge", "error", err, "id", sale.ID)
+		return nil, err
+	}
+	sale.ChargeID = charge.ID
+	sale.QRString = charge.QRString
+	sale.ExpiresAt = charge.ExpiresAt
+
+	s.logger.Info("Service: QRIS sale created", "id", sale.ID, "charge_id", charge.ID, "total_amount", sale.TotalAmount)
+	return &sale, nil
+}
+
+// GetByID - kalau masih pending, status dicek ulang ke gateway dulu
+// (jaga-jaga webhook-nya telat atau ga nyampe)
+func (s *PendingSaleService) GetByID(ctx context.Context, id int, staff models.Staff) (*models.PendingSale, error) {
+	s.logger.Info("Service: Getting QRIS sale", "id", id)
+	sale, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Service: Failed to get QRIS sale", "error", err, "id", id)
+		return nil, err
+	}
+	if !canAccessSale(sale, staff) {
+		return nil, ErrPendingSaleForbidden
+	}
+	if sale.Status != models.SaleStatusPending || sale.ChargeID == "" {
+		return sale, nil
+	}
+	updated, err := s.refresh(ctx, sale)
+	if err != nil {
+		// gateway lagi bermasalah, tampilin status terakhir yang kita tau aja
+		return sale, nil
+	}
+	return updated, nil
+}
+
+// Cancel - kasir batalin QR yang belum dibayar (customer ga jadi), sale jadi failed
+func (s *PendingSaleService) Cancel(id int, staff models.Staff, actor models.AuditActor) (*models.PendingSale, error) {
+	s.logger.Info("Service: Cancelling QRIS sale", "id", id)
+	sale, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Service: Failed to get QRIS sale", "error", err, "id", id)
+		return nil, err
+	}
+	if !canAccessSale(sale, staff) {
+		return nil, ErrPendingSaleForbidden
+	}
+	if sale.Status != models.SaleStatusPending {
+		return nil, fmt.Errorf("%w: status %s", repositories.ErrSaleNotPending, sale.Status)
+	}
+	return s.repo.Close(id, models.SaleStatusFailed, "dibatalkan oleh "+staff.Username, actor)
+}
+
+// HandleWebhook - callback dari gateway. Signature diverifikasi gateway-nya sendiri,
+// status pending/ga dikenal di-ignore. Webhook yang dateng dobel aman karena repo idempotent
+func (s *PendingSaleService) HandleWebhook(header http.Header, body []byte) (*models.PendingSale, error) {
+	event, err := s.gateway.ParseWebhook(header, body)
+	if err != nil {
+		s.logger.Warn("Service: Rejected QRIS webhook", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: QRIS webhook received", "charge_id", event.ChargeID, "status", event.Status)
+
+	sale, err := s.repo.GetByChargeID(event.ChargeID)
+	if err != nil {
+		s.logger.Error("Service: QRIS webhook for unknown charge", "error", err, "charge_id", event.ChargeID)
+		return nil, err
+	}
+	if event.Status == payment.StatusPaid && event.Amount != sale.TotalAmount {
+		s.logger.Error("Service: QRIS webhook amount mismatch", "id", sale.ID, "expected", sale.TotalAmount, "got", event.Amount)
+		return nil, ErrWebhookAmountMismatch
+	}
+	return s.settle(sale, event.Status)
+}
+
+// ExpireOverdue - sapu sale pending yang lewat expires_at. Status dicek ke gateway dulu,
+// jadi pembayaran yang masuk pas detik-detik terakhir ga ikut ke-expire
+func (s *PendingSaleService) ExpireOverdue(ctx context.Context) {
+	sales, err := s.repo.ListOverdue()
+	if err != nil {
+		s.logger.Error("Service: Failed to list overdue QRIS sales", "error", err)
+		return
+	}
+	for i := range sales {
+		sale := &sales[i]
+		if sale.ChargeID == "" {
+			if _, err := s.repo.Close(sale.ID, models.SaleStatusExpired, "QR tidak pernah dibuat", systemActor); err != nil {
+				s.logger.Error("Service: Failed to expire QRIS sale", "error", err, "id", sale.ID)
+			}
+			continue
+		}
+		updated, err := s.refresh(ctx, sale)
+		if err != nil {
+			continue
+		}
+		if updated.Status == models.SaleStatusPending {
+			// gateway masih bilang pending padahal udah lewat, anggap expired
+			if _, err := s.repo.Close(sale.ID, models.SaleStatusExpired, "melewati batas waktu pembayaran", systemActor); err != nil {
+				s.logger.Error("Service: Failed to expire QRIS sale", "error", err, "id", sale.ID)
+			}
+		}
+	}
+}
+
+// RunSweeper - jalanin ExpireOverdue tiap interval sampai ctx selesai
+func (s *PendingSaleService) RunSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.ExpireOverdue(ctx)
+		}
+	}
+}
+
+// refresh - samain status sale dengan status charge di gateway
+func (s *PendingSaleService) refresh(ctx context.Context, sale *models.PendingSale) (*models.PendingSale, error) {
+	charge, err := s.gateway.CheckStatus(ctx, sale.ChargeID)
+	if err != nil {
+		s.logger.Error("Service: Failed to check QRIS charge status", "error", err, "id", sale.ID)
+		return nil, err
+	}
+	if charge.Status == payment.StatusPaid && charge.Amount != sale.TotalAmount {
+		s.logger.Error("Service: QRIS charge amount mismatch", "id", sale.ID, "expected", sale.TotalAmount, "got", charge.Amount)
+		return nil, ErrWebhookAmountMismatch
+	}
+	return s.settle(sale, charge.Status)
+}
+
+// settle - terapin status dari gateway ke sale
+func (s *PendingSaleService) settle(sale *models.PendingSale, status string) (*models.PendingSale, error) {
+	var updated *models.PendingSale
+	var err error
+	switch status {
+	case payment.StatusPaid:
+		updated, err = s.repo.MarkPaid(sale.ID)
+	case payment.StatusExpired:
+		updated, err = s.repo.Close(sale.ID, models.SaleStatusExpired, "QR kedaluwarsa", systemActor)
+	case payment.StatusFailed:
+		updated, err = s.repo.Close(sale.ID, models.SaleStatusFailed, "pembayaran ditolak gateway", systemActor)
+	default:
+		return sale, nil
+	}
+	if errors.Is(err, repositories.ErrSaleNotPending) && status == payment.StatusPaid {
+		// uang masuk ke sale yang udah expired/dibatalin, harus direfund manual
+		s.logger.Error("Service: Payment received for closed QRIS sale, refund manually", "id", sale.ID, "charge_id", sale.ChargeID)
+	}
+	if err != nil {
+		s.logger.Error("Service: Failed to settle QRIS sale", "error", err, "id", sale.ID, "status", status)
+		return nil, err
+	}
+
+	if updated.Transaction != nil {
+		for _, lowStock := range updated.Transaction.LowStockAlerts {
+			s.notifier.NotifyLowStock(lowStock)
+		}
+	}
+	s.logger.Info("Service: QRIS sale settled", "id", updated.ID, "status", updated.Status, "transaction_id", updated.TransactionID)
+	return updated, nil
+}
+
+// canAccessSale - kasir cuma boleh lihat/batalin sale sendiri, supervisor ke atas bebas
+func canAccessSale(sale *models.PendingSale, staff models.Staff) bool {
+	return sale.UserID == staff.UserID || auth.HasRole(staff.Role, models.RoleSupervisor)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"kasir-api/internal/apperror"
 	"kasir-api/models"
 	"kasir-api/repositories"
 	"log/slog"
@@ -8,29 +9,39 @@ import (
 
 //intermediate lah disini sama repo dengan handler
 
+var ErrInvalidPriceRange = apperror.New(apperror.ErrValidation, "min_price ga boleh lebih besar dari max_price")
+
 type ProductService struct {
-	repo   *repositories.ProductRepository
+	repo   repositories.ProductStore
 	logger *slog.Logger
 }
 
-func NewProductService(repo *repositories.ProductRepository, logger *slog.Logger) *ProductService {
+func NewProductService(repo repositories.ProductStore, logger *slog.Logger) *ProductService {
 	return &ProductService{repo: repo, logger: logger}
 }
 
-func (s *ProductService) GetAll() ([]models.Product, error) {
-	s.logger.Info("Service: Getting all products")
-	products, err := s.repo.GetAll()
+// GetAll - list produk per halaman, limit default 50 max 200 (lihat listDefaults)
+func (s *ProductService) GetAll(filter models.ProductFilter) (*models.ProductList, error) {
+	listDefaults(&filter.ListParams)
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, ErrInvalidPriceRange
+	}
+	s.logger.Info("Service: Getting products", "sort", filter.Sort, "limit", filter.Limit)
+	list, err := s.repo.GetAll(filter)
 	if err != nil {
-		s.logger.Error("Service: Failed to get all products", "error", err)
+		s.logger.Error("Service: Failed to get products", "error", err)
 		return nil, err
 	}
-	s.logger.Info("Service: Successfully retrieved products", "count", len(products))
-	return products, nil
+	for i := range list.Data {
+		list.Data[i].CalculateMargin()
+	}
+	s.logger.Info("Service: Successfully retrieved products", "count", len(list.Data))
+	return list, nil
 }
 
-func (s *ProductService) Create(data *models.Product) error {
+func (s *ProductService) Create(data *models.Product, actor models.AuditActor) error {
 	s.logger.Info("Service: Creating product", "name", data.Name)
-	err := s.repo.Create(data)
+	err := s.repo.Create(data, actor)
 	if err != nil {
 		s.logger.Error("Service: Failed to create product", "error", err, "name", data.Name)
 		return err
@@ -46,24 +57,26 @@ func (s *ProductService) GetByID(id int) (*models.Product, error) {
 		s.logger.Error("Service: Failed to get product by ID", "error", err, "id", id)
 		return nil, err
 	}
+	product.CalculateMargin()
 	s.logger.Info("Service: Successfully retrieved product", "id", id)
 	return product, nil
 }
 
-func (s *ProductService) Update(product *models.Product) error {
-	s.logger.Info("Service: Updating product", "id", product.ID)
-	err := s.repo.Update(product)
+// Update - data produk aja, stok diubah lewat StockService.Adjust
+func (s *ProductService) Update(update *models.ProductUpdate, actor models.AuditActor) error {
+	s.logger.Info("Service: Updating product", "id", update.ID)
+	err := s.repo.Update(update, actor)
 	if err != nil {
-		s.logger.Error("Service: Failed to update product", "error", err, "id", product.ID)
+		s.logger.Error("Service: Failed to update product", "error", err, "id", update.ID)
 		return err
 	}
-	s.logger.Info("Service: Product updated successfully", "id", product.ID)
+	s.logger.Info("Service: Product updated successfully", "id", update.ID)
 	return nil
 }
 
-func (s *ProductService) Delete(id int) error {
+func (s *ProductService) Delete(id int, actor models.AuditActor) error {
 	s.logger.Info("Service: Deleting product", "id", id)
-	err := s.repo.Delete(id)
+	err := s.repo.Delete(id, actor)
 	if err != nil {
 		s.logger.Error("Service: Failed to delete product", "error", err, "id", id)
 		return err
@@ -71,3 +84,36 @@ func (s *ProductService) Delete(id int) error {
 	s.logger.Info("Service: Product deleted successfully", "id", id)
 	return nil
 }
+
+// GetLowStock - produk dengan stok <= min_stock, dikelompokin per kategori
+func (s *ProductService) GetLowStock() ([]models.LowStockGroup, error) {
+	s.logger.Info("Service: Getting low stock products")
+	products, err := s.repo.GetLowStock()
+	if err != nil {
+		s.logger.Error("Service: Failed to get low stock products", "error", err)
+		return nil, err
+	}
+
+	// repo udah ngurutin per kategori, tinggal dipotong tiap ganti category_id
+	groups := make([]models.LowStockGroup, 0)
+	for _, p := range products {
+		if len(groups) == 0 || groups[len(groups)-1].CategoryID != p.CategoryID {
+			groups = append(groups, models.LowStockGroup{CategoryID: p.CategoryID, CategoryName: p.CategoryName})
+		}
+		last := &groups[len(groups)-1]
+		last.Products = append(last.Products, p)
+	}
+
+	s.logger.Info("Service: Successfully retrieved low stock products", "count", len(products), "categories", len(groups))
+	return groups, nil
+}
+
+// listDefaults - limit 50 kalau kosong, max 200. List katalog ga pernah unbounded
+func listDefaults(params *models.ListParams) {
+	if params.Limit <= 0 {
+		params.Limit = 50
+	}
+	if params.Limit > 200 {
+		params.Limit = 200
+	}
+}
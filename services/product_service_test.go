@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"testing"
+)
+
+func newTestProductService(t *testing.T) (*ProductService, *CategoryService) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	products, categories := repositories.NewMemoryStores()
+	return NewProductService(products, logger), NewCategoryService(categories, logger)
+}
+
+func TestProductServiceMargin(t *testing.T) {
+	products, categories := newTestProductService(t)
+	c := models.Category{Name: "Minuman"}
+	if err := categories.Create(&c, models.AuditActor{}); err != nil {
+		t.Fatal(err)
+	}
+	p := models.Product{Name: "Teh Botol", Price: 5000, CostPrice: 3500, CategoryID: c.ID}
+	if err := products.Create(&p, models.AuditActor{}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := products.GetByID(p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Margin != 1500 || got.MarginPercent != 30 {
+		t.Fatalf("margin = %d (%v%%), want 1500 (30%%)", got.Margin, got.MarginPercent)
+	}
+}
+
+func TestProductServiceUpdateKeepsStock(t *testing.T) {
+	products, categories := newTestProductService(t)
+	c := models.Category{Name: "Snack"}
+	if err := categories.Create(&c, models.AuditActor{}); err != nil {
+		t.Fatal(err)
+	}
+	p := models.Product{Name: "Chitato", Price: 8000, Stock: 20, MinStock: 5, CategoryID: c.ID}
+	if err := products.Create(&p, models.AuditActor{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// stok basi dari client ga boleh nimpa stok sekarang
+	p.Price, p.Stock = 8500, 4
+	update := models.ProductUpdate{Product: p}
+	if err := products.Update(&update, models.AuditActor{}); err != nil {
+		t.Fatal(err)
+	}
+	if update.Stock != 20 {
+		t.Fatalf("updated stock = %d, want 20 (unchanged)", update.Stock)
+	}
+	got, err := products.GetByID(p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Price != 8500 || got.Stock != 20 {
+		t.Fatalf("product = %+v, want price 8500 and stock 20", got)
+	}
+}
+
+func TestProductServiceGetLowStockGroups(t *testing.T) {
+	products, categories := newTestProductService(t)
+	snack := models.Category{Name: "Snack"}
+	drink := models.Category{Name: "Minuman"}
+	for _, c := range []*models.Category{&snack, &drink} {
+		if err := categories.Create(c, models.AuditActor{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, p := range []models.Product{
+		{Name: "Chitato", Stock: 1, MinStock: 5, CategoryID: snack.ID},
+		{Name: "Aqua", Stock: 2, MinStock: 5, CategoryID: drink.ID},
+		{Name: "Vit", Stock: 50, MinStock: 5, CategoryID: drink.ID},
+		{Name: "Delfi", Stock: 0, MinStock: 5, CategoryID: snack.ID},
+	} {
+		if err := products.Create(&p, models.AuditActor{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	groups, err := products.GetLowStock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("groups = %+v, want 2 categories", groups)
+	}
+	if groups[0].CategoryName != "Minuman" || len(groups[0].Products) != 1 {
+		t.Fatalf("first group = %+v, want Minuman with 1 product", groups[0])
+	}
+	if groups[1].CategoryName != "Snack" || len(groups[1].Products) != 2 || groups[1].Products[0].Name != "Delfi" {
+		t.Fatalf("second group = %+v, want Snack with Delfi first", groups[1])
+	}
+}
+
+func TestProductServiceGetAllDefaults(t *testing.T) {
+	products, categories := newTestProductService(t)
+	c := models.Category{Name: "Snack"}
+	if err := categories.Create(&c, models.AuditActor{}); err != nil {
+		t.Fatal(err)
+	}
+	p := models.Product{Name: "Chitato", Price: 8000, CostPrice: 6000, CategoryID: c.ID}
+	if err := products.Create(&p, models.AuditActor{}); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := products.GetAll(models.ProductFilter{ListParams: models.ListParams{Limit: 1000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Limit != 200 || len(list.Data) != 1 || list.Data[0].Margin != 2000 {
+		t.Fatalf("list = %+v, want limit capped at 200 and margin filled", list)
+	}
+
+	minPrice, maxPrice := 10000, 5000
+	_, err = products.GetAll(models.ProductFilter{MinPrice: &minPrice, MaxPrice: &maxPrice})
+	if !errors.Is(err, ErrInvalidPriceRange) {
+		t.Fatalf("err = %v, want ErrInvalidPriceRange", err)
+	}
+}
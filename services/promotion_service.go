@@ -0,0 +1,168 @@
+package services
+
+import (
+	"fmt"
+	"kasir-api/internal/apperror"
+	"kasir-api/internal/promotion"
+	"kasir-api/internal/tax"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+var ErrInvalidPromotion = apperror.New(apperror.ErrValidation, "data promo tidak valid")
+
+type PromotionService struct {
+	repo     *repositories.PromotionRepository
+	tax      tax.Config
+	location *time.Location
+	logger   *slog.Logger
+}
+
+func NewPromotionService(repo *repositories.PromotionRepository, taxConfig tax.Config, location *time.Location, logger *slog.Logger) *PromotionService {
+	return &PromotionService{repo: repo, tax: taxConfig, location: location, logger: logger}
+}
+
+func (s *PromotionService) GetAll(activeOnly bool) ([]models.Promotion, error) {
+	s.logger.Info("Service: Getting promotions", "active_only", activeOnly)
+	promotions, err := s.repo.GetAll(activeOnly)
+	if err != nil {
+		s.logger.Error("Service: Failed to get promotions", "error", err)
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func (s *PromotionService) GetByID(id int) (*models.Promotion, error) {
+	s.logger.Info("Service: Getting promotion by ID", "id", id)
+	return s.repo.GetByID(id)
+}
+
+func (s *PromotionService) Create(p *models.Promotion, actor models.AuditActor) error {
+	s.logger.Info("Service: Creating promotion", "name", p.Name, "type", p.Type)
+	if err := validatePromotion(p); err != nil {
+		return err
+	}
+	if err := s.repo.Create(p, actor); err != nil {
+		s.logger.Error("Service: Failed to create promotion", "error", err)
+		return err
+	}
+	s.logger.Info("Service: Promotion created successfully", "id", p.ID)
+	return nil
+}
+
+func (s *PromotionService) Update(p *models.Promotion, actor models.AuditActor) error {
+	s.logger.Info("Service: Updating promotion", "id", p.ID)
+	if err := validatePromotion(p); err != nil {
+		return err
+	}
+	if err := s.repo.Update(p, actor); err != nil {
+		s.logger.Error("Service: Failed to update promotion", "error", err, "id", p.ID)
+		return err
+	}
+	s.logger.Info("Service: Promotion updated successfully", "id", p.ID)
+	return nil
+}
+
+func (s *PromotionService) Delete(id int, actor models.AuditActor) error {
+	s.logger.Info("Service: Deleting promotion", "id", id)
+	if err := s.repo.Delete(id, actor); err != nil {
+		s.logger.Error("Service: Failed to delete promotion", "error", err, "id", id)
+		return err
+	}
+	return nil
+}
+
+// PriceCart - hitung harga keranjang pake harga produk sekarang dan promo yang lagi berlaku,
+// terus pecah tiap baris jadi DPP, PPN dan total sesuai config pajak. Cuma hitungan, ga nyimpen apa-apa dan ga ngecek stok.
+// Checkout & sale QRIS ngitung ulang pake evaluasi yang sama di dalam tx-nya, jadi selama harga/promo
+// ga berubah di antaranya, total di sini = yang ditagih
+func (s *PromotionService) PriceCart(req *models.CartRequest) (*models.CartPrice, error) {
+	s.logger.Info("Service: Pricing cart", "item_count", len(req.Items))
+	if len(req.Items) == 0 {
+		return nil, ErrEmptyCart
+	}
+	items, err := mergeItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.repo.CartProducts(items)
+	if err != nil {
+		s.logger.Error("Service: Failed to load cart products", "error", err)
+		return nil, err
+	}
+	promotions, err := s.repo.GetAll(true)
+	if err != nil {
+		s.logger.Error("Service: Failed to load active promotions", "error", err)
+		return nil, err
+	}
+
+	price := promotion.Evaluate(lines, promotions, time.Now().In(s.location))
+	s.tax.ApplyCart(&price)
+	s.logger.Info("Service: Cart priced", "subtotal", price.Subtotal, "total", price.Total, "tax", price.Tax, "gross", price.Gross)
+	return &price, nil
+}
+
+// validatePromotion - cek field wajib per jenis promo
+func validatePromotion(p *models.Promotion) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return fmt.Errorf("%w: nama wajib diisi", ErrInvalidPromotion)
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return fmt.Errorf("%w: ends_at harus setelah starts_at", ErrInvalidPromotion)
+	}
+	for _, d := range p.Days {
+		if d < 0 || d > 6 {
+			return fmt.Errorf("%w: days harus 0 (Minggu) sampai 6 (Sabtu)", ErrInvalidPromotion)
+		}
+	}
+
+	switch p.Type {
+	case models.PromoBuyXGetY:
+		if p.BuyQty <= 0 || p.FreeQty <= 0 {
+			return fmt.Errorf("%w: buy_qty dan free_qty harus lebih dari 0", ErrInvalidPromotion)
+		}
+		if len(p.ProductIDs) == 0 && len(p.CategoryIDs) == 0 {
+			return fmt.Errorf("%w: buy_x_get_y wajib punya product_ids atau category_ids", ErrInvalidPromotion)
+		}
+	case models.PromoPercent:
+		if p.Percent <= 0 || p.Percent > 100 {
+			return fmt.Errorf("%w: percent harus 1 sampai 100", ErrInvalidPromotion)
+		}
+	case models.PromoCartAmount:
+		if p.Amount <= 0 || p.MinSubtotal < 0 {
+			return fmt.Errorf("%w: amount harus lebih dari 0 dan min_subtotal tidak boleh minus", ErrInvalidPromotion)
+		}
+	case models.PromoBundle:
+		if p.BundleQty <= 0 {
+			p.BundleQty = 1
+		}
+		if len(p.ProductIDs) == 0 || len(p.CategoryIDs) > 0 {
+			return fmt.Errorf("%w: bundle cuma bisa pakai product_ids", ErrInvalidPromotion)
+		}
+		if countUnique(p.ProductIDs) != len(p.ProductIDs) {
+			return fmt.Errorf("%w: product_ids bundle tidak boleh dobel", ErrInvalidPromotion)
+		}
+		if len(p.ProductIDs) == 1 && p.BundleQty == 1 {
+			return fmt.Errorf("%w: bundle satu produk butuh bundle_qty lebih dari 1", ErrInvalidPromotion)
+		}
+		if p.BundlePrice <= 0 {
+			return fmt.Errorf("%w: bundle_price harus lebih dari 0", ErrInvalidPromotion)
+		}
+	default:
+		return fmt.Errorf("%w: type harus buy_x_get_y, percent, cart_amount atau bundle", ErrInvalidPromotion)
+	}
+	return nil
+}
+
+func countUnique(ids []int) int {
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	return len(seen)
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+)
+
+var (
+	ErrEmptyPurchaseOrder = apperror.New(apperror.ErrValidation, "purchase order harus punya minimal satu item")
+	ErrInvalidPOLine      = apperror.New(apperror.ErrValidation, "product_id dan quantity harus lebih dari 0, unit_cost tidak boleh negatif")
+)
+
+type PurchaseOrderService struct {
+	repo   *repositories.PurchaseOrderRepository
+	logger *slog.Logger
+}
+
+func NewPurchaseOrderService(repo *repositories.PurchaseOrderRepository, logger *slog.Logger) *PurchaseOrderService {
+	return &PurchaseOrderService{repo: repo, logger: logger}
+}
+
+func (s *PurchaseOrderService) Create(req *models.CreatePurchaseOrderRequest, actor models.AuditActor) (*models.PurchaseOrder, error) {
+	s.logger.Info("Service: Creating purchase order", "supplier_id", req.SupplierID, "item_count", len(req.Items))
+	lines, err := mergePOLines(req.Items)
+	if err != nil {
+		return nil, err
+	}
+	req.Items = lines
+
+	id, err := s.repo.Create(req, actor)
+	if err != nil {
+		s.logger.Error("Service: Failed to create purchase order", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: Purchase order created", "id", id)
+	return s.GetByID(id)
+}
+
+func (s *PurchaseOrderService) GetAll(status string, supplierID int) ([]models.PurchaseOrder, error) {
+	s.logger.Info("Service: Getting purchase orders", "status", status, "supplier_id", supplierID)
+	orders, err := s.repo.GetAll(status, supplierID)
+	if err != nil {
+		s.logger.Error("Service: Failed to get purchase orders", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved purchase orders", "count", len(orders))
+	return orders, nil
+}
+
+func (s *PurchaseOrderService) GetByID(id int) (*models.PurchaseOrder, error) {
+	s.logger.Info("Service: Getting purchase order by ID", "id", id)
+	order, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Service: Failed to get purchase order", "error", err, "id", id)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved purchase order", "id", id)
+	return order, nil
+}
+
+func (s *PurchaseOrderService) MarkSent(id int, actor models.AuditActor) (*models.PurchaseOrder, error) {
+	s.logger.Info("Service: Marking purchase order sent", "id", id)
+	if err := s.repo.MarkSent(id, actor); err != nil {
+		s.logger.Error("Service: Failed to mark purchase order sent", "error", err, "id", id)
+		return nil, err
+	}
+	s.logger.Info("Service: Purchase order marked sent", "id", id)
+	return s.GetByID(id)
+}
+
+func (s *PurchaseOrderService) Receive(id int, req *models.ReceiveGoodsRequest, actor models.AuditActor) (*models.GoodsReceipt, error) {
+	s.logger.Info("Service: Receiving goods", "purchase_order_id", id, "item_count", len(req.Items))
+	lines, err := mergePOLines(req.Items)
+	if err != nil {
+		return nil, err
+	}
+	req.Items = lines
+
+	receipt, err := s.repo.Receive(id, req, actor)
+	if err != nil {
+		s.logger.Error("Service: Failed to receive goods", "error", err, "purchase_order_id", id)
+		return nil, err
+	}
+	s.logger.Info("Service: Goods received", "receipt_id", receipt.ID)
+	return receipt, nil
+}
+
+// mergePOLines - validasi dan gabungin product_id yang sama.
+// Kalau unit_cost beda, yang dipake yang terakhir
+func mergePOLines(items []models.PurchaseOrderLine) ([]models.PurchaseOrderLine, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyPurchaseOrder
+	}
+	merged := make([]models.PurchaseOrderLine, 0, len(items))
+	index := make(map[int]int)
+	for _, item := range items {
+		if item.ProductID <= 0 || item.Quantity <= 0 || item.UnitCost < 0 {
+			return nil, ErrInvalidPOLine
+		}
+		if i, ok := index[item.ProductID]; ok {
+			merged[i].Quantity += item.Quantity
+			if item.UnitCost != 0 {
+				merged[i].UnitCost = item.UnitCost
+			}
+			continue
+		}
+		index[item.ProductID] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+	"kasir-api/internal/receipt"
+	"kasir-api/internal/tax"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// paymentLabels - nama metode bayar yang dicetak di struk
+var paymentLabels = map[string]string{
+	models.PaymentCash:        "Tunai",
+	models.PaymentDebit:       "Kartu Debit",
+	models.PaymentCredit:      "Kartu Kredit",
+	models.PaymentQRIS:        "QRIS",
+	models.PaymentEWallet:     "E-Wallet",
+	models.PaymentStoreCredit: "Store Credit",
+	models.PaymentPoints:      "Poin Member",
+}
+
+type ReceiptService struct {
+	repo     *repositories.TransactionRepository
+	store    receipt.Store
+	location *time.Location
+	logger   *slog.Logger
+}
+
+func NewReceiptService(repo *repositories.TransactionRepository, store receipt.Store, location *time.Location, logger *slog.Logger) *ReceiptService {
+	return &ReceiptService{repo: repo, store: store, location: location, logger: logger}
+}
+
+// Render - struk transaksi dalam format text/escpos/pdf, return isi + content type
+func (s *ReceiptService) Render(id int, format string, paper int) ([]byte, string, error) {
+	s.logger.Info("Service: Rendering receipt", "id", id, "format", format, "paper", paper)
+	transaction, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Service: Failed to get transaction for receipt", "error", err, "id", id)
+		return nil, "", err
+	}
+
+	body, contentType, err := receipt.Render(s.build(transaction), format, paper)
+	if err != nil {
+		s.logger.Error("Service: Failed to render receipt", "error", err, "id", id)
+		return nil, "", err
+	}
+	return body, contentType, nil
+}
+
+// build - petain transaksi ke isi struk. Jam dicetak pake timezone bisnis.
+// Subtotal baris udah dipotong promo baris itu, diskon keranjang dicetak sekali di bawah subtotal
+func (s *ReceiptService) build(t *models.Transaction) *receipt.Receipt {
+	r := &receipt.Receipt{
+		Store:     s.store,
+		Number:    fmt.Sprintf("TRX-%06d", t.ID),
+		Cashier:   t.Cashier,
+		CreatedAt: t.CreatedAt.In(s.location),
+		TaxLabel:  taxLabel(t),
+		Tax:       t.TaxAmount,
+		Total:     t.TotalAmount,
+		Change:    t.ChangeAmount,
+		Refunded:  t.RefundAmount,
+	}
+	for _, item := range t.Items {
+		r.Lines = append(r.Lines, receipt.Line{
+			Name:     item.ProductName,
+			Quantity: item.Quantity,
+			Price:    item.Price,
+			Discount: item.Discount,
+			Subtotal: item.Subtotal - item.Discount,
+		})
+		r.Subtotal += item.Subtotal - item.Discount
+		r.Discount += item.CartDiscount
+	}
+	for _, p := range t.Payments {
+		label, ok := paymentLabels[p.Method]
+		if !ok {
+			label = p.Method
+		}
+		r.Payments = append(r.Payments, receipt.Payment{Label: label, Tendered: p.Tendered})
+	}
+	return r
+}
+
+// taxLabel - "PPN 11%", ditambah "(termasuk)" kalau harga udah termasuk PPN
+// biar pembeli ga ngira PPN-nya ditambahin lagi ke subtotal
+func taxLabel(t *models.Transaction) string {
+	label := "PPN " + strconv.FormatFloat(t.TaxRate, 'f', -1, 64) + "%"
+	if t.TaxMode == tax.ModeInclusive {
+		label += " (termasuk)"
+	}
+	return label
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"kasir-api/internal/apperror"
+	"kasir-api/internal/tax"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"time"
+)
+
+var (
+	ErrInvalidGroupBy = apperror.New(apperror.ErrValidation, "group_by harus day, week, atau month")
+	ErrInvalidPeriod  = apperror.New(apperror.ErrValidation, "tanggal from harus sebelum to")
+	ErrInvalidSort    = apperror.New(apperror.ErrValidation, "sort harus quantity atau revenue")
+
+	ErrInvalidMarginGroup = apperror.New(apperror.ErrValidation, "group_by harus product, category, atau day")
+)
+
+// groupByUnits - whitelist unit date_trunc yang boleh dipake
+var groupByUnits = map[string]string{
+	"day":   "day",
+	"week":  "week",
+	"month": "month",
+}
+
+type ReportService struct {
+	repo     *repositories.ReportRepository
+	tax      tax.Config
+	location *time.Location
+	logger   *slog.Logger
+}
+
+func NewReportService(repo *repositories.ReportRepository, taxConfig tax.Config, location *time.Location, logger *slog.Logger) *ReportService {
+	return &ReportService{repo: repo, tax: taxConfig, location: location, logger: logger}
+}
+
+// Location - timezone bisnis, dipake handler buat parsing tanggal
+func (s *ReportService) Location() *time.Location {
+	return s.location
+}
+
+// SalesReport - from inklusif, to eksklusif (udah dalam timezone bisnis).
+// Kalau kosong default 30 hari terakhir
+func (s *ReportService) SalesReport(from, to time.Time, groupBy string) (*models.SalesReport, error) {
+	if groupBy == "" {
+		groupBy = "day"
+	}
+	unit, ok := groupByUnits[groupBy]
+	if !ok {
+		return nil, ErrInvalidGroupBy
+	}
+
+	if to.IsZero() {
+		now := time.Now().In(s.location)
+		to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.location).AddDate(0, 0, 1)
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	s.logger.Info("Service: Getting sales report", "from", from, "to", to, "group_by", unit)
+	buckets, totals, err := s.repo.SalesReport(from, to, unit, s.location.String())
+	if err != nil {
+		s.logger.Error("Service: Failed to get sales report", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved sales report", "buckets", len(buckets))
+
+	return &models.SalesReport{
+		From:     from,
+		To:       to,
+		GroupBy:  unit,
+		Timezone: s.location.String(),
+		Buckets:  buckets,
+		Totals:   *totals,
+	}, nil
+}
+
+// TopProducts - default 30 hari terakhir, sort quantity, limit 10
+func (s *ReportService) TopProducts(filter models.ProductSalesFilter) (*models.ProductSalesReport, error) {
+	if err := s.normalizeProductFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Service: Getting top products", "from", filter.From, "to", filter.To, "sort", filter.Sort)
+	products, err := s.repo.TopProducts(filter)
+	if err != nil {
+		s.logger.Error("Service: Failed to get top products", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved top products", "count", len(products))
+	return &models.ProductSalesReport{From: filter.From, To: filter.To, Sort: filter.Sort, ByCategory: filter.ByCategory, Products: products}, nil
+}
+
+// SlowProducts - window dihitung mundur `days` hari dari sekarang.
+// Default cuma produk yang sama sekali ga laku (MaxQuantity 0)
+func (s *ReportService) SlowProducts(days int, filter models.ProductSalesFilter) (*models.ProductSalesReport, error) {
+	if days <= 0 {
+		days = 30
+	}
+	filter.To = time.Now().In(s.location)
+	filter.From = filter.To.AddDate(0, 0, -days)
+	if filter.MaxQuantity < 0 {
+		filter.MaxQuantity = 0
+	}
+	if err := s.normalizeProductFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Service: Getting slow products", "days", days, "max_quantity", filter.MaxQuantity)
+	products, err := s.repo.SlowProducts(filter)
+	if err != nil {
+		s.logger.Error("Service: Failed to get slow products", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved slow products", "count", len(products))
+	return &models.ProductSalesReport{From: filter.From, To: filter.To, Sort: filter.Sort, ByCategory: filter.ByCategory, Products: products}, nil
+}
+
+func (s *ReportService) normalizeProductFilter(filter *models.ProductSalesFilter) error {
+	if filter.Sort == "" {
+		filter.Sort = "quantity"
+	}
+	if filter.Sort != "quantity" && filter.Sort != "revenue" {
+		return ErrInvalidSort
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 10
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+
+	if filter.To.IsZero() {
+		now := time.Now().In(s.location)
+		filter.To = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.location).AddDate(0, 0, 1)
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, 0, -30)
+	}
+	if !filter.From.Before(filter.To) {
+		return ErrInvalidPeriod
+	}
+	return nil
+}
+
+// MarginReport - default 30 hari terakhir, group_by product
+func (s *ReportService) MarginReport(from, to time.Time, groupBy string) (*models.MarginReport, error) {
+	if groupBy == "" {
+		groupBy = "product"
+	}
+	if groupBy != "product" && groupBy != "category" && groupBy != "day" {
+		return nil, ErrInvalidMarginGroup
+	}
+
+	if to.IsZero() {
+		now := time.Now().In(s.location)
+		to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.location).AddDate(0, 0, 1)
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	s.logger.Info("Service: Getting margin report", "from", from, "to", to, "group_by", groupBy)
+	rows, err := s.repo.MarginReport(from, to, groupBy, s.location.String())
+	if err != nil {
+		s.logger.Error("Service: Failed to get margin report", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved margin report", "rows", len(rows))
+
+	return &models.MarginReport{
+		From:     from,
+		To:       to,
+		GroupBy:  groupBy,
+		Timezone: s.location.String(),
+		Rows:     rows,
+	}, nil
+}
+
+// PaymentReport - default 30 hari terakhir
+func (s *ReportService) PaymentReport(from, to time.Time) (*models.PaymentReport, error) {
+	if to.IsZero() {
+		now := time.Now().In(s.location)
+		to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.location).AddDate(0, 0, 1)
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	s.logger.Info("Service: Getting payment report", "from", from, "to", to)
+	methods, err := s.repo.PaymentReport(from, to)
+	if err != nil {
+		s.logger.Error("Service: Failed to get payment report", "error", err)
+		return nil, err
+	}
+
+	report := &models.PaymentReport{From: from, To: to, Timezone: s.location.String(), Methods: methods}
+	for _, m := range methods {
+		report.Total += m.Amount
+	}
+	return report, nil
+}
+
+// TaxReport - rekap PPN buat lapor pajak, default 30 hari terakhir per hari.
+// TaxRate & TaxMode di header itu config sekarang; tiap transaksi tetap pake tarif snapshot-nya
+func (s *ReportService) TaxReport(from, to time.Time, groupBy string) (*models.TaxReport, error) {
+	if groupBy == "" {
+		groupBy = "day"
+	}
+	unit, ok := groupByUnits[groupBy]
+	if !ok {
+		return nil, ErrInvalidGroupBy
+	}
+
+	if to.IsZero() {
+		now := time.Now().In(s.location)
+		to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.location).AddDate(0, 0, 1)
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	s.logger.Info("Service: Getting tax report", "from", from, "to", to, "group_by", unit)
+	buckets, totals, err := s.repo.TaxReport(from, to, unit, s.location.String())
+	if err != nil {
+		s.logger.Error("Service: Failed to get tax report", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved tax report", "buckets", len(buckets))
+
+	return &models.TaxReport{
+		From:     from,
+		To:       to,
+		GroupBy:  unit,
+		Timezone: s.location.String(),
+		TaxRate:  s.tax.RatePercent(),
+		TaxMode:  s.tax.Mode,
+		Buckets:  buckets,
+		Totals:   *totals,
+	}, nil
+}
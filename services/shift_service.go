@@ -0,0 +1,127 @@
+package services
+
+import (
+	"kasir-api/internal/apperror"
+	"kasir-api/internal/auth"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+)
+
+var (
+	ErrInvalidOpeningFloat = apperror.New(apperror.ErrValidation, "opening_float tidak boleh minus")
+	ErrInvalidCashMovement = apperror.New(apperror.ErrValidation, "type harus in atau out dan amount harus lebih dari 0")
+	ErrCountedCashRequired = apperror.New(apperror.ErrValidation, "counted_cash wajib diisi dan tidak boleh minus")
+	ErrShiftForbidden      = apperror.New(apperror.ErrForbidden, "shift ini bukan milik kamu")
+)
+
+type ShiftService struct {
+	repo   *repositories.ShiftRepository
+	logger *slog.Logger
+}
+
+func NewShiftService(repo *repositories.ShiftRepository, logger *slog.Logger) *ShiftService {
+	return &ShiftService{repo: repo, logger: logger}
+}
+
+func (s *ShiftService) Open(req *models.OpenShiftRequest, staff models.Staff, actor models.AuditActor) (*models.Shift, error) {
+	s.logger.Info("Service: Opening shift", "user_id", staff.UserID, "opening_float", req.OpeningFloat)
+	if req.OpeningFloat < 0 {
+		return nil, ErrInvalidOpeningFloat
+	}
+
+	shift := models.Shift{
+		UserID:       staff.UserID,
+		Cashier:      staff.Username,
+		OpeningFloat: req.OpeningFloat,
+		Note:         req.Note,
+	}
+	if err := s.repo.Open(&shift, actor); err != nil {
+		s.logger.Error("Service: Failed to open shift", "error", err, "user_id", staff.UserID)
+		return nil, err
+	}
+	s.logger.Info("Service: Shift opened", "id", shift.ID)
+	return &shift, nil
+}
+
+func (s *ShiftService) Current(staff models.Staff) (*models.Shift, error) {
+	s.logger.Info("Service: Getting current shift", "user_id", staff.UserID)
+	return s.repo.GetOpenByUser(staff.UserID)
+}
+
+func (s *ShiftService) GetAll(filter models.ShiftFilter) ([]models.Shift, error) {
+	s.logger.Info("Service: Getting shifts", "status", filter.Status, "user_id", filter.UserID)
+	shifts, err := s.repo.GetAll(filter)
+	if err != nil {
+		s.logger.Error("Service: Failed to get shifts", "error", err)
+		return nil, err
+	}
+	return shifts, nil
+}
+
+// GetByID - kasir cuma boleh lihat shift sendiri, supervisor ke atas bebas
+func (s *ShiftService) GetByID(id int, staff models.Staff) (*models.Shift, error) {
+	s.logger.Info("Service: Getting shift by ID", "id", id)
+	shift, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Service: Failed to get shift", "error", err, "id", id)
+		return nil, err
+	}
+	if !canAccessShift(shift, staff) {
+		return nil, ErrShiftForbidden
+	}
+	return shift, nil
+}
+
+func (s *ShiftService) AddCashMovement(shiftID int, req *models.CashMovementRequest, staff models.Staff, actor models.AuditActor) (*models.CashMovement, error) {
+	s.logger.Info("Service: Recording cash movement", "shift_id", shiftID, "type", req.Type, "amount", req.Amount)
+	if (req.Type != models.CashMovementIn && req.Type != models.CashMovementOut) || req.Amount <= 0 {
+		return nil, ErrInvalidCashMovement
+	}
+	if _, err := s.GetByID(shiftID, staff); err != nil {
+		return nil, err
+	}
+
+	movement := models.CashMovement{
+		ShiftID:   shiftID,
+		Type:      req.Type,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+		CreatedBy: staff.Username,
+	}
+	if err := s.repo.AddCashMovement(&movement, actor); err != nil {
+		s.logger.Error("Service: Failed to record cash movement", "error", err, "shift_id", shiftID)
+		return nil, err
+	}
+	return &movement, nil
+}
+
+// Close - hitung expected cash, simpan selisih dengan uang yang dihitung fisik
+func (s *ShiftService) Close(shiftID int, req *models.CloseShiftRequest, staff models.Staff, actor models.AuditActor) (*models.Shift, error) {
+	s.logger.Info("Service: Closing shift", "shift_id", shiftID)
+	if req.CountedCash == nil || *req.CountedCash < 0 {
+		return nil, ErrCountedCashRequired
+	}
+	if _, err := s.GetByID(shiftID, staff); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Close(shiftID, *req.CountedCash, req.Note, actor); err != nil {
+		s.logger.Error("Service: Failed to close shift", "error", err, "shift_id", shiftID)
+		return nil, err
+	}
+
+	shift, err := s.repo.GetByID(shiftID)
+	if err != nil {
+		return nil, err
+	}
+	if shift.Difference != nil && *shift.Difference != 0 {
+		s.logger.Warn("Service: Cash drawer over/short", "shift_id", shiftID, "cashier", shift.Cashier, "difference", *shift.Difference)
+	}
+	s.logger.Info("Service: Shift closed", "shift_id", shiftID, "expected_cash", shift.ExpectedCash)
+	return shift, nil
+}
+
+func canAccessShift(shift *models.Shift, staff models.Staff) bool {
+	return shift.UserID == staff.UserID || auth.HasRole(staff.Role, models.RoleSupervisor)
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"kasir-api/internal/alert"
+	"kasir-api/internal/apperror"
+	"kasir-api/internal/auth"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+)
+
+var (
+	ErrLockSalesNeedsCategory = apperror.New(apperror.ErrValidation, "lock_sales hanya bisa dipakai kalau category_id diisi")
+	ErrInvalidOpnameCount     = apperror.New(apperror.ErrValidation, "product_id harus lebih dari 0 dan counted_qty tidak boleh negatif")
+	ErrLockSalesForbidden     = apperror.New(apperror.ErrForbidden, "lock_sales cuma boleh dipakai owner")
+)
+
+type StockOpnameService struct {
+	repo     *repositories.StockOpnameRepository
+	notifier alert.Notifier
+	logger   *slog.Logger
+}
+
+func NewStockOpnameService(repo *repositories.StockOpnameRepository, notifier alert.Notifier, logger *slog.Logger) *StockOpnameService {
+	return &StockOpnameService{repo: repo, notifier: notifier, logger: logger}
+}
+
+// Create - lock_sales nahan penjualan satu kategori sampai opname selesai, jadi cuma owner
+// yang boleh; supervisor tetap bisa buka opname biasa
+func (s *StockOpnameService) Create(req *models.CreateStockOpnameRequest, staff models.Staff, actor models.AuditActor) (*models.StockOpname, error) {
+	s.logger.Info("Service: Creating stock opname", "category_id", req.CategoryID, "lock_sales", req.LockSales)
+	if req.LockSales && !auth.HasRole(staff.Role, models.RoleOwner) {
+		s.logger.Warn("Service: lock_sales rejected", "user_id", staff.UserID, "role", staff.Role)
+		return nil, ErrLockSalesForbidden
+	}
+	if req.LockSales && req.CategoryID <= 0 {
+		return nil, ErrLockSalesNeedsCategory
+	}
+
+	opname := models.StockOpname{
+		CategoryID: req.CategoryID,
+		LockSales:  req.LockSales,
+		Note:       req.Note,
+		CreatedBy:  req.User,
+	}
+	if err := s.repo.Create(&opname, actor); err != nil {
+		s.logger.Error("Service: Failed to create stock opname", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: Stock opname created", "id", opname.ID)
+	return &opname, nil
+}
+
+func (s *StockOpnameService) GetAll(status string) ([]models.StockOpname, error) {
+	s.logger.Info("Service: Getting stock opnames", "status", status)
+	opnames, err := s.repo.GetAll(status)
+	if err != nil {
+		s.logger.Error("Service: Failed to get stock opnames", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved stock opnames", "count", len(opnames))
+	return opnames, nil
+}
+
+func (s *StockOpnameService) GetByID(id int) (*models.StockOpname, error) {
+	s.logger.Info("Service: Getting stock opname by ID", "id", id)
+	opname, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Service: Failed to get stock opname", "error", err, "id", id)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved stock opname", "id", id)
+	return opname, nil
+}
+
+// SubmitCounts - hasil hitung di-return lagi biar counter langsung liat selisihnya
+func (s *StockOpnameService) SubmitCounts(id int, req *models.SubmitStockOpnameCountsRequest, actor models.AuditActor) (*models.StockOpname, error) {
+	s.logger.Info("Service: Submitting stock opname counts", "id", id, "count", len(req.Items))
+	if len(req.Items) == 0 {
+		return nil, ErrInvalidOpnameCount
+	}
+	for _, item := range req.Items {
+		if item.ProductID <= 0 || item.CountedQty < 0 {
+			return nil, ErrInvalidOpnameCount
+		}
+	}
+
+	if err := s.repo.SubmitCounts(id, req, actor); err != nil {
+		s.logger.Error("Service: Failed to submit stock opname counts", "error", err, "id", id)
+		return nil, err
+	}
+	s.logger.Info("Service: Stock opname counts submitted", "id", id)
+	return s.GetByID(id)
+}
+
+func (s *StockOpnameService) Post(id int, user string, actor models.AuditActor) (*models.StockOpname, error) {
+	s.logger.Info("Service: Posting stock opname", "id", id)
+	alerts, err := s.repo.Post(id, user, actor)
+	if err != nil {
+		s.logger.Error("Service: Failed to post stock opname", "error", err, "id", id)
+		return nil, err
+	}
+	for _, lowStock := range alerts {
+		s.notifier.NotifyLowStock(lowStock)
+	}
+	s.logger.Info("Service: Stock opname posted", "id", id)
+	return s.GetByID(id)
+}
+
+func (s *StockOpnameService) Cancel(id int, user string, actor models.AuditActor) (*models.StockOpname, error) {
+	s.logger.Info("Service: Cancelling stock opname", "id", id)
+	if err := s.repo.Cancel(id, user, actor); err != nil {
+		s.logger.Error("Service: Failed to cancel stock opname", "error", err, "id", id)
+		return nil, err
+	}
+	s.logger.Info("Service: Stock opname cancelled", "id", id)
+	return s.GetByID(id)
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"kasir-api/models"
+	"log/slog"
+	"testing"
+)
+
+// Penolakan lock_sales terjadi sebelum nyentuh repository, jadi cukup pake repo nil
+func TestStockOpnameLockSalesOwnerOnly(t *testing.T) {
+	service := NewStockOpnameService(nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	req := &models.CreateStockOpnameRequest{CategoryID: 1, LockSales: true}
+
+	for _, role := range []string{models.RoleCashier, models.RoleSupervisor, ""} {
+		_, err := service.Create(req, models.Staff{UserID: 2, Role: role}, models.AuditActor{})
+		if !errors.Is(err, ErrLockSalesForbidden) {
+			t.Fatalf("role %q: err = %v, want ErrLockSalesForbidden", role, err)
+		}
+	}
+
+	// owner lolos cek role, baru kena validasi kategori
+	_, err := service.Create(&models.CreateStockOpnameRequest{LockSales: true}, models.Staff{UserID: 1, Role: models.RoleOwner}, models.AuditActor{})
+	if !errors.Is(err, ErrLockSalesNeedsCategory) {
+		t.Fatalf("owner: err = %v, want ErrLockSalesNeedsCategory", err)
+	}
+}
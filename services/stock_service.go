@@ -0,0 +1,85 @@
+package services
+
+import (
+	"kasir-api/internal/alert"
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+)
+
+var (
+	ErrInvalidStockReason   = apperror.New(apperror.ErrValidation, "reason harus restock atau adjustment")
+	ErrInvalidStockQuantity = apperror.New(apperror.ErrValidation, "quantity restock harus lebih dari 0, adjustment tidak boleh 0")
+)
+
+// StockService - perubahan stok manual dan baca ledger.
+// Perubahan dari sale/refund dicatat otomatis sama TransactionRepository
+type StockService struct {
+	repo     *repositories.StockRepository
+	notifier alert.Notifier
+	logger   *slog.Logger
+}
+
+func NewStockService(repo *repositories.StockRepository, notifier alert.Notifier, logger *slog.Logger) *StockService {
+	return &StockService{repo: repo, notifier: notifier, logger: logger}
+}
+
+func (s *StockService) Adjust(productID int, req *models.StockAdjustmentRequest, actor models.AuditActor) (*models.StockMovement, error) {
+	s.logger.Info("Service: Adjusting stock", "product_id", productID, "reason", req.Reason, "quantity", req.Quantity)
+
+	switch req.Reason {
+	case models.StockReasonRestock:
+		if req.Quantity <= 0 {
+			return nil, ErrInvalidStockQuantity
+		}
+	case models.StockReasonAdjustment:
+		if req.Quantity == 0 {
+			return nil, ErrInvalidStockQuantity
+		}
+	default:
+		return nil, ErrInvalidStockReason
+	}
+
+	movement := models.StockMovement{
+		ProductID:     productID,
+		Reason:        req.Reason,
+		Quantity:      req.Quantity,
+		ReferenceType: "manual",
+		Note:          req.Note,
+		CreatedBy:     req.User,
+	}
+	lowStock, err := s.repo.Adjust(&movement, actor)
+	if err != nil {
+		s.logger.Error("Service: Failed to adjust stock", "error", err, "product_id", productID)
+		return nil, err
+	}
+	if lowStock != nil {
+		s.notifier.NotifyLowStock(*lowStock)
+	}
+	s.logger.Info("Service: Stock adjusted", "product_id", productID, "stock_after", movement.StockAfter)
+	return &movement, nil
+}
+
+func (s *StockService) GetHistory(productID, page, limit int) (*models.StockHistory, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	s.logger.Info("Service: Getting stock history", "product_id", productID, "page", page, "limit", limit)
+	history, err := s.repo.GetHistory(productID, limit, (page-1)*limit)
+	if err != nil {
+		s.logger.Error("Service: Failed to get stock history", "error", err, "product_id", productID)
+		return nil, err
+	}
+	history.Page = page
+	history.Limit = limit
+	s.logger.Info("Service: Successfully retrieved stock history", "product_id", productID, "count", len(history.Movements))
+	return history, nil
+}
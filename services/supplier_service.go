@@ -0,0 +1,68 @@
+package services
+
+import (
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"strings"
+)
+
+var ErrSupplierNameRequired = apperror.New(apperror.ErrValidation, "nama supplier wajib diisi")
+
+type SupplierService struct {
+	repo   *repositories.SupplierRepository
+	logger *slog.Logger
+}
+
+func NewSupplierService(repo *repositories.SupplierRepository, logger *slog.Logger) *SupplierService {
+	return &SupplierService{repo: repo, logger: logger}
+}
+
+func (s *SupplierService) GetAll() ([]models.Supplier, error) {
+	s.logger.Info("Service: Getting all suppliers")
+	suppliers, err := s.repo.GetAll()
+	if err != nil {
+		s.logger.Error("Service: Failed to get all suppliers", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved suppliers", "count", len(suppliers))
+	return suppliers, nil
+}
+
+func (s *SupplierService) Create(data *models.Supplier, actor models.AuditActor) error {
+	s.logger.Info("Service: Creating supplier", "name", data.Name)
+	if strings.TrimSpace(data.Name) == "" {
+		return ErrSupplierNameRequired
+	}
+	if err := s.repo.Create(data, actor); err != nil {
+		s.logger.Error("Service: Failed to create supplier", "error", err, "name", data.Name)
+		return err
+	}
+	s.logger.Info("Service: Supplier created successfully", "id", data.ID)
+	return nil
+}
+
+func (s *SupplierService) GetByID(id int) (*models.Supplier, error) {
+	s.logger.Info("Service: Getting supplier by ID", "id", id)
+	supplier, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Service: Failed to get supplier by ID", "error", err, "id", id)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved supplier", "id", id)
+	return supplier, nil
+}
+
+func (s *SupplierService) Update(data *models.Supplier, actor models.AuditActor) error {
+	s.logger.Info("Service: Updating supplier", "id", data.ID)
+	if strings.TrimSpace(data.Name) == "" {
+		return ErrSupplierNameRequired
+	}
+	if err := s.repo.Update(data, actor); err != nil {
+		s.logger.Error("Service: Failed to update supplier", "error", err, "id", data.ID)
+		return err
+	}
+	s.logger.Info("Service: Supplier updated successfully", "id", data.ID)
+	return nil
+}
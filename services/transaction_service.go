@@ -0,0 +1,202 @@
+package services
+
+import (
+	"kasir-api/internal/alert"
+	"kasir-api/internal/apperror"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+)
+
+var (
+	ErrEmptyCart       = apperror.New(apperror.ErrValidation, "keranjang kosong")
+	ErrInvalidCartItem = apperror.New(apperror.ErrValidation, "product_id dan quantity harus lebih dari 0")
+
+	ErrInvalidPaymentMethod     = apperror.New(apperror.ErrValidation, "metode pembayaran harus cash, debit, credit, qris, ewallet, store_credit atau points")
+	ErrInvalidPaymentAmount     = apperror.New(apperror.ErrValidation, "amount pembayaran harus lebih dari 0")
+	ErrPaymentReferenceRequired = apperror.New(apperror.ErrValidation, "reference wajib diisi untuk pembayaran kartu dan store credit")
+	ErrPointsNeedCustomer       = apperror.New(apperror.ErrValidation, "pembayaran pakai poin wajib menyertakan customer_phone")
+)
+
+// paymentMethods - value true = wajib ada reference
+var paymentMethods = map[string]bool{
+	models.PaymentCash:        false,
+	models.PaymentDebit:       true,
+	models.PaymentCredit:      true,
+	models.PaymentQRIS:        false,
+	models.PaymentEWallet:     false,
+	models.PaymentStoreCredit: true,
+	models.PaymentPoints:      false,
+}
+
+type TransactionService struct {
+	repo     *repositories.TransactionRepository
+	notifier alert.Notifier
+	logger   *slog.Logger
+}
+
+func NewTransactionService(repo *repositories.TransactionRepository, notifier alert.Notifier, logger *slog.Logger) *TransactionService {
+	return &TransactionService{repo: repo, notifier: notifier, logger: logger}
+}
+
+// Checkout - validasi keranjang, gabungin produk yang sama, lalu lempar ke repo
+func (s *TransactionService) Checkout(req *models.CheckoutRequest) (*models.Transaction, error) {
+	s.logger.Info("Service: Checkout", "item_count", len(req.Items))
+	if len(req.Items) == 0 {
+		return nil, ErrEmptyCart
+	}
+
+	// produk yang sama di-merge biar lock dan cek stoknya sekali aja
+	merged, err := mergeItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := normalizePayments(req); err != nil {
+		return nil, err
+	}
+	if req.CustomerPhone != "" {
+		if req.CustomerPhone, err = normalizePhone(req.CustomerPhone); err != nil {
+			return nil, err
+		}
+	}
+	if req.CustomerPhone == "" && usesPoints(req) {
+		return nil, ErrPointsNeedCustomer
+	}
+	req.Items = merged
+
+	transaction, err := s.repo.CreateTransaction(req)
+	if err != nil {
+		s.logger.Error("Service: Failed to checkout", "error", err)
+		return nil, err
+	}
+	for _, lowStock := range transaction.LowStockAlerts {
+		s.notifier.NotifyLowStock(lowStock)
+	}
+	s.logger.Info("Service: Checkout successful", "id", transaction.ID, "total_amount", transaction.TotalAmount)
+	return transaction, nil
+}
+
+// GetAll - default page 1 limit 20, limit dibatesin max 100
+func (s *TransactionService) GetAll(filter models.TransactionFilter) (*models.TransactionList, error) {
+	pageDefaults(&filter)
+
+	s.logger.Info("Service: Getting transactions", "page", filter.Page, "limit", filter.Limit)
+	transactions, total, err := s.repo.GetAll(filter)
+	if err != nil {
+		s.logger.Error("Service: Failed to get transactions", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved transactions", "count", len(transactions), "total", total)
+	return &models.TransactionList{Data: transactions, Page: filter.Page, Limit: filter.Limit, Total: total}, nil
+}
+
+func (s *TransactionService) GetByID(id int) (*models.Transaction, error) {
+	s.logger.Info("Service: Getting transaction by ID", "id", id)
+	transaction, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Service: Failed to get transaction by ID", "error", err, "id", id)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved transaction", "id", id)
+	return transaction, nil
+}
+
+// Refund - Items kosong = refund full. Produk yang sama di-merge kayak checkout
+func (s *TransactionService) Refund(transactionID int, req *models.RefundRequest, actor models.AuditActor) (*models.Refund, error) {
+	s.logger.Info("Service: Refund", "transaction_id", transactionID, "item_count", len(req.Items))
+
+	merged, err := mergeItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+	req.Items = merged
+
+	refund, err := s.repo.CreateRefund(transactionID, req, actor)
+	if err != nil {
+		s.logger.Error("Service: Failed to refund", "error", err, "transaction_id", transactionID)
+		return nil, err
+	}
+	s.logger.Info("Service: Refund successful", "id", refund.ID, "total_amount", refund.TotalAmount)
+	return refund, nil
+}
+
+// normalizePayments - validasi tiap pembayaran dan isi PaymentMethod di header:
+// metode-nya kalau cuma satu jenis, "split" kalau campur.
+// Cukup/enggaknya total baru dicek di repo, karena harga baru pasti setelah row produk di-lock
+func normalizePayments(req *models.CheckoutRequest) error {
+	if len(req.Payments) == 0 {
+		if req.PaymentMethod == "" {
+			req.PaymentMethod = models.PaymentCash
+		}
+		if _, ok := paymentMethods[req.PaymentMethod]; !ok {
+			return ErrInvalidPaymentMethod
+		}
+		if paymentMethods[req.PaymentMethod] {
+			return ErrPaymentReferenceRequired
+		}
+		return nil
+	}
+
+	for i, p := range req.Payments {
+		needsReference, ok := paymentMethods[p.Method]
+		if !ok {
+			return ErrInvalidPaymentMethod
+		}
+		if p.Amount <= 0 {
+			return ErrInvalidPaymentAmount
+		}
+		if needsReference && p.Reference == "" {
+			return ErrPaymentReferenceRequired
+		}
+		if i == 0 {
+			req.PaymentMethod = p.Method
+		} else if p.Method != req.PaymentMethod {
+			req.PaymentMethod = models.PaymentSplit
+		}
+	}
+	return nil
+}
+
+// pageDefaults - page 1 limit 20 kalau kosong, limit max 100
+func pageDefaults(filter *models.TransactionFilter) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+}
+
+func usesPoints(req *models.CheckoutRequest) bool {
+	if len(req.Payments) == 0 {
+		return req.PaymentMethod == models.PaymentPoints
+	}
+	for _, p := range req.Payments {
+		if p.Method == models.PaymentPoints {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeItems - validasi qty dan gabungin baris dengan product_id yang sama
+func mergeItems(items []models.CheckoutItem) ([]models.CheckoutItem, error) {
+	merged := make([]models.CheckoutItem, 0, len(items))
+	index := make(map[int]int)
+	for _, item := range items {
+		if item.ProductID <= 0 || item.Quantity <= 0 {
+			return nil, ErrInvalidCartItem
+		}
+		if i, ok := index[item.ProductID]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.ProductID] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged, nil
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"kasir-api/internal/apperror"
+	"kasir-api/internal/auth"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"strings"
+)
+
+var (
+	ErrUsernameRequired = apperror.New(apperror.ErrValidation, "username wajib diisi")
+	ErrPasswordTooShort = apperror.New(apperror.ErrValidation, "password minimal 8 karakter")
+	ErrInvalidRole      = apperror.New(apperror.ErrValidation, "role harus cashier, supervisor atau owner")
+)
+
+type UserService struct {
+	repo   *repositories.UserRepository
+	logger *slog.Logger
+}
+
+func NewUserService(repo *repositories.UserRepository, logger *slog.Logger) *UserService {
+	return &UserService{repo: repo, logger: logger}
+}
+
+func (s *UserService) GetAll() ([]models.User, error) {
+	s.logger.Info("Service: Getting all users")
+	users, err := s.repo.GetAll()
+	if err != nil {
+		s.logger.Error("Service: Failed to get all users", "error", err)
+		return nil, err
+	}
+	s.logger.Info("Service: Successfully retrieved users", "count", len(users))
+	return users, nil
+}
+
+func (s *UserService) GetByID(id int) (*models.User, error) {
+	s.logger.Info("Service: Getting user by ID", "id", id)
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Service: Failed to get user", "error", err, "id", id)
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *UserService) Create(req *models.CreateUserRequest, actor models.AuditActor) (*models.User, error) {
+	req.Username = strings.TrimSpace(req.Username)
+	s.logger.Info("Service: Creating user", "username", req.Username)
+	if req.Username == "" {
+		return nil, ErrUsernameRequired
+	}
+	if len(req.Password) < 8 {
+		return nil, ErrPasswordTooShort
+	}
+	if req.Role == "" {
+		req.Role = models.RoleCashier
+	}
+	if !auth.ValidRole(req.Role) {
+		return nil, ErrInvalidRole
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		s.logger.Error("Service: Failed to hash password", "error", err)
+		return nil, err
+	}
+
+	user := models.User{Username: req.Username, Name: req.Name, PasswordHash: hash, Role: req.Role, Active: true}
+	if err := s.repo.Create(&user, actor); err != nil {
+		s.logger.Error("Service: Failed to create user", "error", err, "username", req.Username)
+		return nil, err
+	}
+	s.logger.Info("Service: User created successfully", "id", user.ID)
+	return &user, nil
+}
+
+// EnsureAdmin - bikin user pertama (role owner) dari env kalau tabel users masih kosong,
+// biar environment baru ga kekunci di luar
+func (s *UserService) EnsureAdmin(username, password string) error {
+	if username == "" || password == "" {
+		return nil
+	}
+	count, err := s.repo.Count()
+	if err != nil {
+		s.logger.Error("Service: Failed to count users", "error", err)
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	s.logger.Info("Service: No users yet, creating bootstrap admin", "username", username)
+	_, err = s.Create(
+		&models.CreateUserRequest{Username: username, Name: username, Password: password, Role: models.RoleOwner},
+		models.AuditActor{Username: "system"},
+	)
+	return err
+}
@@ -12,7 +12,47 @@ export const options = {
 
 const BASE_URL = __ENV.BASE_URL || 'http://localhost:8080';
 
-export default function () {
+// Selain /, /health, login, refresh & webhook QRIS semua endpoint wajib Bearer token.
+// setup() login sekali pake akun admin (sama kayak ADMIN_USERNAME/ADMIN_PASSWORD server),
+// token-nya dioper ke tiap iterasi lewat parameter data
+const USERNAME = __ENV.K6_USERNAME || __ENV.ADMIN_USERNAME || 'admin';
+const PASSWORD = __ENV.K6_PASSWORD || __ENV.ADMIN_PASSWORD;
+
+function login() {
+  if (!PASSWORD) {
+    throw new Error('set K6_PASSWORD (atau ADMIN_PASSWORD) buat login');
+  }
+  const res = http.post(`${BASE_URL}/api/auth/login`, JSON.stringify({
+    username: USERNAME,
+    password: PASSWORD,
+  }), {
+    headers: { 'Content-Type': 'application/json' },
+  });
+  check(res, {
+    'login status is 200': (r) => r.status === 200,
+  });
+  if (res.status !== 200) {
+    throw new Error(`login gagal: ${res.status} ${res.body}`);
+  }
+  return res.json('access_token');
+}
+
+function authParams(token) {
+  return {
+    headers: {
+      'Content-Type': 'application/json',
+      Authorization: `Bearer ${token}`,
+    },
+  };
+}
+
+export function setup() {
+  return { token: login() };
+}
+
+export default function (data) {
+  const params = authParams(data.token);
+
   // Test home endpoint
   let res = http.get(`${BASE_URL}/`);
   check(res, {
@@ -28,17 +68,18 @@ export default function () {
   });
 
   // Test get all products
-  res = http.get(`${BASE_URL}/api/produk`);
+  res = http.get(`${BASE_URL}/api/produk`, params);
   check(res, {
     'products status is 200': (r) => r.status === 200,
-    'products response is array': (r) => Array.isArray(r.json('data')),
+    // list dibungkus {data, limit, next_cursor, total}
+    'products response has data array': (r) => Array.isArray(r.json('data')),
   });
 
   // Test get all categories
-  res = http.get(`${BASE_URL}/categories`);
+  res = http.get(`${BASE_URL}/categories`, params);
   check(res, {
     'categories status is 200': (r) => r.status === 200,
-    'categories response has success': (r) => r.json('success') === true,
+    'categories response has data array': (r) => Array.isArray(r.json('data')),
   });
 
   sleep(1);
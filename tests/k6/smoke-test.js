@@ -12,11 +12,45 @@ export const options = {
 
 const BASE_URL = __ENV.BASE_URL || 'http://localhost:8080';
 
+// Selain /, /health, login, refresh & webhook QRIS semua endpoint wajib Bearer token.
+// setup() login sekali pake akun admin (sama kayak ADMIN_USERNAME/ADMIN_PASSWORD server),
+// token-nya dioper ke tiap iterasi lewat parameter data
+const USERNAME = __ENV.K6_USERNAME || __ENV.ADMIN_USERNAME || 'admin';
+const PASSWORD = __ENV.K6_PASSWORD || __ENV.ADMIN_PASSWORD;
+
+function login() {
+  if (!PASSWORD) {
+    throw new Error('set K6_PASSWORD (atau ADMIN_PASSWORD) buat login');
+  }
+  const res = http.post(`${BASE_URL}/api/auth/login`, JSON.stringify({
+    username: USERNAME,
+    password: PASSWORD,
+  }), params);
+  check(res, {
+    'login status is 200': (r) => r.status === 200,
+  });
+  if (res.status !== 200) {
+    throw new Error(`login gagal: ${res.status} ${res.body}`);
+  }
+  return res.json('access_token');
+}
+
+function authParams(token) {
+  return {
+    headers: {
+      'Content-Type': 'application/json',
+      Authorization: `Bearer ${token}`,
+    },
+  };
+}
+
 // Test data - sesuai dengan models/product.go dan models/category.go
 let createdProductId;
 let createdCategoryId;
 
-export default function () {
+export default function (data) {
+  const params = authParams(data.token);
+
   // ===== HOME & HEALTH ENDPOINTS =====
   let res = http.get(`${BASE_URL}/`);
   check(res, {
@@ -37,10 +71,11 @@ export default function () {
   // ===== CATEGORY ENDPOINTS =====
   
   // GET all categories
-  res = http.get(`${BASE_URL}/categories`);
+  res = http.get(`${BASE_URL}/categories`, params);
   check(res, {
     'GET categories status is 200': (r) => r.status === 200,
-    'categories response is array': (r) => Array.isArray(r.body) || r.body !== null,
+    // list dibungkus {data, limit, next_cursor, total}
+    'categories response has data array': (r) => Array.isArray(r.json('data')),
   });
 
   sleep(0.5);
@@ -51,9 +86,7 @@ export default function () {
     description: 'K6 Smoke Test Category'
   });
 
-  res = http.post(`${BASE_URL}/categories`, categoryPayload, {
-    headers: { 'Content-Type': 'application/json' },
-  });
+  res = http.post(`${BASE_URL}/categories`, categoryPayload, params);
   check(res, {
     'POST category status is 201': (r) => r.status === 201,
     'POST category returns name': (r) => r.json('name') !== undefined,
@@ -67,7 +100,7 @@ export default function () {
 
   // GET category by ID
   if (createdCategoryId) {
-    res = http.get(`${BASE_URL}/categories/${createdCategoryId}`);
+    res = http.get(`${BASE_URL}/categories/${createdCategoryId}`, params);
     check(res, {
       'GET category by ID status is 200': (r) => r.status === 200,
       'GET category returns id': (r) => r.json('id') === createdCategoryId,
@@ -85,9 +118,7 @@ export default function () {
       description: 'Updated by K6'
     });
 
-    res = http.put(`${BASE_URL}/categories/${createdCategoryId}`, updateCategoryPayload, {
-      headers: { 'Content-Type': 'application/json' },
-    });
+    res = http.put(`${BASE_URL}/categories/${createdCategoryId}`, updateCategoryPayload, params);
     check(res, {
       'PUT category status is 200': (r) => r.status === 200,
       'PUT category returns updated name': (r) => r.json('name').includes('Updated'),
@@ -99,10 +130,10 @@ export default function () {
   // ===== PRODUCT ENDPOINTS =====
   
   // GET all products
-  res = http.get(`${BASE_URL}/api/produk`);
+  res = http.get(`${BASE_URL}/api/produk`, params);
   check(res, {
     'GET products status is 200': (r) => r.status === 200,
-    'products response is array': (r) => Array.isArray(r.body) || r.body !== null,
+    'products response has data array': (r) => Array.isArray(r.json('data')),
   });
 
   sleep(0.5);
@@ -115,9 +146,7 @@ export default function () {
     category_id: createdCategoryId || 1
   });
 
-  res = http.post(`${BASE_URL}/api/produk`, productPayload, {
-    headers: { 'Content-Type': 'application/json' },
-  });
+  res = http.post(`${BASE_URL}/api/produk`, productPayload, params);
   check(res, {
     'POST product status is 201': (r) => r.status === 201,
     'POST product returns name': (r) => r.json('name') !== undefined,
@@ -133,7 +162,7 @@ export default function () {
 
   // GET product by ID (dengan category_name jika ada JOIN)
   if (createdProductId) {
-    res = http.get(`${BASE_URL}/api/produk/${createdProductId}`);
+    res = http.get(`${BASE_URL}/api/produk/${createdProductId}`, params);
     check(res, {
       'GET product by ID status is 200': (r) => r.status === 200,
       'GET product returns id': (r) => r.json('id') === createdProductId,
@@ -155,9 +184,7 @@ export default function () {
       category_id: createdCategoryId || 1
     });
 
-    res = http.put(`${BASE_URL}/api/produk/${createdProductId}`, updateProductPayload, {
-      headers: { 'Content-Type': 'application/json' },
-    });
+    res = http.put(`${BASE_URL}/api/produk/${createdProductId}`, updateProductPayload, params);
     check(res, {
       'PUT product status is 200': (r) => r.status === 200,
       'PUT product returns updated name': (r) => r.json('name').includes('Updated'),
@@ -169,7 +196,7 @@ export default function () {
 
   // DELETE product
   if (createdProductId) {
-    res = http.del(`${BASE_URL}/api/produk/${createdProductId}`);
+    res = http.del(`${BASE_URL}/api/produk/${createdProductId}`, null, params);
     check(res, {
       'DELETE product status is 200': (r) => r.status === 200,
       'DELETE product returns message': (r) => r.json('message') !== undefined,
@@ -180,7 +207,7 @@ export default function () {
 
   // DELETE category
   if (createdCategoryId) {
-    res = http.del(`${BASE_URL}/categories/${createdCategoryId}`);
+    res = http.del(`${BASE_URL}/categories/${createdCategoryId}`, null, params);
     check(res, {
       'DELETE category status is 200': (r) => r.status === 200,
       'DELETE category returns message': (r) => r.json('message') !== undefined,
@@ -194,6 +221,7 @@ export default function () {
 export function setup() {
   console.log('🚀 Starting smoke test...');
   console.log(`📡 Base URL: ${BASE_URL}`);
+  return { token: login() };
 }
 
 // Teardown function - runs once after test